@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/bxcodec/go-clean-arch/audit"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	mysqlRepo "github.com/bxcodec/go-clean-arch/internal/repository/mysql"
+	postgresRepo "github.com/bxcodec/go-clean-arch/internal/repository/postgres"
+	sqliteRepo "github.com/bxcodec/go-clean-arch/internal/repository/sqlite"
+	"github.com/bxcodec/go-clean-arch/news"
+	"github.com/bxcodec/go-clean-arch/topic"
+)
+
+// The databases of DATABASE_DRIVER
+const (
+	driverPostgres = "postgres"
+	driverMySQL    = "mysql"
+	driverSQLite   = "sqlite"
+)
+
+// defaultSQLitePath is the database file of the sqlite driver
+const defaultSQLitePath = "news.db"
+
+// repositories are the repositories of a database backend
+type repositories struct {
+	news       news.NewsRepository
+	authors    news.AuthorRepository
+	topics     topic.TopicRepository
+	newsTopics news.NewsTopicRepository
+	audit      auditRepository
+}
+
+// auditRepository is the audit log repository of the services
+type auditRepository interface {
+	news.AuditRepository
+	audit.AuditRepository
+}
+
+// databaseDriver returns the DATABASE_DRIVER, postgres by default
+func databaseDriver() string {
+	driver := envOrDefault("DATABASE_DRIVER", driverPostgres)
+	if driver != driverPostgres && driver != driverMySQL && driver != driverSQLite {
+		log.Fatalf("Unknown DATABASE_DRIVER %q, expected %s, %s or %s", driver, driverPostgres, driverMySQL, driverSQLite)
+	}
+	return driver
+}
+
+// newRepositories returns the repositories of driver on db
+func newRepositories(driver string, db *repository.DB) repositories {
+	switch driver {
+	case driverMySQL:
+		return repositories{
+			news:       mysqlRepo.NewNewsRepository(db),
+			authors:    mysqlRepo.NewAuthorRepository(db),
+			topics:     mysqlRepo.NewTopicRepository(db),
+			newsTopics: mysqlRepo.NewNewsTopicRepository(db),
+			audit:      mysqlRepo.NewAuditRepository(db),
+		}
+	case driverSQLite:
+		return repositories{
+			news:       sqliteRepo.NewNewsRepository(db),
+			authors:    sqliteRepo.NewAuthorRepository(db),
+			topics:     sqliteRepo.NewTopicRepository(db),
+			newsTopics: sqliteRepo.NewNewsTopicRepository(db),
+			audit:      sqliteRepo.NewAuditRepository(db),
+		}
+	}
+	return repositories{
+		news:       postgresRepo.NewNewsRepository(db),
+		authors:    postgresRepo.NewAuthorRepository(db),
+		topics:     postgresRepo.NewTopicRepository(db),
+		newsTopics: postgresRepo.NewNewsTopicRepository(db),
+		audit:      postgresRepo.NewAuditRepository(db),
+	}
+}
+
+// openDB connects to the database described by the DATABASE_* variables.
+// The SQLite database is the DATABASE_PATH file, created with its schema when
+// it does not exist.
+func openDB(driver string) *sql.DB {
+	if driver == driverSQLite {
+		dbConn, err := sqliteRepo.Open(context.Background(), envOrDefault("DATABASE_PATH", defaultSQLitePath))
+		if err != nil {
+			log.Fatal("Failed to open database:", err)
+		}
+		return dbConn
+	}
+
+	dbHost := os.Getenv("DATABASE_HOST")
+	dbPort := os.Getenv("DATABASE_PORT")
+	dbUser := os.Getenv("DATABASE_USER")
+	dbPass := os.Getenv("DATABASE_PASS")
+	dbName := os.Getenv("DATABASE_NAME")
+
+	connection := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPass, dbHost, dbPort, dbName)
+	if driver == driverMySQL {
+		cfg := mysql.NewConfig()
+		cfg.User = dbUser
+		cfg.Passwd = dbPass
+		cfg.Net = "tcp"
+		cfg.Addr = net.JoinHostPort(dbHost, dbPort)
+		cfg.DBName = dbName
+		connection = cfg.FormatDSN()
+	}
+	dbConn, err := openConn(driver, connection)
+	if err != nil {
+		log.Fatal("Failed to open connection to database:", err)
+	}
+
+	err = dbConn.Ping()
+	if err != nil {
+		log.Fatal("Failed to ping database:", err)
+	}
+	return dbConn
+}
+
+// openReplicas opens the read replicas of DATABASE_REPLICA_URLS, comma
+// separated connection URLs or MySQL DSNs. They are not pinged, the replicas
+// which can not be reached are skipped by the reads.
+func openReplicas(driver string) []*sql.DB {
+	var replicas []*sql.DB
+	for _, url := range strings.Split(os.Getenv("DATABASE_REPLICA_URLS"), ",") {
+		if url = strings.TrimSpace(url); url == "" {
+			continue
+		}
+		if driver == driverSQLite {
+			log.Fatal("DATABASE_REPLICA_URLS is not supported by the sqlite driver")
+		}
+		replica, err := openConn(driver, url)
+		if err != nil {
+			log.Fatal("Failed to open connection to replica:", err)
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas
+}
+
+// openConn opens a connection to dsn. The MySQL connections parse the times,
+// which the repositories scan into time.Time.
+func openConn(driver, dsn string) (*sql.DB, error) {
+	if driver == driverMySQL {
+		cfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ParseTime = true
+		dsn = cfg.FormatDSN()
+	}
+	return sql.Open(driver, dsn)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	newsDto "github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/bxcodec/go-clean-arch/news"
+)
+
+// runImport loads news from a CSV or NDJSON file and prints the import report
+// as JSON, e.g.
+//
+//	go run ./app import -file archive.csv -dry-run
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	file := flags.String("file", "", "CSV or NDJSON file to import, - for stdin")
+	format := flags.String("format", "", "csv or ndjson, guessed from the file extension by default")
+	dryRun := flags.Bool("dry-run", false, "validate the rows without storing them")
+	batchSize := flags.Int("batch-size", 0, "news inserted per transaction")
+	actor := flags.String("actor", "cli:import", "actor recorded in the audit log")
+	_ = flags.Parse(args)
+
+	if *file == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+		if *format == "jsonl" {
+			*format = newsDto.ImportFormatNDJSON
+		}
+	}
+
+	input := os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatal("Failed to open import file:", err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Println("Error closing the import file:", err)
+			}
+		}()
+		input = f
+	}
+
+	reader, err := newsDto.NewImportReader(input, *format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	driver := databaseDriver()
+	dbConn := openDB(driver)
+	defer func() {
+		if err := dbConn.Close(); err != nil {
+			log.Println("Error closing the DB connection:", err)
+		}
+	}()
+
+	// the import reads its own writes, it does not use the replicas
+	repos := newRepositories(driver, repository.NewDB(dbConn))
+	ns := news.NewService(repos.news, repos.authors, repos.topics, repos.newsTopics, repos.audit, repository.NewTransactor(dbConn))
+
+	ctx := domain.ContextWithRequestInfo(context.Background(), domain.RequestInfo{Actor: *actor})
+	report, err := ns.Import(ctx, reader, domain.ImportOptions{DryRun: *dryRun, BatchSize: *batchSize})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(report); encodeErr != nil {
+		log.Println(encodeErr)
+	}
+	if err != nil {
+		log.Fatal("Import stopped:", err)
+	}
+	log.Printf("Imported %d news: %d created, %d skipped, %d failed", report.Total, report.Created, report.Skipped, report.Failed)
+}
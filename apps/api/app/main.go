@@ -1,28 +1,48 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"expvar"
 	"fmt"
 	"github.com/bxcodec/go-clean-arch/topic"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	_ "github.com/bxcodec/go-clean-arch/app/docs"
-	postgresRepo "github.com/bxcodec/go-clean-arch/internal/repository/postgres" // Update the repository package
+	"github.com/bxcodec/go-clean-arch/audit"
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+	"github.com/bxcodec/go-clean-arch/internal/gql"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/bxcodec/go-clean-arch/internal/repository/cached"
 	"github.com/bxcodec/go-clean-arch/internal/rest"
 	"github.com/bxcodec/go-clean-arch/internal/rest/middleware"
+	"github.com/bxcodec/go-clean-arch/internal/rpc"
 	"github.com/bxcodec/go-clean-arch/news"
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 const (
-	defaultTimeout = 30 * time.Second
-	defaultAddress = ":9090"
+	defaultTimeout        = 30 * time.Second
+	defaultAddress        = ":9090"
+	defaultReadRateLimit  = "300/1m"
+	defaultWriteRateLimit = "60/1m"
+	defaultSiteURL        = "http://localhost:9090"
+	defaultFeedTitle      = "News and Topic Management"
+	defaultSiteLanguage   = "en"
+	defaultGraphQLDepth   = 8
+	defaultGraphQLCost    = 2000
+	defaultIdempotencyTTL = "24h"
+	defaultCacheTTL       = "1m"
+	defaultCacheSize      = 10000
+	// defaultResponseCacheSize is lower, responses weighing up to 1 MiB
+	defaultResponseCacheSize    = 1000
+	defaultReplicaCheckInterval = 5 * time.Second
 )
 
 func init() {
@@ -46,28 +66,18 @@ func init() {
 
 // @host
 func main() {
-	// Prepare database connection
-	dbHost := os.Getenv("DATABASE_HOST")
-	dbPort := os.Getenv("DATABASE_PORT")
-	dbUser := os.Getenv("DATABASE_USER")
-	dbPass := os.Getenv("DATABASE_PASS")
-	dbName := os.Getenv("DATABASE_NAME")
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(os.Args[2:])
+		return
+	}
+
 	address := os.Getenv("SERVER_ADDRESS")
 	if address == "" {
 		address = defaultAddress
 	}
 
-	connection := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", dbUser, dbPass, dbHost, dbPort, dbName)
-	dbConn, err := sql.Open("postgres", connection)
-	if err != nil {
-		log.Fatal("Failed to open connection to database:", err)
-	}
-
-	err = dbConn.Ping()
-	if err != nil {
-		log.Fatal("Failed to ping database:", err)
-	}
-
+	driver := databaseDriver()
+	dbConn := openDB(driver)
 	defer func() {
 		if err := dbConn.Close(); err != nil {
 			log.Fatal("Error closing the DB connection:", err)
@@ -83,14 +93,57 @@ func main() {
 	}
 	timeoutContext := time.Duration(timeout) * time.Second
 
+	// The reads go to the replicas of DATABASE_REPLICA_URLS while they are
+	// healthy, except in the requests which have written
+	replicas := openReplicas(driver)
+	defer func() {
+		for _, replica := range replicas {
+			if err := replica.Close(); err != nil {
+				log.Println("Error closing the replica connection:", err)
+			}
+		}
+	}()
+	db := repository.NewDB(dbConn, replicas...)
+	if len(replicas) > 0 {
+		go db.CheckReplicas(context.Background(), defaultReplicaCheckInterval)
+	}
+	expvar.Publish("database", expvar.Func(func() interface{} { return db.Stats() }))
+
 	// Initialize repositories and services
-	newsRepo := postgresRepo.NewNewsRepository(dbConn)
-	authorRepo := postgresRepo.NewAuthorRepository(dbConn)
-	topicRepo := postgresRepo.NewTopicRepository(dbConn)
-	newsTopicRepo := postgresRepo.NewNewsTopicRepository(dbConn)
+	var (
+		repos      = newRepositories(driver, db)
+		newsRepo   = repos.news
+		authorRepo = repos.authors
+		topicRepo  = repos.topics
+	)
+	newsTopicRepo := repos.newsTopics
+	auditRepo := repos.audit
+	transactor := repository.NewTransactor(dbConn)
+
+	// The reads and the anonymous list and detail responses are cached for
+	// CACHE_TTL, 0 disabling the caches, and invalidated by the writes of
+	// the entities they were built from
+	cacheTTL, err := time.ParseDuration(envOrDefault("CACHE_TTL", defaultCacheTTL))
+	if err != nil {
+		log.Fatal("Invalid CACHE_TTL:", err)
+	}
+	responseCacheMiddleware := func(next http.Handler) http.Handler { return next }
+	if cacheTTL > 0 {
+		entities := cache.New(envIntOrDefault("CACHE_SIZE", defaultCacheSize), cacheTTL)
+		responses := cache.New(envIntOrDefault("RESPONSE_CACHE_SIZE", defaultResponseCacheSize), cacheTTL)
+		entities.Cascade(responses)
+		newsRepo = cached.NewNewsRepository(newsRepo, entities)
+		authorRepo = cached.NewAuthorRepository(authorRepo, entities)
+		topicRepo = cached.NewTopicRepository(topicRepo, entities)
+		responseCacheMiddleware = rest.ResponseCache(responses)
+		expvar.Publish("cache", expvar.Func(func() interface{} {
+			return map[string]cache.Stats{"entities": entities.Stats(), "responses": responses.Stats()}
+		}))
+	}
 
-	ns := news.NewService(newsRepo, authorRepo, topicRepo, newsTopicRepo)
-	ts := topic.NewService(topicRepo)
+	ns := news.NewService(newsRepo, authorRepo, topicRepo, newsTopicRepo, auditRepo, transactor)
+	ts := topic.NewService(topicRepo, auditRepo, transactor)
+	as := audit.NewService(auditRepo)
 
 	// Initialize handlers with standard http handlers
 	mux := http.NewServeMux()
@@ -106,12 +159,108 @@ func main() {
 	// Swagger endpoint
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
 
-	rest.NewNewsHandler(mux, ns)
-	rest.NewTopicHandler(mux, ts)
+	// Runtime and cache metrics, for the holders of the ADMIN_API_KEY
+	adminKey := os.Getenv("ADMIN_API_KEY")
+	mux.Handle("GET /debug/vars", rest.RequireAdminKey(adminKey, expvar.Handler()))
+
+	// Updates and deletes apply to the version given by If-Match, which
+	// REQUIRE_IF_MATCH makes mandatory
+	requireIfMatch, _ := strconv.ParseBool(os.Getenv("REQUIRE_IF_MATCH"))
+	concurrency := rest.ConcurrencyConfig{RequireIfMatch: requireIfMatch}
+
+	// The resources are versioned under /v1, the unprefixed paths are kept
+	// as aliases which UNVERSIONED_DEPRECATION and UNVERSIONED_SUNSET
+	// (RFC 3339 dates) announce the end of
+	v1 := rest.APIVersion{
+		Name: "v1",
+		Register: func(mux *http.ServeMux) {
+			rest.NewNewsHandler(mux, ns, concurrency)
+			rest.NewTopicHandler(mux, ts, concurrency)
+			rest.NewAuditHandler(mux, as, rest.AuditConfig{AdminKey: adminKey})
+			rest.NewAuthorHandler(mux, ns)
+		},
+	}
+	rest.MountVersions(mux, rest.VersioningConfig{
+		Versions: []rest.APIVersion{v1},
+		Alias:    v1.Name,
+		AliasDeprecation: rest.Deprecation{
+			At:     envTimeOrZero("UNVERSIONED_DEPRECATION"),
+			Sunset: envTimeOrZero("UNVERSIONED_SUNSET"),
+			Link:   os.Getenv("UNVERSIONED_DEPRECATION_LINK"),
+		},
+	})
+	rest.NewFeedHandler(mux, ns, ts, ns, rest.FeedConfig{
+		SiteURL:     envOrDefault("SITE_URL", defaultSiteURL),
+		Title:       envOrDefault("FEED_TITLE", defaultFeedTitle),
+		Description: "Latest published news",
+	})
+	rest.NewSitemapHandler(mux, ns, ts, rest.SitemapConfig{
+		SiteURL:             envOrDefault("SITE_URL", defaultSiteURL),
+		PublicationName:     envOrDefault("FEED_TITLE", defaultFeedTitle),
+		PublicationLanguage: envOrDefault("SITE_LANGUAGE", defaultSiteLanguage),
+	})
+
+	graphQLServer, err := gql.NewServer(ns, ts, gql.Config{
+		MaxDepth:      envIntOrDefault("GRAPHQL_MAX_DEPTH", defaultGraphQLDepth),
+		MaxComplexity: envIntOrDefault("GRAPHQL_MAX_COMPLEXITY", defaultGraphQLCost),
+	})
+	if err != nil {
+		log.Fatal("Failed to build the GraphQL schema:", err)
+	}
+	rest.NewGraphQLHandler(mux, graphQLServer)
+
+	// Prepare rate limit policies
+	readPolicy, err := middleware.ParseRateLimitPolicy("read", envOrDefault("RATE_LIMIT_READ", defaultReadRateLimit))
+	if err != nil {
+		log.Fatal(err)
+	}
+	writePolicy, err := middleware.ParseRateLimitPolicy("write", envOrDefault("RATE_LIMIT_WRITE", defaultWriteRateLimit))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Clients are identified by IP address, or by their key when it is one
+	// of API_KEYS (comma separated) or ADMIN_API_KEY
+	clientKey := middleware.ClientKeyWithAPIKeys(append(strings.Split(os.Getenv("API_KEYS"), ","), adminKey)...)
+	rateLimitMiddleware := middleware.RateLimit(middleware.RateLimitConfig{
+		Read:    readPolicy,
+		Write:   writePolicy,
+		KeyFunc: clientKey,
+		Store:   middleware.NewMemoryRateLimitStore(),
+		Skip: func(r *http.Request) bool {
+			return r.URL.Path == "/health"
+		},
+		ErrorFunc: rest.WriteError,
+	})
+
+	// POST requests sent with an Idempotency-Key are replayed for IDEMPOTENCY_TTL
+	idempotencyTTL, err := time.ParseDuration(envOrDefault("IDEMPOTENCY_TTL", defaultIdempotencyTTL))
+	if err != nil {
+		log.Fatal("Invalid IDEMPOTENCY_TTL:", err)
+	}
+	// Imports are larger than the bodies it keeps, their retries skip the
+	// news already imported instead
+	idempotencyMiddleware := middleware.Idempotency(middleware.IdempotencyConfig{
+		TTL:     idempotencyTTL,
+		KeyFunc: clientKey,
+		Store:   middleware.NewMemoryIdempotencyStore(),
+		Skip: func(r *http.Request) bool {
+			return strings.HasSuffix(r.URL.Path, "/news/import")
+		},
+	})
+
+	// The links in the responses are under API_BASE_URL, or the URL the
+	// requests were sent to, behind a proxy with TRUST_FORWARDED_HEADERS
+	trustForwarded, _ := strconv.ParseBool(os.Getenv("TRUST_FORWARDED_HEADERS"))
+	linksMiddleware := rest.Links(rest.LinkConfig{
+		BaseURL:        os.Getenv("API_BASE_URL"),
+		TrustForwarded: trustForwarded,
+	})
 
 	// Middleware setup
-	handlerWithMiddleware := middleware.CORS(mux)
-	timeoutMiddleware := middleware.SetRequestContextWithTimeout(timeoutContext)
+	handlerWithMiddleware := rest.RouteNames(linksMiddleware(middleware.CORS(middleware.RequestInfo(rateLimitMiddleware(idempotencyMiddleware(responseCacheMiddleware(middleware.ReadYourWrites(mux))))))))
+	timeoutMiddleware := middleware.SetRequestContextWithTimeout(timeoutContext, func(r *http.Request) bool {
+		return strings.HasSuffix(r.URL.Path, "/export") || strings.HasSuffix(r.URL.Path, "/news/import")
+	})
 	handlerWithTimeout := timeoutMiddleware(handlerWithMiddleware)
 
 	// Start server
@@ -122,6 +271,47 @@ func main() {
 		WriteTimeout: 10 * time.Second,
 	}
 
+	// The gRPC API is served on its own port when GRPC_ADDRESS is set
+	if grpcAddress := os.Getenv("GRPC_ADDRESS"); grpcAddress != "" {
+		listener, err := net.Listen("tcp", grpcAddress)
+		if err != nil {
+			log.Fatal("Failed to listen for gRPC:", err)
+		}
+		grpcServer := rpc.NewServer(ns, ts)
+		go func() {
+			log.Printf("Starting gRPC server on %s", grpcAddress)
+			log.Fatal(grpcServer.Serve(listener))
+		}()
+	}
+
 	log.Printf("Starting server on %s", address)
 	log.Fatal(server.ListenAndServe())
 }
+
+func envOrDefault(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envTimeOrZero parses an RFC 3339 date, the zero time when it is not set
+func envTimeOrZero(key string) time.Time {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Fatalf("Invalid %s: %v", key, err)
+	}
+	return t
+}
+
+func envIntOrDefault(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
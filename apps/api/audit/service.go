@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// AuditRepository represent the audit log repository contract
+//
+//go:generate mockery --name AuditRepository
+type AuditRepository interface {
+	Fetch(ctx context.Context, filter domain.AuditFilter) (res []domain.AuditLog, totalData int64, err error)
+	Stream(ctx context.Context, filter domain.AuditFilter, fn func(domain.AuditLog) error) error
+}
+
+type Service struct {
+	auditRepo AuditRepository
+}
+
+// NewService will create a new audit service object
+func NewService(a AuditRepository) *Service {
+	return &Service{
+		auditRepo: a,
+	}
+}
+
+func (s *Service) Fetch(ctx context.Context, filter domain.AuditFilter) (res []domain.AuditLog, totalData int64, err error) {
+	return s.auditRepo.Fetch(ctx, filter)
+}
+
+// Export calls fn for every audit log entry matching filter, oldest first
+func (s *Service) Export(ctx context.Context, filter domain.AuditFilter, fn func(domain.AuditLog) error) error {
+	return s.auditRepo.Stream(ctx, filter, fn)
+}
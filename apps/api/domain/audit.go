@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditAction defines the kind of mutation recorded in the audit log
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntityType defines the kind of entity recorded in the audit log
+type AuditEntityType string
+
+const (
+	AuditEntityNews   AuditEntityType = "news"
+	AuditEntityTopic  AuditEntityType = "topic"
+	AuditEntityAuthor AuditEntityType = "author"
+)
+
+// AuditLog is representing one append-only audit log entry
+type AuditLog struct {
+	ID         int64           `json:"id" xml:"id"`
+	Actor      string          `json:"actor" xml:"actor"`
+	Action     AuditAction     `json:"action" xml:"action"`
+	EntityType AuditEntityType `json:"entity_type" xml:"entity_type"`
+	EntityID   int64           `json:"entity_id" xml:"entity_id"`
+	Before     json.RawMessage `json:"before" xml:"before"`
+	After      json.RawMessage `json:"after" xml:"after"`
+	RequestID  string          `json:"request_id" xml:"request_id"`
+	ClientIP   string          `json:"client_ip" xml:"client_ip"`
+	CreatedAt  time.Time       `json:"created_at" xml:"created_at"`
+}
+
+type AuditFilter struct {
+	EntityType string    `json:"entity_type"`
+	EntityID   int64     `json:"entity_id"`
+	Actor      string    `json:"actor"`
+	Action     string    `json:"action"`
+	StartDate  time.Time `json:"start_date"`
+	EndDate    time.Time `json:"end_date"`
+	Limit      int64     `json:"limit"`
+	Page       int64     `json:"page"`
+}
+
+// NewAuditLog builds an audit log entry for the request carried by ctx.
+// before and after are snapshots of the entity, nil when it does not exist.
+func NewAuditLog(ctx context.Context, action AuditAction, entityType AuditEntityType, entityID int64, before, after interface{}) (*AuditLog, error) {
+	info := RequestInfoFromContext(ctx)
+	entry := &AuditLog{
+		Actor:      info.Actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  info.RequestID,
+		ClientIP:   info.ClientIP,
+	}
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
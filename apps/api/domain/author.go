@@ -1,15 +1,72 @@
 package domain
 
+import "time"
+
 // Author representing the Author data struct
 type Author struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID        int64  `json:"id" xml:"id"`
+	Name      string `json:"name" xml:"name"`
+	CreatedAt string `json:"created_at" xml:"created_at"`
+	UpdatedAt string `json:"updated_at" xml:"updated_at"`
+	Links     *Links `json:"links,omitempty" xml:"links,omitempty"`
 }
 
 // AuthorNews representing the AuthorNews data struct
 type AuthorNews struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID   int64  `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+// StatsIntervals are the periods the news of an author can be counted per
+var StatsIntervals = []string{"week", "month"}
+
+// AuthorStatsFilter selects the news the stats of the authors are computed on
+type AuthorStatsFilter struct {
+	// AuthorID restricts the stats to one author, every author when 0
+	AuthorID  int64     `json:"author_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	// Interval is one of the StatsIntervals, month by default
+	Interval string `json:"interval"`
+}
+
+// NewsFilter returns the filter of the news the stats are computed on
+func (f AuthorStatsFilter) NewsFilter() NewsFilter {
+	return NewsFilter{AuthorID: f.AuthorID, StartDate: f.StartDate, EndDate: f.EndDate}
+}
+
+// AuthorStats summarizes the news created by an author
+type AuthorStats struct {
+	AuthorID  int64  `json:"author_id" xml:"author_id"`
+	Name      string `json:"name" xml:"name"`
+	Total     int64  `json:"total" xml:"total"`
+	Draft     int64  `json:"draft" xml:"draft"`
+	Published int64  `json:"published" xml:"published"`
+	Deleted   int64  `json:"deleted" xml:"deleted"`
+	// AverageWords is the average word count of the news which are not deleted
+	AverageWords float64 `json:"average_words" xml:"average_words"`
+	// AverageHoursToPublish is the average time between the creation of the
+	// published news and their first publication recorded in the audit log,
+	// nil when none was recorded
+	AverageHoursToPublish *float64 `json:"average_hours_to_publish,omitempty" xml:"average_hours_to_publish,omitempty"`
+	// TopTopics are the topics the author wrote the most news in
+	TopTopics []AuthorTopic `json:"top_topics" xml:"top_topics>topic"`
+	// Periods counts the news created per week or month, oldest first,
+	// periods without news are left out
+	Periods []AuthorPeriod `json:"periods" xml:"periods>period"`
+}
+
+// AuthorTopic is a topic with the number of news an author wrote in it
+type AuthorTopic struct {
+	ID    int64  `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name"`
+	Count int64  `json:"count" xml:"count"`
+}
+
+// AuthorPeriod counts the news created by an author during the week or month
+// starting at Start
+type AuthorPeriod struct {
+	Start     time.Time `json:"start" xml:"start"`
+	Total     int64     `json:"total" xml:"total"`
+	Published int64     `json:"published" xml:"published"`
 }
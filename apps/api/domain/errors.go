@@ -11,4 +11,26 @@ var (
 	ErrConflict = errors.New("your Item already exist")
 	// ErrBadParamInput will throw if the given request-body or params is not valid
 	ErrBadParamInput = errors.New("given Param is not valid")
+	// ErrTooManyRequests will throw if the client exceeded its rate limit
+	ErrTooManyRequests = errors.New("too many requests, please retry later")
+	// ErrMethodNotAllowed will throw if the resource does not support the request method
+	ErrMethodNotAllowed = errors.New("method not allowed")
+	// ErrUnprocessableEntity will throw if the request body can not be decoded
+	ErrUnprocessableEntity = errors.New("request body can not be processed")
+	// ErrNotAcceptable will throw if none of the accepted media types can be produced
+	ErrNotAcceptable = errors.New("none of the accepted media types is supported")
+	// ErrUnsupportedMediaType will throw if the request body media type is not supported
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrPreconditionFailed will throw if the item changed since the version the client expects
+	ErrPreconditionFailed = errors.New("your Item has been modified in the meantime")
+	// ErrPreconditionRequired will throw if a change does not tell the version it applies to
+	ErrPreconditionRequired = errors.New("the If-Match header is required")
+	// ErrBatchAborted will throw for the operations of an atomic batch which were not applied because another one failed
+	ErrBatchAborted = errors.New("not applied, another operation of the batch failed")
+	// ErrIdempotencyKeyReused will throw if an Idempotency-Key is sent again with a different request
+	ErrIdempotencyKeyReused = errors.New("the Idempotency-Key was already used for a different request")
+	// ErrUnauthorized will throw if a request does not carry the credentials required by the resource
+	ErrUnauthorized = errors.New("missing or invalid API key")
+	// ErrRequestInProgress will throw if the request of an Idempotency-Key is still being processed
+	ErrRequestInProgress = errors.New("a request with the same Idempotency-Key is in progress, please retry later")
 )
@@ -0,0 +1,52 @@
+package domain
+
+// ImportStatus defines the outcome of one imported row
+type ImportStatus string
+
+const (
+	ImportCreated ImportStatus = "created"
+	ImportSkipped ImportStatus = "skipped"
+	ImportFailed  ImportStatus = "failed"
+)
+
+// ImportOptions controls a bulk import
+type ImportOptions struct {
+	// DryRun validates and resolves every row without writing anything
+	DryRun bool `json:"dry_run"`
+	// BatchSize is the number of news inserted per transaction
+	BatchSize int `json:"batch_size"`
+}
+
+// ImportRowResult is representing the outcome of one imported row
+type ImportRowResult struct {
+	Line   int          `json:"line" xml:"line"`
+	Title  string       `json:"title" xml:"title"`
+	Status ImportStatus `json:"status" xml:"status"`
+	NewsID int64        `json:"news_id,omitempty" xml:"news_id,omitempty"`
+	Reason string       `json:"reason,omitempty" xml:"reason,omitempty"`
+}
+
+// ImportReport is representing the outcome of a bulk import
+type ImportReport struct {
+	DryRun        bool              `json:"dry_run" xml:"dry_run"`
+	Total         int               `json:"total" xml:"total"`
+	Created       int               `json:"created" xml:"created"`
+	Skipped       int               `json:"skipped" xml:"skipped"`
+	Failed        int               `json:"failed" xml:"failed"`
+	CreatedTopics []string          `json:"created_topics" xml:"created_topics>topic"`
+	Rows          []ImportRowResult `json:"rows" xml:"rows>row"`
+}
+
+// Add records the outcome of a row
+func (r *ImportReport) Add(result ImportRowResult) {
+	r.Total++
+	switch result.Status {
+	case ImportCreated:
+		r.Created++
+	case ImportSkipped:
+		r.Skipped++
+	case ImportFailed:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, result)
+}
@@ -0,0 +1,7 @@
+package domain
+
+// Links are the links of a resource in the REST responses, set by the REST
+// handlers only
+type Links struct {
+	Self string `json:"self" xml:"self"`
+}
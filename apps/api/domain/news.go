@@ -2,6 +2,7 @@ package domain
 
 import (
 	"errors"
+	"slices"
 	"time"
 )
 
@@ -26,25 +27,91 @@ func (s NewsStatus) Validate() error {
 
 // News is representing the News data struct
 type News struct {
-	ID        int64       `json:"id"`
-	Title     string      `json:"title"`
-	Content   string      `json:"content"`
-	Author    AuthorNews  `json:"author"` // just a little improvisation :)
-	Status    NewsStatus  `json:"status"`
-	UpdatedAt time.Time   `json:"updated_at"`
-	CreatedAt time.Time   `json:"created_at"`
-	Topics    []TopicNews `json:"topics"`
+	ID        int64       `json:"id" xml:"id"`
+	Title     string      `json:"title" xml:"title"`
+	Content   string      `json:"content" xml:"content"`
+	Author    AuthorNews  `json:"author" xml:"author"` // just a little improvisation :)
+	Status    NewsStatus  `json:"status" xml:"status"`
+	Version   int64       `json:"version" xml:"version"` // incremented by every update
+	UpdatedAt time.Time   `json:"updated_at" xml:"updated_at"`
+	CreatedAt time.Time   `json:"created_at" xml:"created_at"`
+	Topics    []TopicNews `json:"topics" xml:"topics>topic"`
+	Links     *Links      `json:"links,omitempty" xml:"links,omitempty"`
 }
 
 type NewsFilter struct {
 	ID        int64     `json:"id"`
+	IDs       []int64   `json:"ids"`
 	Title     string    `json:"title"`
 	Status    string    `json:"status"`
 	AuthorID  int64     `json:"author_id"`
+	TopicID   int64     `json:"topic_id"`
 	StartDate time.Time `json:"start_date"`
 	EndDate   time.Time `json:"end_date"`
 	Limit     int64     `json:"limit"`
 	Page      int64     `json:"page"`
 	SortBy    string    `json:"sort_by"`    // e.g., "created_at"
 	SortOrder string    `json:"sort_order"` // e.g., "asc" or "desc"
+	// Fields lists the NewsFields to load, every field when nil
+	Fields []string `json:"fields"`
+	// Include lists the NewsRelations to expand with the author name and
+	// topic names, every relation when nil
+	Include []string `json:"include"`
+}
+
+// NewsFields are the fields of News which can be selected
+var NewsFields = []string{"id", "title", "content", "author", "status", "version", "updated_at", "created_at", "topics"}
+
+// NewsRelations are the fields of News which can be expanded
+var NewsRelations = []string{"author", "topics"}
+
+// Selects tells whether field is loaded
+func (f NewsFilter) Selects(field string) bool {
+	return f.Fields == nil || slices.Contains(f.Fields, field)
+}
+
+// Expands tells whether the relation is loaded and expanded
+func (f NewsFilter) Expands(relation string) bool {
+	return f.Selects(relation) && (f.Include == nil || slices.Contains(f.Include, relation))
+}
+
+// NewsFacets are the facets news can be counted by
+var NewsFacets = []string{"status", "topic", "author", "month"}
+
+// WithoutFacet returns the filter without its condition on facet, the counts
+// of a facet show the news the other values would match
+func (f NewsFilter) WithoutFacet(facet string) NewsFilter {
+	switch facet {
+	case "status":
+		f.Status = ""
+	case "topic":
+		f.TopicID = 0
+	case "author":
+		f.AuthorID = 0
+	case "month":
+		f.StartDate, f.EndDate = time.Time{}, time.Time{}
+	}
+	return f
+}
+
+// FacetValue is the number of news having a value of a facet, Label is the
+// name of topics and authors
+type FacetValue struct {
+	Value string `json:"value" xml:"value"`
+	Label string `json:"label,omitempty" xml:"label,omitempty"`
+	Count int64  `json:"count" xml:"count"`
+}
+
+// Facet is representing the news counts per value of a facet, the most
+// frequent values first and the latest months first
+type Facet struct {
+	Name   string       `json:"name" xml:"name"`
+	Values []FacetValue `json:"values" xml:"values>value"`
+}
+
+// NewsArchive is representing the number of news created in a month
+type NewsArchive struct {
+	Month        time.Time `json:"month"`
+	Total        int64     `json:"total"`
+	LastModified time.Time `json:"last_modified"`
 }
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// AnonymousActor is used when a request does not identify its caller
+const AnonymousActor = "anonymous"
+
+// RequestInfo describes who made the current request
+type RequestInfo struct {
+	Actor     string `json:"actor"`
+	RequestID string `json:"request_id"`
+	ClientIP  string `json:"client_ip"`
+}
+
+type requestInfoKey struct{}
+
+// ContextWithRequestInfo returns a copy of ctx carrying info
+func ContextWithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the RequestInfo carried by ctx, the actor
+// defaults to AnonymousActor
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	if info.Actor == "" {
+		info.Actor = AnonymousActor
+	}
+	return info
+}
+
+// ActorName names the caller identified by a user ID or an API key without
+// exposing the key
+func ActorName(userID, apiKey string) string {
+	if userID != "" {
+		return "user:" + userID
+	}
+	if apiKey != "" {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "api-key:" + hex.EncodeToString(sum[:4])
+	}
+	return AnonymousActor
+}
+
+// NewRequestID returns a random request ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
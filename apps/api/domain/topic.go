@@ -4,16 +4,56 @@ import "time"
 
 // Topic representing the Topic data struct
 type Topic struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64     `json:"id" xml:"id"`
+	Name      string    `json:"name" xml:"name"`
+	Version   int64     `json:"version" xml:"version"` // incremented by every update
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" xml:"updated_at"`
+	// Stats is only loaded when asked for with TopicFilter.WithStats
+	Stats *TopicStats `json:"stats,omitempty" xml:"stats,omitempty"`
+	Links *Links      `json:"links,omitempty" xml:"links,omitempty"`
 }
 
+// TopicStats summarizes the news of a topic
+type TopicStats struct {
+	Published int64 `json:"published" xml:"published"`
+	Draft     int64 `json:"draft" xml:"draft"`
+	// LastPublishedAt is the creation date of the latest published news
+	LastPublishedAt *time.Time `json:"last_published_at,omitempty" xml:"last_published_at,omitempty"`
+	// TopAuthors are the authors with the most published news in the topic
+	TopAuthors []TopicAuthor `json:"top_authors" xml:"top_authors>author"`
+}
+
+// TopicAuthor is an author with the number of news they published in a topic
+type TopicAuthor struct {
+	ID    int64  `json:"id" xml:"id"`
+	Name  string `json:"name" xml:"name"`
+	Count int64  `json:"count" xml:"count"`
+}
+
+// TopicWeek counts the news of a topic created during the week starting on
+// Monday Week
+type TopicWeek struct {
+	Week      time.Time `json:"week" xml:"week"`
+	Published int64     `json:"published" xml:"published"`
+	Draft     int64     `json:"draft" xml:"draft"`
+}
+
+// TopicActivity holds the stats of a topic with their weekly time series,
+// oldest week first
+type TopicActivity struct {
+	TopicID int64 `json:"topic_id" xml:"topic_id"`
+	TopicStats
+	Weeks []TopicWeek `json:"weeks" xml:"weeks>week"`
+}
+
+// TopicStatsSorts are the stats topics can be sorted by when they are loaded
+var TopicStatsSorts = []string{"published", "draft", "last_published_at"}
+
 // TopicNews representing the TopicNews data struct
 type TopicNews struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID   int64  `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
 }
 
 type TopicFilter struct {
@@ -23,4 +63,7 @@ type TopicFilter struct {
 	Page      int64  `json:"page"`
 	SortBy    string `json:"sort_by"`    // e.g., "created_at"
 	SortOrder string `json:"sort_order"` // e.g., "asc" or "desc"
+	// WithStats loads the Stats of the topics, which can then be sorted by
+	// one of the TopicStatsSorts
+	WithStats bool `json:"with_stats"`
 }
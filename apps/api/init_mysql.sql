@@ -0,0 +1,144 @@
+-- Connect to the database created by the container
+USE news_and_topic_management;
+
+-- Drop the existing tables if they exist
+SET FOREIGN_KEY_CHECKS = 0;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS news_topic;
+DROP TABLE IF EXISTS topic;
+DROP TABLE IF EXISTS news;
+DROP TABLE IF EXISTS author;
+SET FOREIGN_KEY_CHECKS = 1;
+
+-- Table structure for table `news`
+CREATE TABLE news
+(
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    title      VARCHAR(45) NOT NULL,
+    content    TEXT        NOT NULL,
+    author_id  INT      DEFAULT 0,
+    status     VARCHAR(20) NOT NULL,
+    version    INT         NOT NULL DEFAULT 1,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+-- Inserting realistic data for table `news`
+INSERT INTO news (id, title, content, author_id, status, updated_at, created_at)
+VALUES (1, 'Health Benefits of cnr Mediterranean Diet',
+        'The Mediterranean diet has been associated with various health benefits...',
+        1, 'published', '2024-10-28 09:15:00', '2024-10-28 09:00:00'),
+       (2, 'AI and the Future of Work',
+        'Artificial Intelligence is transforming the workplace by automating tasks...',
+        2, 'published', '2024-10-27 14:30:00', '2024-10-27 14:00:00'),
+       (3, '10 Best Travel Destinations for 2024',
+        'Looking to plan your 2024 vacation? Here are ten must-visit destinations...',
+        3, 'draft', '2024-10-26 11:20:00', '2024-10-26 11:00:00'),
+       (4, 'Climate Change: What You Can Do to Help',
+        'As global temperatures continue to rise, individuals have the power to make cnr difference...',
+        4, 'deleted', '2024-10-25 08:10:00', '2024-10-25 08:00:00'),
+       (5, '5 Tips for Boosting Your Mental Health',
+        'Prioritizing mental health is essential. Here are five tips to improve your well-being...',
+        1, 'draft', '2024-10-24 13:45:00', '2024-10-24 13:00:00'),
+       (6, 'Advancements in Renewable Energy Technologies',
+        'Renewable energy sources such as solar and wind are becoming more efficient and accessible...',
+        2, 'published', '2024-10-23 10:30:00', '2024-10-23 10:00:00');
+
+-- Table structure for table `topic`
+CREATE TABLE topic
+(
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    version    INT          NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+-- Inserting data for table `topic`
+INSERT INTO topic (id, name)
+VALUES (1, 'Health'),
+       (2, 'Technology'),
+       (3, 'Travel'),
+       (4, 'Environment'),
+       (5, 'Mental Health');
+
+-- Table structure for table `news_topic` (associates `news` with `topic`)
+CREATE TABLE news_topic
+(
+    id       INT AUTO_INCREMENT PRIMARY KEY,
+    news_id  INT NOT NULL,
+    topic_id INT NOT NULL,
+    UNIQUE (news_id, topic_id),
+    FOREIGN KEY (news_id) REFERENCES news (id) ON DELETE CASCADE,
+    FOREIGN KEY (topic_id) REFERENCES topic (id) ON DELETE CASCADE
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+-- Inserting data for table `news_topic`
+INSERT INTO news_topic (news_id, topic_id)
+VALUES (1, 1),
+       (1, 4),
+       (2, 2),
+       (3, 3),
+       (4, 4),
+       (5, 5),
+       (6, 2);
+
+-- Table structure for table `author`
+CREATE TABLE author
+(
+    id         INT AUTO_INCREMENT PRIMARY KEY,
+    name       VARCHAR(200) DEFAULT '',
+    created_at DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     DEFAULT CURRENT_TIMESTAMP
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+-- Inserting data for table `author`
+INSERT INTO author (id, name, created_at, updated_at)
+VALUES (1, 'Doni', '2017-05-18 13:50:19', '2017-05-18 13:50:19'),
+       (2, 'Deni', '2017-05-19 14:00:00', '2017-05-19 14:00:00'),
+       (3, 'Dani', '2017-05-20 15:00:00', '2017-05-20 15:00:00'),
+       (4, 'Dini', '2017-05-21 16:00:00', '2017-05-21 16:00:00');
+
+-- Table structure for table `audit_log` (append-only trail of every mutation)
+CREATE TABLE audit_log
+(
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor       VARCHAR(200) NOT NULL,
+    action      VARCHAR(20)  NOT NULL,
+    entity_type VARCHAR(20)  NOT NULL,
+    entity_id   INT          NOT NULL,
+    `before`    JSON,
+    `after`     JSON,
+    request_id  VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip   VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at  DATETIME(6)  NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    INDEX audit_log_entity_idx (entity_type, entity_id),
+    INDEX audit_log_actor_idx (actor),
+    INDEX audit_log_created_at_idx (created_at)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_unicode_ci;
+
+-- Reject any change to existing audit entries
+DELIMITER //
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE
+    ON audit_log
+    FOR EACH ROW
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+//
+CREATE TRIGGER audit_log_no_delete
+    BEFORE DELETE
+    ON audit_log
+    FOR EACH ROW
+    SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+//
+DELIMITER ;
@@ -2,10 +2,12 @@
 \c news_and_topic_management;
 
 -- Drop the existing tables if they exist
+DROP TABLE IF EXISTS audit_log CASCADE;
 DROP TABLE IF EXISTS news_topic CASCADE;
 DROP TABLE IF EXISTS topic CASCADE;
 DROP TABLE IF EXISTS news CASCADE;
 DROP TABLE IF EXISTS author CASCADE;
+DROP FUNCTION IF EXISTS audit_log_append_only();
 
 -- Table structure for table `news`
 CREATE TABLE news
@@ -15,6 +17,7 @@ CREATE TABLE news
     content    TEXT        NOT NULL,
     author_id  INTEGER   DEFAULT 0,
     status     VARCHAR(20) NOT NULL,
+    version    INTEGER     NOT NULL DEFAULT 1,
     updated_at TIMESTAMP DEFAULT NOW(),
     created_at TIMESTAMP DEFAULT NOW()
 );
@@ -45,6 +48,7 @@ CREATE TABLE topic
 (
     id         SERIAL PRIMARY KEY,
     name       VARCHAR(100) NOT NULL,
+    version    INTEGER      NOT NULL DEFAULT 1,
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
@@ -91,3 +95,36 @@ VALUES (1, 'Doni', '2017-05-18 13:50:19', '2017-05-18 13:50:19'),
        (2, 'Deni', '2017-05-19 14:00:00', '2017-05-19 14:00:00'),
        (3, 'Dani', '2017-05-20 15:00:00', '2017-05-20 15:00:00'),
        (4, 'Dini', '2017-05-21 16:00:00', '2017-05-21 16:00:00');
+
+-- Table structure for table `audit_log` (append-only trail of every mutation)
+CREATE TABLE audit_log
+(
+    id          BIGSERIAL PRIMARY KEY,
+    actor       VARCHAR(200) NOT NULL,
+    action      VARCHAR(20)  NOT NULL,
+    entity_type VARCHAR(20)  NOT NULL,
+    entity_id   INTEGER      NOT NULL,
+    before      JSONB,
+    after       JSONB,
+    request_id  VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip   VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at  TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+CREATE INDEX audit_log_actor_idx ON audit_log (actor);
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+-- Reject any change to existing audit entries
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
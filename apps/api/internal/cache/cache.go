@@ -0,0 +1,247 @@
+// Package cache provides an in-memory LRU cache whose entries are tagged with
+// the entities they were built from, so that writing an entity invalidates
+// exactly the entries which carry its tag.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache is an LRU cache of values expiring after a TTL and bounded in number
+// of entries. It is safe for concurrent use.
+type Cache struct {
+	size int
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru holds the entries, the most recently used first
+	lru *list.List
+	// tagged indexes the keys of the entries by tag
+	tagged map[string]map[string]struct{}
+	// generation is incremented by every invalidation
+	generation uint64
+	dependents []*Cache
+
+	group singleflight.Group
+
+	hits, misses, evictions, invalidations atomic.Int64
+}
+
+type entry struct {
+	key     string
+	value   interface{}
+	tags    []string
+	expires time.Time
+}
+
+// Stats are the counters of a cache since it was created
+type Stats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`
+	Invalidations int64 `json:"invalidations"`
+	Entries       int   `json:"entries"`
+}
+
+// New returns a cache of at most size entries, each expiring ttl after it
+// was stored
+func New(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+		tagged:  map[string]map[string]struct{}{},
+	}
+}
+
+// Cascade makes the invalidations of c also invalidate the entries of d,
+// e.g. the responses built from the entities of c
+func (c *Cache) Cascade(d *Cache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dependents = append(c.dependents, d)
+}
+
+// Get returns the value stored under key and adds its tags to the collector
+// of ctx
+func (c *Cache) Get(ctx context.Context, key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if ok && c.now().After(elem.Value.(*entry).expires) {
+		c.remove(elem)
+		ok = false
+	}
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	c.lru.MoveToFront(elem)
+	e := elem.Value.(*entry)
+	Collect(ctx, e.tags...)
+	return e.value, true
+}
+
+// Generation identifies the invalidations which happened so far, see SetSince
+func (c *Cache) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Set stores value under key with the tags of the entities it was built from
+func (c *Cache) Set(key string, value interface{}, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(key, value, tags)
+}
+
+// SetSince stores value like Set unless the cache was invalidated since
+// generation, when value may have been built from stale entities
+func (c *Cache) SetSince(generation uint64, key string, value interface{}, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.set(key, value, tags)
+	}
+}
+
+func (c *Cache) set(key string, value interface{}, tags []string) {
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+
+	elem := c.lru.PushFront(&entry{key: key, value: value, tags: tags, expires: c.now().Add(c.ttl)})
+	c.entries[key] = elem
+	for _, tag := range tags {
+		if c.tagged[tag] == nil {
+			c.tagged[tag] = map[string]struct{}{}
+		}
+		c.tagged[tag][key] = struct{}{}
+	}
+
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	e := c.lru.Remove(elem).(*entry)
+	delete(c.entries, e.key)
+	for _, tag := range e.tags {
+		delete(c.tagged[tag], e.key)
+		if len(c.tagged[tag]) == 0 {
+			delete(c.tagged, tag)
+		}
+	}
+}
+
+// Load returns the value stored under key, or loads it with load on a miss
+// and stores it with the tags load returns. Concurrent misses of a key share
+// a single load, run with the context of the first one. Errors are not
+// cached.
+func (c *Cache) Load(ctx context.Context, key string, load func() (interface{}, []string, error)) (interface{}, error) {
+	if value, ok := c.Get(ctx, key); ok {
+		return value, nil
+	}
+
+	res, err, _ := c.group.Do(key, func() (interface{}, error) {
+		generation := c.Generation()
+		value, tags, err := load()
+		if err != nil {
+			return nil, err
+		}
+		c.SetSince(generation, key, value, tags...)
+		return &entry{value: value, tags: tags}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	loaded := res.(*entry)
+	Collect(ctx, loaded.tags...)
+	return loaded.value, nil
+}
+
+// Invalidate removes the entries carrying any of tags, from c and from the
+// caches it cascades to
+func (c *Cache) Invalidate(tags ...string) {
+	c.mu.Lock()
+	c.generation++
+	for _, tag := range tags {
+		for key := range c.tagged[tag] {
+			c.remove(c.entries[key])
+		}
+	}
+	dependents := c.dependents
+	c.mu.Unlock()
+
+	c.invalidations.Add(1)
+	for _, d := range dependents {
+		d.Invalidate(tags...)
+	}
+}
+
+// Stats returns the counters of c
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Evictions:     c.evictions.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       entries,
+	}
+}
+
+type collectorKey struct{}
+
+// collector gathers the tags of the entries read while serving a request
+type collector struct {
+	mu   sync.Mutex
+	tags map[string]struct{}
+}
+
+// WithCollector returns a context collecting the tags of the entries read
+// with it, and a function returning them
+func WithCollector(ctx context.Context) (context.Context, func() []string) {
+	col := &collector{tags: map[string]struct{}{}}
+	return context.WithValue(ctx, collectorKey{}, col), func() []string {
+		col.mu.Lock()
+		defer col.mu.Unlock()
+		tags := make([]string, 0, len(col.tags))
+		for tag := range col.tags {
+			tags = append(tags, tag)
+		}
+		return tags
+	}
+}
+
+// Collect adds tags to the collector of ctx, if any. Reads which are not
+// cached collect the tags their results depend on too.
+func Collect(ctx context.Context, tags ...string) {
+	col, ok := ctx.Value(collectorKey{}).(*collector)
+	if !ok {
+		return
+	}
+	col.mu.Lock()
+	defer col.mu.Unlock()
+	for _, tag := range tags {
+		col.tags[tag] = struct{}{}
+	}
+}
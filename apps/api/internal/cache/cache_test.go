@@ -0,0 +1,135 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+)
+
+func TestLoad(t *testing.T) {
+	c := cache.New(10, time.Minute)
+	ctx := context.Background()
+
+	var loads atomic.Int64
+	release := make(chan struct{})
+	load := func() (interface{}, []string, error) {
+		loads.Add(1)
+		<-release
+		return "Health", []string{"topic:1"}, nil
+	}
+
+	// concurrent misses share a single load
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Load(ctx, "topic.GetByID[1]", load)
+			assert.NoError(t, err)
+			assert.Equal(t, "Health", v)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int64(1), loads.Load())
+
+	v, err := c.Load(ctx, "topic.GetByID[1]", load)
+	require.NoError(t, err)
+	assert.Equal(t, "Health", v)
+	assert.Equal(t, int64(1), loads.Load())
+
+	// errors are not cached
+	errExpected := errors.New("connection lost")
+	_, err = c.Load(ctx, "topic.GetByID[2]", func() (interface{}, []string, error) {
+		return nil, nil, errExpected
+	})
+	assert.ErrorIs(t, err, errExpected)
+	_, ok := c.Get(ctx, "topic.GetByID[2]")
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, 1, stats.Entries)
+	assert.Positive(t, stats.Hits)
+	assert.Positive(t, stats.Misses)
+}
+
+func TestInvalidate(t *testing.T) {
+	entities := cache.New(10, time.Minute)
+	responses := cache.New(10, time.Minute)
+	entities.Cascade(responses)
+	ctx := context.Background()
+
+	entities.Set("news.GetByID[1]", "Health Benefits", "news:1", "topic:1")
+	entities.Set("news.GetByID[2]", "Covid 19 is gone", "news:2", "topic:2")
+	entities.Set("news.Fetch[{}]", "page", "news:1", "news:2", "news:*")
+	responses.Set("/news/1", "response", "news:1", "topic:1")
+
+	entities.Invalidate("topic:1")
+	_, ok := entities.Get(ctx, "news.GetByID[1]")
+	assert.False(t, ok)
+	_, ok = responses.Get(ctx, "/news/1")
+	assert.False(t, ok)
+	_, ok = entities.Get(ctx, "news.GetByID[2]")
+	assert.True(t, ok)
+	_, ok = entities.Get(ctx, "news.Fetch[{}]")
+	assert.True(t, ok)
+
+	entities.Invalidate("news:*")
+	_, ok = entities.Get(ctx, "news.Fetch[{}]")
+	assert.False(t, ok)
+	_, ok = entities.Get(ctx, "news.GetByID[2]")
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), entities.Stats().Invalidations)
+
+	// values loaded before an invalidation are not stored
+	generation := entities.Generation()
+	entities.Invalidate("news:2")
+	entities.SetSince(generation, "news.GetByID[2]", "stale", "news:2")
+	_, ok = entities.Get(ctx, "news.GetByID[2]")
+	assert.False(t, ok)
+}
+
+func TestEviction(t *testing.T) {
+	c := cache.New(2, 20*time.Millisecond)
+	ctx := context.Background()
+
+	c.Set("1", 1)
+	c.Set("2", 2)
+	_, ok := c.Get(ctx, "1")
+	require.True(t, ok)
+	// the least recently used entry is evicted
+	c.Set("3", 3)
+	_, ok = c.Get(ctx, "2")
+	assert.False(t, ok)
+	_, ok = c.Get(ctx, "1")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), c.Stats().Evictions)
+
+	time.Sleep(30 * time.Millisecond)
+	_, ok = c.Get(ctx, "1")
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Stats().Entries)
+}
+
+func TestCollect(t *testing.T) {
+	c := cache.New(10, time.Minute)
+	c.Set("news.GetByID[1]", "Health Benefits", "news:1", "author:1")
+
+	ctx, tags := cache.WithCollector(context.Background())
+	_, ok := c.Get(ctx, "news.GetByID[1]")
+	require.True(t, ok)
+	cache.Collect(ctx, "news:*")
+	assert.ElementsMatch(t, []string{"news:1", "author:1", "news:*"}, tags())
+
+	// without collector
+	cache.Collect(context.Background(), "news:*")
+}
@@ -0,0 +1,57 @@
+package news
+
+// BatchOp is the kind of an operation of a batch
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchStatus BatchOp = "status"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchMode tells what happens to a batch when one of its operations fails
+type BatchMode string
+
+const (
+	// BatchAtomic applies every operation or none of them
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies the operations which succeed and reports the
+	// others
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// BatchOperation is one change of a batch. Create is set for BatchCreate,
+// Update for BatchUpdate and BatchStatus. Version applies to updates and
+// deletes, 0 changes any version.
+type BatchOperation struct {
+	Op      BatchOp
+	ID      int64
+	Version int64
+	Create  *CreateNewsReq
+	Update  *UpdateNewsReq
+}
+
+// BatchResult is the outcome of one operation of a batch, ID is the news
+// created or changed
+type BatchResult struct {
+	ID  int64
+	Err error
+}
+
+// BatchItemResult is the outcome of one operation as reported to the client
+type BatchItemResult struct {
+	Index   int     `json:"index" xml:"index"`
+	Op      BatchOp `json:"op" xml:"op"`
+	ID      int64   `json:"id,omitempty" xml:"id,omitempty"`
+	Status  int     `json:"status" xml:"status"`
+	Message string  `json:"message,omitempty" xml:"message,omitempty"`
+}
+
+// BatchResponse is the body answered to a batch
+type BatchResponse struct {
+	Mode      BatchMode         `json:"mode" xml:"mode"`
+	Succeeded int               `json:"succeeded" xml:"succeeded"`
+	Failed    int               `json:"failed" xml:"failed"`
+	Results   []BatchItemResult `json:"results" xml:"results>result"`
+}
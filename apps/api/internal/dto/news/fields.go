@@ -0,0 +1,76 @@
+package news
+
+import (
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// News is a news restricted to a sparse fieldset, the fields which were not
+// selected are nil and left out of the response
+type News struct {
+	ID        *int64             `json:"id,omitempty" xml:"id,omitempty"`
+	Title     *string            `json:"title,omitempty" xml:"title,omitempty"`
+	Content   *string            `json:"content,omitempty" xml:"content,omitempty"`
+	Author    *Relation          `json:"author,omitempty" xml:"author,omitempty"`
+	Status    *domain.NewsStatus `json:"status,omitempty" xml:"status,omitempty"`
+	Version   *int64             `json:"version,omitempty" xml:"version,omitempty"`
+	UpdatedAt *time.Time         `json:"updated_at,omitempty" xml:"updated_at,omitempty"`
+	CreatedAt *time.Time         `json:"created_at,omitempty" xml:"created_at,omitempty"`
+	Topics    *[]Relation        `json:"topics,omitempty" xml:"topics>topic,omitempty"`
+	Links     *domain.Links      `json:"links,omitempty" xml:"links,omitempty"`
+}
+
+// Relation is the author or a topic of a News, its name is only set when
+// the relation is expanded
+type Relation struct {
+	ID   int64   `json:"id" xml:"id"`
+	Name *string `json:"name,omitempty" xml:"name,omitempty"`
+}
+
+// SelectFields returns the fields of n selected by filter, with the names of
+// the relations it expands. The links are only kept with every field.
+func SelectFields(n domain.News, filter domain.NewsFilter) News {
+	var res News
+	fields := filter.Fields
+	if fields == nil {
+		fields = domain.NewsFields
+		res.Links = n.Links
+	}
+	for _, field := range fields {
+		switch field {
+		case "id":
+			res.ID = &n.ID
+		case "title":
+			res.Title = &n.Title
+		case "content":
+			res.Content = &n.Content
+		case "author":
+			author := relation(n.Author.ID, n.Author.Name, filter.Expands("author"))
+			res.Author = &author
+		case "status":
+			res.Status = &n.Status
+		case "version":
+			res.Version = &n.Version
+		case "updated_at":
+			res.UpdatedAt = &n.UpdatedAt
+		case "created_at":
+			res.CreatedAt = &n.CreatedAt
+		case "topics":
+			topics := make([]Relation, 0, len(n.Topics))
+			for _, t := range n.Topics {
+				topics = append(topics, relation(t.ID, t.Name, filter.Expands("topics")))
+			}
+			res.Topics = &topics
+		}
+	}
+	return res
+}
+
+func relation(id int64, name string, expanded bool) Relation {
+	r := Relation{ID: id}
+	if expanded {
+		r.Name = &name
+	}
+	return r
+}
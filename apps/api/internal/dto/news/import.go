@@ -0,0 +1,246 @@
+package news
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	// topicSeparator separates topics inside a CSV cell, e.g. "Health|2"
+	topicSeparator = "|"
+	maxLineSize    = 10 * 1024 * 1024
+)
+
+// ImportNewsReq is one row of a bulk import
+type ImportNewsReq struct {
+	Line     int               `json:"-"`
+	Title    string            `json:"title"`
+	Content  string            `json:"content"`
+	AuthorID int64             `json:"author_id"`
+	Author   string            `json:"author"` // author name, used when AuthorID is not set
+	Status   domain.NewsStatus `json:"status"`
+	TopicIDs []int64           `json:"topic_ids"`
+	Topics   []string          `json:"topics"` // topic names, created when missing
+	// CreatedAt and UpdatedAt default to the import time
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RowError reports a row which could not be read, the import goes on with
+// the next row
+type RowError struct {
+	Line int
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// ImportReader yields import rows until io.EOF
+type ImportReader interface {
+	Next() (ImportNewsReq, error)
+}
+
+// NewImportReader will create a reader for the given format, csv or ndjson
+func NewImportReader(r io.Reader, format string) (ImportReader, error) {
+	switch format {
+	case ImportFormatCSV:
+		return NewCSVImportReader(r)
+	case ImportFormatNDJSON:
+		return NewNDJSONImportReader(r), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported import format %q", domain.ErrBadParamInput, format)
+	}
+}
+
+// CSVImportReader reads rows from a CSV file with a header line naming the
+// columns: title, content, author_id or author, status, topics, created_at,
+// updated_at. Topics are topic IDs or names separated by "|".
+type CSVImportReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+// NewCSVImportReader will create a CSVImportReader, reading the header line
+func NewCSVImportReader(r io.Reader) (*CSVImportReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read CSV header: %v", domain.ErrBadParamInput, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"title", "content", "status"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing CSV column %q", domain.ErrBadParamInput, required)
+		}
+	}
+
+	return &CSVImportReader{reader: reader, columns: columns}, nil
+}
+
+func (c *CSVImportReader) get(record []string, column string) string {
+	i, ok := c.columns[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// Next returns the next row
+func (c *CSVImportReader) Next() (ImportNewsReq, error) {
+	record, err := c.reader.Read()
+	if err == io.EOF {
+		return ImportNewsReq{}, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return ImportNewsReq{}, &RowError{Line: parseErr.StartLine, Err: parseErr.Err}
+	}
+	if err != nil {
+		return ImportNewsReq{}, err
+	}
+	c.line, _ = c.reader.FieldPos(0)
+
+	row := ImportNewsReq{
+		Line:    c.line,
+		Title:   c.get(record, "title"),
+		Content: c.get(record, "content"),
+		Author:  c.get(record, "author"),
+		Status:  domain.NewsStatus(c.get(record, "status")),
+	}
+
+	if authorID := c.get(record, "author_id"); authorID != "" {
+		if row.AuthorID, err = strconv.ParseInt(authorID, 10, 64); err != nil {
+			return row, &RowError{Line: c.line, Err: fmt.Errorf("invalid author_id %q", authorID)}
+		}
+	} else if authorID, err := strconv.ParseInt(row.Author, 10, 64); err == nil {
+		row.AuthorID, row.Author = authorID, ""
+	}
+
+	for _, topic := range strings.Split(c.get(record, "topics"), topicSeparator) {
+		topic = strings.TrimSpace(topic)
+		if topic == "" {
+			continue
+		}
+		if topicID, err := strconv.ParseInt(topic, 10, 64); err == nil {
+			row.TopicIDs = append(row.TopicIDs, topicID)
+		} else {
+			row.Topics = append(row.Topics, topic)
+		}
+	}
+
+	if row.CreatedAt, err = parseImportTime(c.get(record, "created_at")); err != nil {
+		return row, &RowError{Line: c.line, Err: fmt.Errorf("invalid created_at: %w", err)}
+	}
+	if row.UpdatedAt, err = parseImportTime(c.get(record, "updated_at")); err != nil {
+		return row, &RowError{Line: c.line, Err: fmt.Errorf("invalid updated_at: %w", err)}
+	}
+	return row, nil
+}
+
+func parseImportTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// NDJSONImportReader reads one JSON object per line, topics may mix topic
+// IDs and names
+type NDJSONImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewNDJSONImportReader will create a NDJSONImportReader
+func NewNDJSONImportReader(r io.Reader) *NDJSONImportReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	return &NDJSONImportReader{scanner: scanner}
+}
+
+// Next returns the next row, blank lines are skipped
+func (n *NDJSONImportReader) Next() (ImportNewsReq, error) {
+	for n.scanner.Scan() {
+		n.line++
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var raw struct {
+			ImportNewsReq
+			Author json.RawMessage   `json:"author"`
+			Topics []json.RawMessage `json:"topics"`
+		}
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return ImportNewsReq{}, &RowError{Line: n.line, Err: err}
+		}
+
+		row := raw.ImportNewsReq
+		row.Line = n.line
+		if err := decodeAuthor(raw.Author, &row); err != nil {
+			return row, &RowError{Line: n.line, Err: err}
+		}
+		for _, topic := range raw.Topics {
+			var topicID int64
+			var name string
+			switch {
+			case json.Unmarshal(topic, &topicID) == nil:
+				row.TopicIDs = append(row.TopicIDs, topicID)
+			case json.Unmarshal(topic, &name) == nil:
+				row.Topics = append(row.Topics, name)
+			default:
+				return row, &RowError{Line: n.line, Err: fmt.Errorf("invalid topic %s", topic)}
+			}
+		}
+		return row, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return ImportNewsReq{}, err
+	}
+	return ImportNewsReq{}, io.EOF
+}
+
+// decodeAuthor accepts the author as a name or an ID
+func decodeAuthor(raw json.RawMessage, row *ImportNewsReq) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var authorID int64
+	if err := json.Unmarshal(raw, &authorID); err == nil {
+		row.AuthorID = authorID
+		return nil
+	}
+	if err := json.Unmarshal(raw, &row.Author); err != nil {
+		return fmt.Errorf("invalid author %s", raw)
+	}
+	return nil
+}
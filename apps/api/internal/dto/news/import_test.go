@@ -0,0 +1,91 @@
+package news_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+)
+
+func readAll(t *testing.T, reader news.ImportReader) (rows []news.ImportNewsReq, rowErrs []*news.RowError) {
+	t.Helper()
+
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			return
+		}
+		var rowErr *news.RowError
+		if errors.As(err, &rowErr) {
+			rowErrs = append(rowErrs, rowErr)
+			continue
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVImportReader(t *testing.T) {
+	input := "\ufefftitle,content,author,status,topics,created_at\n" +
+		"First,\"Hello, world\",Deni,published,Health|2,2024-10-28T09:00:00Z\n" +
+		"Second,Body,3,draft,,\n" +
+		"Third,Body,Deni,draft,,yesterday\n"
+
+	reader, err := news.NewImportReader(strings.NewReader(input), news.ImportFormatCSV)
+	require.NoError(t, err)
+
+	rows, rowErrs := readAll(t, reader)
+	require.Len(t, rows, 2)
+	assert.Equal(t, news.ImportNewsReq{
+		Line:      2,
+		Title:     "First",
+		Content:   "Hello, world",
+		Author:    "Deni",
+		Status:    domain.Published,
+		TopicIDs:  []int64{2},
+		Topics:    []string{"Health"},
+		CreatedAt: time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC),
+	}, rows[0])
+	assert.Equal(t, int64(3), rows[1].AuthorID)
+	assert.Empty(t, rows[1].Author)
+
+	require.Len(t, rowErrs, 1)
+	assert.Equal(t, 4, rowErrs[0].Line)
+}
+
+func TestCSVImportReaderMissingColumn(t *testing.T) {
+	_, err := news.NewImportReader(strings.NewReader("title,content\n"), news.ImportFormatCSV)
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+
+	_, err = news.NewImportReader(strings.NewReader(""), "xml")
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
+
+func TestNDJSONImportReader(t *testing.T) {
+	input := `{"title":"First","content":"Body","author":"Deni","status":"published","topics":["Health",2]}
+
+{"title":"Second","content":"Body","author":3,"status":"draft"}
+{"title":
+{"title":"Third","content":"Body","author_id":1,"status":"draft","topics":[true]}
+`
+
+	rows, rowErrs := readAll(t, news.NewNDJSONImportReader(strings.NewReader(input)))
+	require.Len(t, rows, 2)
+	assert.Equal(t, 1, rows[0].Line)
+	assert.Equal(t, "Deni", rows[0].Author)
+	assert.Equal(t, []int64{2}, rows[0].TopicIDs)
+	assert.Equal(t, []string{"Health"}, rows[0].Topics)
+	assert.Equal(t, 3, rows[1].Line)
+	assert.Equal(t, int64(3), rows[1].AuthorID)
+
+	require.Len(t, rowErrs, 2)
+	assert.Equal(t, 4, rowErrs[0].Line)
+	assert.Equal(t, 5, rowErrs[1].Line)
+}
@@ -6,22 +6,62 @@ import (
 )
 
 type CreateNewsReq struct {
-	ID        int64             `json:"id"`
-	Title     string            `json:"title" validate:"required"`
-	Content   string            `json:"content" validate:"required"`
-	AuthorID  int64             `json:"author_id" validate:"required"`
-	Status    domain.NewsStatus `json:"status" validate:"required"`
-	TopicIDs  []int64           `json:"topic_ids" validate:"required"`
-	UpdatedAt time.Time         `json:"updated_at"`
-	CreatedAt time.Time         `json:"created_at"`
+	ID        int64             `json:"id" xml:"id"`
+	Title     string            `json:"title" xml:"title" validate:"required"`
+	Content   string            `json:"content" xml:"content" validate:"required"`
+	AuthorID  int64             `json:"author_id" xml:"author_id" validate:"required"`
+	Status    domain.NewsStatus `json:"status" xml:"status" validate:"required"`
+	TopicIDs  []int64           `json:"topic_ids" xml:"topic_ids>topic_id" validate:"required"`
+	UpdatedAt time.Time         `json:"updated_at" xml:"updated_at"`
+	CreatedAt time.Time         `json:"created_at" xml:"created_at"`
 }
 
 type UpdateNewsReq struct {
-	ID        *int64             `json:"id"`         // Pointer to allow for optional ID
-	Title     *string            `json:"title"`      // Pointer to allow for optional title
-	Content   *string            `json:"content"`    // Pointer to allow for optional content
-	AuthorID  *int64             `json:"author_id"`  // Pointer to allow for optional author ID
-	Status    *domain.NewsStatus `json:"status"`     // Pointer to allow for optional status
-	TopicIDs  *[]int64           `json:"topic_ids"`  // Pointer to allow for optional topic IDs
-	UpdatedAt *time.Time         `json:"updated_at"` // Pointer to allow for optional update timestamp
+	ID        *int64             `json:"id" xml:"id"`                        // Pointer to allow for optional ID
+	Title     *string            `json:"title" xml:"title"`                  // Pointer to allow for optional title
+	Content   *string            `json:"content" xml:"content"`              // Pointer to allow for optional content
+	AuthorID  *int64             `json:"author_id" xml:"author_id"`          // Pointer to allow for optional author ID
+	Status    *domain.NewsStatus `json:"status" xml:"status"`                // Pointer to allow for optional status
+	TopicIDs  *[]int64           `json:"topic_ids" xml:"topic_ids>topic_id"` // Pointer to allow for optional topic IDs
+	UpdatedAt *time.Time         `json:"updated_at" xml:"updated_at"`        // Pointer to allow for optional update timestamp
+	Version   *int64             `json:"-" xml:"-"`                          // Version the update applies to, from If-Match
+}
+
+// ReplaceNewsReq holds every editable field of a news, it is the body of PUT
+// and the document JSON patches apply to
+type ReplaceNewsReq struct {
+	Title    string            `json:"title" xml:"title" validate:"required"`
+	Content  string            `json:"content" xml:"content" validate:"required"`
+	AuthorID int64             `json:"author_id" xml:"author_id" validate:"required"`
+	Status   domain.NewsStatus `json:"status" xml:"status" validate:"required"`
+	TopicIDs []int64           `json:"topic_ids" xml:"topic_ids>topic_id" validate:"required"`
+}
+
+// NewReplaceNewsReq returns the editable fields of n
+func NewReplaceNewsReq(n domain.News) ReplaceNewsReq {
+	req := ReplaceNewsReq{
+		Title:    n.Title,
+		Content:  n.Content,
+		AuthorID: n.Author.ID,
+		Status:   n.Status,
+		TopicIDs: make([]int64, 0, len(n.Topics)),
+	}
+	for _, t := range n.Topics {
+		req.TopicIDs = append(req.TopicIDs, t.ID)
+	}
+	return req
+}
+
+// UpdateRequest returns the update setting every field of the news id to the
+// ones of r
+func (r ReplaceNewsReq) UpdateRequest(id int64) *UpdateNewsReq {
+	topicIDs := r.TopicIDs
+	return &UpdateNewsReq{
+		ID:       &id,
+		Title:    &r.Title,
+		Content:  &r.Content,
+		AuthorID: &r.AuthorID,
+		Status:   &r.Status,
+		TopicIDs: &topicIDs,
+	}
 }
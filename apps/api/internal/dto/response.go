@@ -8,18 +8,36 @@ import (
 )
 
 type PaginationMeta struct {
-	CurrentPage int64 `json:"current_page"`
-	TotalPages  int64 `json:"total_pages"`
-	TotalData   int64 `json:"total_data"`
+	CurrentPage int64 `json:"current_page" xml:"current_page"`
+	TotalPages  int64 `json:"total_pages" xml:"total_pages"`
+	TotalData   int64 `json:"total_data" xml:"total_data"`
+	// Facets holds the counts asked for with the facets query parameter
+	Facets []domain.Facet  `json:"facets,omitempty" xml:"facets>facet,omitempty"`
+	Links  PaginationLinks `json:"links" xml:"links"`
+}
+
+// PaginationLinks are the URLs of the pages of a listing, with the same query
+// parameters but page. Prev and Next are left out on the first and last pages.
+type PaginationLinks struct {
+	Self  string `json:"self" xml:"self"`
+	First string `json:"first" xml:"first"`
+	Prev  string `json:"prev,omitempty" xml:"prev,omitempty"`
+	Next  string `json:"next,omitempty" xml:"next,omitempty"`
+	Last  string `json:"last" xml:"last"`
 }
 
 type Response struct {
-	Data interface{}    `json:"data"`
-	Meta PaginationMeta `json:"meta"`
+	Data interface{}    `json:"data" xml:"data>item"`
+	Meta PaginationMeta `json:"meta" xml:"meta"`
 }
 
 type ResponseError struct {
-	Message string `json:"message"`
+	Message string `json:"message" xml:"message"`
+}
+
+// ResponseMessage is the body of successful requests without data
+type ResponseMessage struct {
+	Message string `json:"message" xml:"message"`
 }
 
 func GetStatusCode(err error) int {
@@ -31,10 +49,30 @@ func GetStatusCode(err error) int {
 	switch {
 	case errors.Is(err, domain.ErrInternalServerError):
 		return http.StatusInternalServerError
+	case errors.Is(err, domain.ErrUnauthorized):
+		return http.StatusUnauthorized
 	case errors.Is(err, domain.ErrNotFound):
 		return http.StatusNotFound
 	case errors.Is(err, domain.ErrConflict):
 		return http.StatusConflict
+	case errors.Is(err, domain.ErrBadParamInput):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrTooManyRequests):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrMethodNotAllowed):
+		return http.StatusMethodNotAllowed
+	case errors.Is(err, domain.ErrUnprocessableEntity):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrNotAcceptable):
+		return http.StatusNotAcceptable
+	case errors.Is(err, domain.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, domain.ErrPreconditionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, domain.ErrBatchAborted):
+		return http.StatusFailedDependency
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,7 @@
+package topic
+
+// ReplaceTopicReq holds every editable field of a topic, it is the body of
+// PUT and the document JSON patches apply to
+type ReplaceTopicReq struct {
+	Name string `json:"name" xml:"name" validate:"required"`
+}
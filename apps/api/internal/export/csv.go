@@ -0,0 +1,43 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []string
+	started bool
+}
+
+func newCSVWriter(w io.Writer, columns []string) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), columns: columns}
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.writer.Write(c.columns)
+}
+
+func (c *csvWriter) Write(row []interface{}) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	record := make([]string, len(row))
+	for i, v := range row {
+		record[i] = formatCell(v)
+	}
+	return c.writer.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
@@ -0,0 +1,93 @@
+// Package export writes tables of rows as CSV, NDJSON or XLSX while they are
+// read, without holding the whole table in memory.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+
+	// ListSeparator joins list cells in CSV and XLSX, the separator the news
+	// import expects for topics
+	ListSeparator = "|"
+)
+
+// ErrTooManyRows is returned when a table does not fit in the format
+var ErrTooManyRows = errors.New("too many rows for the export format")
+
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Writer writes the rows of a table. Cells are strings, integers, floats,
+// time.Time, []string or []int64 values, in the order of the columns. Nothing is written to the
+// underlying writer before the first row or Close.
+type Writer interface {
+	Write(row []interface{}) error
+	Close() error
+}
+
+// ContentType returns the media type of format
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// NewWriter will create a Writer for format with the given column names
+func NewWriter(w io.Writer, format string, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w, columns), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported export format %q", domain.ErrBadParamInput, format)
+	}
+}
+
+// formatCell renders a cell as text, as used by CSV and XLSX
+func formatCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.UTC().Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, ListSeparator)
+	case []int64:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = strconv.FormatInt(item, 10)
+		}
+		return strings.Join(items, ListSeparator)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
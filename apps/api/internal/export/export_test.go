@@ -0,0 +1,103 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/export"
+)
+
+var (
+	columns   = []string{"id", "title", "topics", "created_at"}
+	createdAt = time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)
+	rows      = [][]interface{}{
+		{int64(1), "Health, \"diet\" & <food>", []string{"Health", "Food"}, createdAt},
+		{int64(2), "Empty", []string(nil), time.Time{}},
+	}
+)
+
+func writeTable(t *testing.T, format string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, format, columns)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, w.Write(row))
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	got := writeTable(t, export.FormatCSV)
+	assert.Equal(t, "id,title,topics,created_at\n"+
+		"1,\"Health, \"\"diet\"\" & <food>\",Health|Food,2024-10-28T09:00:00Z\n"+
+		"2,Empty,,\n", string(got))
+}
+
+func TestNDJSON(t *testing.T) {
+	got := writeTable(t, export.FormatNDJSON)
+	assert.Equal(t, `{"id":1,"title":"Health, \"diet\" \u0026 \u003cfood\u003e","topics":["Health","Food"],"created_at":"2024-10-28T09:00:00Z"}
+{"id":2,"title":"Empty","topics":[],"created_at":null}
+`, string(got))
+}
+
+func TestXLSX(t *testing.T) {
+	got := writeTable(t, export.FormatXLSX)
+
+	archive, err := zip.NewReader(bytes.NewReader(got), int64(len(got)))
+	require.NoError(t, err)
+
+	var sheet []byte
+	names := make([]string, 0, len(archive.File))
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, err := f.Open()
+			require.NoError(t, err)
+			sheet, err = io.ReadAll(r)
+			require.NoError(t, err)
+		}
+	}
+	assert.Contains(t, names, "[Content_Types].xml")
+	assert.Contains(t, names, "xl/workbook.xml")
+
+	var doc struct {
+		Rows []struct {
+			Cells []struct {
+				Type   string `xml:"t,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	require.NoError(t, xml.Unmarshal(sheet, &doc))
+	require.Len(t, doc.Rows, 3)
+	assert.Equal(t, "created_at", doc.Rows[0].Cells[3].Inline)
+	assert.Equal(t, "1", doc.Rows[1].Cells[0].Value)
+	assert.Equal(t, "", doc.Rows[1].Cells[0].Type)
+	assert.Equal(t, "Health, \"diet\" & <food>", doc.Rows[1].Cells[1].Inline)
+	assert.Equal(t, "Health|Food", doc.Rows[1].Cells[2].Inline)
+}
+
+func TestEmptyTable(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(&buf, export.FormatCSV, columns)
+	require.NoError(t, err)
+	assert.Zero(t, buf.Len())
+	require.NoError(t, w.Close())
+	assert.Equal(t, strings.Join(columns, ",")+"\n", buf.String())
+
+	_, err = export.NewWriter(&buf, "pdf", columns)
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+}
@@ -0,0 +1,63 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type ndjsonWriter struct {
+	writer  *bufio.Writer
+	columns [][]byte // JSON encoded column names
+}
+
+func newNDJSONWriter(w io.Writer, columns []string) *ndjsonWriter {
+	keys := make([][]byte, len(columns))
+	for i, column := range columns {
+		keys[i], _ = json.Marshal(column)
+	}
+	return &ndjsonWriter{writer: bufio.NewWriter(w), columns: keys}
+}
+
+// Write writes the row as one JSON object keeping the order of the columns
+func (n *ndjsonWriter) Write(row []interface{}) error {
+	_ = n.writer.WriteByte('{')
+	for i, v := range row {
+		if i >= len(n.columns) {
+			break
+		}
+		if i > 0 {
+			_ = n.writer.WriteByte(',')
+		}
+		switch cell := v.(type) {
+		case []string:
+			if cell == nil {
+				v = []string{}
+			}
+		case []int64:
+			if cell == nil {
+				v = []int64{}
+			}
+		case time.Time:
+			if cell.IsZero() {
+				v = nil
+			} else {
+				v = cell.UTC()
+			}
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, _ = n.writer.Write(n.columns[i])
+		_ = n.writer.WriteByte(':')
+		_, _ = n.writer.Write(value)
+	}
+	_, err := n.writer.WriteString("}\n")
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.writer.Flush()
+}
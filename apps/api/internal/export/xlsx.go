@@ -0,0 +1,135 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// xlsxMaxRows and xlsxMaxCellSize are the limits of a worksheet
+	xlsxMaxRows     = 1048576
+	xlsxMaxCellSize = 32767
+)
+
+// xlsxParts are the workbook parts written before the single worksheet
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// xlsxWriter streams a workbook with a single worksheet. The zip entries are
+// written one after the other so the worksheet, written last, grows row by
+// row without being buffered.
+type xlsxWriter struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	columns []string
+	rows    int
+}
+
+func newXLSXWriter(w io.Writer, columns []string) *xlsxWriter {
+	return &xlsxWriter{zip: zip.NewWriter(w), columns: columns}
+}
+
+func (x *xlsxWriter) start() error {
+	if x.sheet != nil {
+		return nil
+	}
+
+	for _, part := range xlsxParts {
+		f, err := x.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(f, part.body); err != nil {
+			return err
+		}
+	}
+
+	f, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(f)
+	_, _ = x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	header := make([]interface{}, len(x.columns))
+	for i, column := range x.columns {
+		header[i] = column
+	}
+	return x.writeRow(header)
+}
+
+func (x *xlsxWriter) Write(row []interface{}) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	return x.writeRow(row)
+}
+
+func (x *xlsxWriter) writeRow(row []interface{}) error {
+	if x.rows == xlsxMaxRows {
+		return ErrTooManyRows
+	}
+	x.rows++
+
+	_, _ = x.sheet.WriteString(`<row r="` + strconv.Itoa(x.rows) + `">`)
+	for _, v := range row {
+		switch v := v.(type) {
+		case int64:
+			_, _ = x.sheet.WriteString(`<c><v>` + strconv.FormatInt(v, 10) + `</v></c>`)
+		case int:
+			_, _ = x.sheet.WriteString(`<c><v>` + strconv.Itoa(v) + `</v></c>`)
+		case float64:
+			_, _ = x.sheet.WriteString(`<c><v>` + strconv.FormatFloat(v, 'f', -1, 64) + `</v></c>`)
+		case time.Time:
+			// kept as ISO 8601 text, the workbook has no styles to format dates
+			x.writeString(formatCell(v))
+		default:
+			x.writeString(formatCell(v))
+		}
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+func (x *xlsxWriter) writeString(s string) {
+	if len(s) > xlsxMaxCellSize {
+		s = s[:xlsxMaxCellSize]
+		for !utf8.ValidString(s) {
+			s = s[:len(s)-1]
+		}
+	}
+	_, _ = x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+	_ = xml.EscapeText(x.sheet, []byte(s))
+	_, _ = x.sheet.WriteString(`</t></is></c>`)
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	_, _ = x.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
@@ -0,0 +1,222 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+const (
+	// ContentTypeRSS is the media type of RSS 2.0 documents
+	ContentTypeRSS = "application/rss+xml; charset=utf-8"
+	// ContentTypeAtom is the media type of Atom documents
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+
+	atomNS = "http://www.w3.org/2005/Atom"
+	dcNS   = "http://purl.org/dc/elements/1.1/"
+)
+
+// Feed describes a channel of news articles
+type Feed struct {
+	Title       string
+	Description string
+	// Link is the HTML page the feed is about
+	Link string
+	// SelfLink is the URL of the feed document itself
+	SelfLink string
+	Items    []domain.News
+	// ItemLink returns the permanent URL of a news article
+	ItemLink func(n domain.News) string
+}
+
+// Updated returns the latest update time of the feed items
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, item := range f.Items {
+		if item.UpdatedAt.After(updated) {
+			updated = item.UpdatedAt
+		}
+		if item.CreatedAt.After(updated) {
+			updated = item.CreatedAt
+		}
+	}
+	return updated.UTC()
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS renders the feed as an RSS 2.0 document
+func RSS(f Feed) ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  atomNS,
+		DCNS:    dcNS,
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Description,
+			AtomLink: rssLink{
+				Href: f.SelfLink,
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+			Items: make([]rssItem, 0, len(f.Items)),
+		},
+	}
+	if updated := f.Updated(); !updated.IsZero() {
+		doc.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+	}
+
+	for _, n := range f.Items {
+		link := f.ItemLink(n)
+		item := rssItem{
+			Title:       n.Title,
+			Link:        link,
+			Description: n.Content,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			PubDate:     n.CreatedAt.UTC().Format(time.RFC1123Z),
+			Creator:     n.Author.Name,
+		}
+		for _, t := range n.Topics {
+			item.Categories = append(item.Categories, t.Name)
+		}
+		doc.Channel.Items = append(doc.Channel.Items, item)
+	}
+
+	return marshal(doc)
+}
+
+type atomDocument struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   atomAuthor  `xml:"author"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Author     *atomAuthor    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+	Content    atomContent    `xml:"content"`
+}
+
+// Atom renders the feed as an Atom (RFC 4287) document
+func Atom(f Feed) ([]byte, error) {
+	updated := f.Updated()
+	if updated.IsZero() {
+		updated = time.Unix(0, 0).UTC()
+	}
+
+	doc := atomDocument{
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       f.SelfLink,
+		Updated:  updated.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: f.SelfLink, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate"},
+		},
+		// Feed level author, used by entries without one
+		Author:  atomAuthor{Name: f.Title},
+		Entries: make([]atomEntry, 0, len(f.Items)),
+	}
+
+	for _, n := range f.Items {
+		link := f.ItemLink(n)
+		entry := atomEntry{
+			Title:     n.Title,
+			ID:        link,
+			Link:      atomLink{Href: link, Rel: "alternate"},
+			Published: n.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:   n.UpdatedAt.UTC().Format(time.RFC3339),
+			Content:   atomContent{Type: "html", Value: n.Content},
+		}
+		if n.Author.Name != "" {
+			entry.Author = &atomAuthor{Name: n.Author.Name}
+		}
+		for _, t := range n.Topics {
+			entry.Categories = append(entry.Categories, atomCategory{Term: t.Name})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return marshal(doc)
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
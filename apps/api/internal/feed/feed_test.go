@@ -0,0 +1,139 @@
+package feed_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/feed"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func newFeed() feed.Feed {
+	createdAt := time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)
+	return feed.Feed{
+		Title:       "News and Topic Management",
+		Description: "Latest published news",
+		Link:        "https://news.example.com",
+		SelfLink:    "https://news.example.com/feeds/news.rss",
+		Items: []domain.News{
+			{
+				ID:        2,
+				Title:     "AI & the Future of Work",
+				Content:   "<p>Artificial Intelligence is transforming the workplace...</p>",
+				Author:    domain.AuthorNews{ID: 2, Name: "Deni"},
+				Status:    domain.Published,
+				CreatedAt: createdAt.Add(time.Hour),
+				UpdatedAt: createdAt.Add(2 * time.Hour),
+				Topics:    []domain.TopicNews{{ID: 2, Name: "Technology"}},
+			},
+			{
+				ID:        1,
+				Title:     "Health Benefits of the Mediterranean Diet",
+				Content:   "The Mediterranean diet has been associated with various health benefits...",
+				Status:    domain.Published,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+				Topics:    []domain.TopicNews{{ID: 1, Name: "Health"}, {ID: 4, Name: "Environment"}},
+			},
+		},
+		ItemLink: func(n domain.News) string {
+			return fmt.Sprintf("https://news.example.com/news/%d", n.ID)
+		},
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.WriteFile(golden, got, 0o600))
+	}
+
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+// assertWellFormed reads every token of the document and returns its root element
+func assertWellFormed(t *testing.T, doc []byte) xml.StartElement {
+	t.Helper()
+
+	var root xml.StartElement
+	decoder := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if start, ok := token.(xml.StartElement); ok && root.Name.Local == "" {
+			root = start
+		}
+	}
+	return root
+}
+
+func TestRSS(t *testing.T) {
+	got, err := feed.RSS(newFeed())
+	require.NoError(t, err)
+
+	root := assertWellFormed(t, got)
+	assert.Equal(t, "rss", root.Name.Local)
+
+	var doc struct {
+		Items []struct {
+			GUID       string   `xml:"guid"`
+			PubDate    string   `xml:"pubDate"`
+			Categories []string `xml:"category"`
+		} `xml:"channel>item"`
+	}
+	require.NoError(t, xml.Unmarshal(got, &doc))
+	require.Len(t, doc.Items, 2)
+	assert.Equal(t, "https://news.example.com/news/2", doc.Items[0].GUID)
+	_, err = time.Parse(time.RFC1123Z, doc.Items[0].PubDate)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Health", "Environment"}, doc.Items[1].Categories)
+
+	assertGolden(t, "news.rss", got)
+}
+
+func TestAtom(t *testing.T) {
+	f := newFeed()
+	f.SelfLink = "https://news.example.com/feeds/news.atom"
+	got, err := feed.Atom(f)
+	require.NoError(t, err)
+
+	root := assertWellFormed(t, got)
+	assert.Equal(t, "http://www.w3.org/2005/Atom", root.Name.Space)
+	assert.Equal(t, "feed", root.Name.Local)
+
+	assertGolden(t, "news.atom", got)
+}
+
+func TestEmptyFeed(t *testing.T) {
+	f := newFeed()
+	f.Items = nil
+
+	got, err := feed.RSS(f)
+	require.NoError(t, err)
+	assertWellFormed(t, got)
+	assert.NotContains(t, string(got), "lastBuildDate")
+
+	got, err = feed.Atom(f)
+	require.NoError(t, err)
+	assertWellFormed(t, got)
+	assert.Contains(t, string(got), "<updated>1970-01-01T00:00:00Z</updated>")
+}
@@ -0,0 +1,219 @@
+package gql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// queryCost is the depth and complexity of a selection set
+type queryCost struct {
+	depth      int
+	complexity int
+}
+
+func (c *queryCost) add(other queryCost) {
+	if other.depth > c.depth {
+		c.depth = other.depth
+	}
+	c.complexity += other.complexity
+}
+
+// costWalker computes the cost of an operation. Every field costs 1, the
+// fields below a list taking a limit, or a list of ids, are counted once per
+// requested item. Introspection fields are free.
+type costWalker struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// visiting guards against fragment cycles, rejected by the validation
+	// but walked before it reports them
+	visiting map[string]bool
+}
+
+// operationCost returns the cost of the operation named operationName, or of
+// the only operation of the document
+func operationCost(schema *graphql.Schema, doc *ast.Document, operationName string, variables map[string]interface{}) (queryCost, error) {
+	w := &costWalker{
+		schema:    schema,
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+		visiting:  map[string]bool{},
+	}
+	op, err := selectOperation(doc, operationName)
+	if err != nil {
+		return queryCost{}, err
+	}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			w.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	root := schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+	return w.selectionSet(root, op.SelectionSet, 1), nil
+}
+
+// selectOperation picks the operation to run the same way the executor does
+func selectOperation(doc *ast.Document, operationName string) (*ast.OperationDefinition, error) {
+	var res *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" {
+			if res != nil {
+				return nil, errors.New("must provide operation name if query contains multiple operations")
+			}
+			res = op
+		} else if op.Name != nil && op.Name.Value == operationName {
+			res = op
+		}
+	}
+	if res == nil {
+		if operationName != "" {
+			return nil, fmt.Errorf("unknown operation named %q", operationName)
+		}
+		return nil, errors.New("must provide an operation")
+	}
+	return res, nil
+}
+
+func (w *costWalker) selectionSet(parent *graphql.Object, set *ast.SelectionSet, depth int) (cost queryCost) {
+	if parent == nil || set == nil {
+		return
+	}
+	for _, selection := range set.Selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			cost.add(w.field(parent, s, depth))
+		case *ast.InlineFragment:
+			cost.add(w.selectionSet(w.fragmentType(parent, s.TypeCondition), s.SelectionSet, depth))
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || w.visiting[name] {
+				continue
+			}
+			w.visiting[name] = true
+			cost.add(w.selectionSet(w.fragmentType(parent, fragment.TypeCondition), fragment.SelectionSet, depth))
+			delete(w.visiting, name)
+		}
+	}
+	return
+}
+
+func (w *costWalker) field(parent *graphql.Object, field *ast.Field, depth int) queryCost {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return queryCost{}
+	}
+	def, ok := parent.Fields()[field.Name.Value]
+	if !ok {
+		return queryCost{}
+	}
+
+	cost := queryCost{depth: depth, complexity: 1}
+	if field.SelectionSet != nil {
+		children := w.selectionSet(objectType(def.Type), field.SelectionSet, depth+1)
+		if children.depth > cost.depth {
+			cost.depth = children.depth
+		}
+		cost.complexity += w.multiplier(def, field) * children.complexity
+	}
+	return cost
+}
+
+// multiplier is the number of items a field may return: its limit argument,
+// or default limit, or the number of requested ids
+func (w *costWalker) multiplier(def *graphql.FieldDefinition, field *ast.Field) int {
+	n := 1
+	for _, arg := range def.Args {
+		if arg.PrivateName == "limit" {
+			if value, ok := arg.DefaultValue.(int); ok {
+				n = value
+			}
+		}
+	}
+	for _, arg := range field.Arguments {
+		switch arg.Name.Value {
+		case "limit":
+			if value, ok := w.intValue(arg.Value); ok {
+				n = value
+			}
+		case "ids":
+			if list, ok := w.value(arg.Value).([]interface{}); ok {
+				n = len(list)
+			}
+		}
+	}
+
+	switch {
+	case n < 1:
+		return 1
+	case n > maxPageSize:
+		return maxPageSize
+	}
+	return n
+}
+
+// value returns the value of a literal or a variable
+func (w *costWalker) value(v ast.Value) interface{} {
+	if variable, ok := v.(*ast.Variable); ok {
+		return w.variables[variable.Name.Value]
+	}
+	if list, ok := v.(*ast.ListValue); ok {
+		res := make([]interface{}, 0, len(list.Values))
+		for _, item := range list.Values {
+			res = append(res, w.value(item))
+		}
+		return res
+	}
+	return v.GetValue()
+}
+
+func (w *costWalker) intValue(v ast.Value) (int, bool) {
+	switch value := w.value(v).(type) {
+	case string:
+		n, err := strconv.Atoi(value)
+		return n, err == nil
+	case int:
+		return value, true
+	case float64:
+		return int(value), true
+	}
+	return 0, false
+}
+
+func (w *costWalker) fragmentType(parent *graphql.Object, condition *ast.Named) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	if t, ok := w.schema.Type(condition.Name.Value).(*graphql.Object); ok {
+		return t
+	}
+	return nil
+}
+
+// objectType unwraps the list and non null types around an object
+func objectType(t graphql.Type) *graphql.Object {
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			t = wrapped.OfType
+		case *graphql.Object:
+			return wrapped
+		default:
+			return nil
+		}
+	}
+}
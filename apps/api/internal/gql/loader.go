@@ -0,0 +1,147 @@
+package gql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// Loader batches the keys requested while one level of a query is resolved.
+// Load only queues the key and returns a thunk; graphql-go calls the thunks
+// of a level once all of them are queued, so the first call fetches every
+// queued key with a single batch call. Results are cached for the request.
+type Loader[K comparable, V any] struct {
+	batch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]*loaderResult[V]
+}
+
+type loaderResult[V any] struct {
+	value V
+	found bool
+	err   error
+}
+
+// NewLoader will create a Loader fetching keys with batch, keys missing from
+// its result are reported as not found
+func NewLoader[K comparable, V any](batch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{batch: batch, results: map[K]*loaderResult[V]{}}
+}
+
+// Load queues key and returns a thunk returning its value once fetched
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, bool, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loaderResult[V]{}
+		l.results[key] = result
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.dispatch(ctx)
+		return result.value, result.found, result.err
+	}
+}
+
+// dispatch fetches the queued keys
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := l.pending
+	if len(keys) == 0 {
+		return
+	}
+	l.pending = nil
+
+	values, err := l.batch(ctx, keys)
+	for _, key := range keys {
+		result := l.results[key]
+		if err != nil {
+			result.err = err
+			continue
+		}
+		result.value, result.found = values[key]
+	}
+}
+
+// loaders are the loaders of one request
+type loaders struct {
+	authors    *Loader[int64, domain.Author]
+	topics     *Loader[int64, domain.Topic]
+	newsCounts *Loader[int64, int64]
+
+	mu sync.Mutex
+	// latest holds one loader of the latest news of authors per limit
+	latest  map[int64]*Loader[int64, []domain.News]
+	newsSvc NewsService
+}
+
+func newLoaders(ns NewsService, ts TopicService) *loaders {
+	return &loaders{
+		authors: NewLoader(func(ctx context.Context, ids []int64) (map[int64]domain.Author, error) {
+			list, err := ns.AuthorsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64]domain.Author, len(list))
+			for _, a := range list {
+				res[a.ID] = a
+			}
+			return res, nil
+		}),
+		topics: NewLoader(func(ctx context.Context, ids []int64) (map[int64]domain.Topic, error) {
+			list, err := ts.GetByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64]domain.Topic, len(list))
+			for _, t := range list {
+				res[t.ID] = t
+			}
+			return res, nil
+		}),
+		newsCounts: NewLoader(ts.CountNews),
+		latest:     map[int64]*Loader[int64, []domain.News]{},
+		newsSvc:    ns,
+	}
+}
+
+// latestNews returns the loader of the latest news of authors, up to limit
+// per author
+func (l *loaders) latestNews(limit int64) *Loader[int64, []domain.News] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loader, ok := l.latest[limit]
+	if !ok {
+		loader = NewLoader(func(ctx context.Context, ids []int64) (map[int64][]domain.News, error) {
+			list, err := l.newsSvc.LatestByAuthors(ctx, ids, limit)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64][]domain.News, len(ids))
+			for _, n := range list {
+				res[n.Author.ID] = append(res[n.Author.ID], n)
+			}
+			return res, nil
+		})
+		l.latest[limit] = loader
+	}
+	return loader
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
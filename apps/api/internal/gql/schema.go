@@ -0,0 +1,690 @@
+package gql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+)
+
+// NewsService represents the news use cases exposed through GraphQL
+type NewsService interface {
+	Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) error
+	Count(ctx context.Context, filter domain.NewsFilter) (int64, error)
+	LatestByAuthors(ctx context.Context, authorIDs []int64, limit int64) ([]domain.News, error)
+	AuthorsByIDs(ctx context.Context, ids []int64) ([]domain.Author, error)
+	Store(context.Context, *news.CreateNewsReq) error
+	Update(ctx context.Context, ar *news.UpdateNewsReq) error
+	Delete(ctx context.Context, id int64, version int64) error
+}
+
+// TopicService represents the topic use cases exposed through GraphQL
+type TopicService interface {
+	Fetch(ctx context.Context, filter domain.TopicFilter) ([]domain.Topic, int64, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]domain.Topic, error)
+	CountNews(ctx context.Context, ids []int64) (map[int64]int64, error)
+	Store(context.Context, *domain.Topic) error
+	Update(ctx context.Context, ar *domain.Topic) error
+	Delete(ctx context.Context, id int64, version int64) error
+}
+
+const (
+	defaultPageSize = 10
+	// maxPageSize bounds the limit argument of every list
+	maxPageSize = 100
+	// defaultAuthorNews is the number of news listed per author by default
+	defaultAuthorNews = 5
+)
+
+// newsPage is the source of the NewsPage type, the total is only counted
+// when requested
+type newsPage struct {
+	filter domain.NewsFilter
+	items  []domain.News
+}
+
+type topicPage struct {
+	filter domain.TopicFilter
+	items  []domain.Topic
+	total  int64
+}
+
+type resolver struct {
+	news   NewsService
+	topics TopicService
+}
+
+// NewSchema will create the GraphQL schema of news, topics and authors
+func NewSchema(ns NewsService, ts TopicService) (graphql.Schema, error) {
+	r := &resolver{news: ns, topics: ts}
+
+	newsStatus := graphql.NewEnum(graphql.EnumConfig{
+		Name: "NewsStatus",
+		Values: graphql.EnumValueConfigMap{
+			string(domain.Draft):     &graphql.EnumValueConfig{Value: domain.Draft},
+			string(domain.Published): &graphql.EnumValueConfig{Value: domain.Published},
+			string(domain.Deleted):   &graphql.EnumValueConfig{Value: domain.Deleted},
+		},
+	})
+
+	var newsType, authorType, topicType *graphql.Object
+
+	topicType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Topic",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: topicField(func(t domain.Topic) interface{} { return t.ID })},
+				"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: topicField(func(t domain.Topic) interface{} { return t.Name })},
+				"createdAt": &graphql.Field{Type: graphql.DateTime, Resolve: topicField(func(t domain.Topic) interface{} { return t.CreatedAt })},
+				"updatedAt": &graphql.Field{Type: graphql.DateTime, Resolve: topicField(func(t domain.Topic) interface{} { return t.UpdatedAt })},
+				"newsCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: r.topicNewsCount},
+			}
+		}),
+	})
+
+	authorType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Author",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: authorField(func(a domain.Author) interface{} { return a.ID })},
+				"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: authorField(func(a domain.Author) interface{} { return a.Name })},
+				"createdAt": &graphql.Field{Type: graphql.String, Resolve: authorField(func(a domain.Author) interface{} { return a.CreatedAt })},
+				"updatedAt": &graphql.Field{Type: graphql.String, Resolve: authorField(func(a domain.Author) interface{} { return a.UpdatedAt })},
+				"news": &graphql.Field{
+					Description: "Latest news of the author",
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(newsType))),
+					Args: graphql.FieldConfigArgument{
+						"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultAuthorNews},
+					},
+					Resolve: r.authorNews,
+				},
+			}
+		}),
+	})
+
+	newsType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "News",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: newsField(func(n domain.News) interface{} { return n.ID })},
+			"title":     &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: newsField(func(n domain.News) interface{} { return n.Title })},
+			"content":   &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: newsField(func(n domain.News) interface{} { return n.Content })},
+			"status":    &graphql.Field{Type: graphql.NewNonNull(newsStatus), Resolve: newsField(func(n domain.News) interface{} { return n.Status })},
+			"createdAt": &graphql.Field{Type: graphql.DateTime, Resolve: newsField(func(n domain.News) interface{} { return n.CreatedAt })},
+			"updatedAt": &graphql.Field{Type: graphql.DateTime, Resolve: newsField(func(n domain.News) interface{} { return n.UpdatedAt })},
+			"author":    &graphql.Field{Type: authorType, Resolve: r.newsAuthor},
+			"topics":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(topicType))), Resolve: r.newsTopics},
+		},
+	})
+
+	newsPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "NewsPage",
+		Fields: graphql.Fields{
+			"items": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(newsType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(newsPage).items, nil
+				},
+			},
+			"page": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(newsPage).filter.Page, nil
+				},
+			},
+			"total":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: r.newsTotal},
+			"totalPages": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: r.newsTotalPages},
+		},
+	})
+
+	topicPageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TopicPage",
+		Fields: graphql.Fields{
+			"items": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(topicType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(topicPage).items, nil
+				},
+			},
+			"page": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(topicPage).filter.Page, nil
+				},
+			},
+			"total": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(topicPage).total, nil
+				},
+			},
+			"totalPages": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					page := p.Source.(topicPage)
+					return totalPages(page.total, page.filter.Limit), nil
+				},
+			},
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"news": &graphql.Field{
+				Type:    newsType,
+				Args:    idArgs,
+				Resolve: r.newsByID,
+			},
+			"newsList": &graphql.Field{
+				Description: "News matching the same filters as GET /news",
+				Type:        graphql.NewNonNull(newsPageType),
+				Args: graphql.FieldConfigArgument{
+					"title":     &graphql.ArgumentConfig{Type: graphql.String},
+					"status":    &graphql.ArgumentConfig{Type: newsStatus},
+					"authorId":  &graphql.ArgumentConfig{Type: graphql.ID},
+					"topicId":   &graphql.ArgumentConfig{Type: graphql.ID},
+					"startDate": &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"endDate":   &graphql.ArgumentConfig{Type: graphql.DateTime},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"page":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"sortBy":    &graphql.ArgumentConfig{Type: graphql.String},
+					"sortOrder": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.newsList,
+			},
+			"topic": &graphql.Field{
+				Type:    topicType,
+				Args:    idArgs,
+				Resolve: r.topicByID,
+			},
+			"topics": &graphql.Field{
+				Description: "Topics matching the same filters as GET /topic",
+				Type:        graphql.NewNonNull(topicPageType),
+				Args: graphql.FieldConfigArgument{
+					"name":      &graphql.ArgumentConfig{Type: graphql.String},
+					"limit":     &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"page":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 1},
+					"sortBy":    &graphql.ArgumentConfig{Type: graphql.String},
+					"sortOrder": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: r.topicList,
+			},
+			"author": &graphql.Field{
+				Type:    authorType,
+				Args:    idArgs,
+				Resolve: r.authorByID,
+			},
+			"authors": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(authorType))),
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: r.authorsByIDs,
+			},
+		},
+	})
+
+	createNewsInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateNewsInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"content":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"authorId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+			"status":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(newsStatus)},
+			"topicIds": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+		},
+	})
+	updateNewsInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UpdateNewsInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"content":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"authorId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"status":   &graphql.InputObjectFieldConfig{Type: newsStatus},
+			"topicIds": &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createNews": &graphql.Field{
+				Type: newsType,
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createNewsInput)},
+				},
+				Resolve: r.createNews,
+			},
+			"updateNews": &graphql.Field{
+				Type: newsType,
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateNewsInput)},
+				},
+				Resolve: r.updateNews,
+			},
+			"deleteNews": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    idArgs,
+				Resolve: r.deleteNews,
+			},
+			"createTopic": &graphql.Field{
+				Type: topicType,
+				Args: graphql.FieldConfigArgument{
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.createTopic,
+			},
+			"updateTopic": &graphql.Field{
+				Type: topicType,
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"name": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: r.updateTopic,
+			},
+			"deleteTopic": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    idArgs,
+				Resolve: r.deleteTopic,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func newsField(fn func(domain.News) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(domain.News)), nil
+	}
+}
+
+func topicField(fn func(domain.Topic) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(domain.Topic)), nil
+	}
+}
+
+func authorField(fn func(domain.Author) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return fn(p.Source.(domain.Author)), nil
+	}
+}
+
+func (r *resolver) newsByID(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	return r.loadNews(p.Context, id)
+}
+
+// loadNews returns the news with its author name and topics, nil when it
+// does not exist
+func (r *resolver) loadNews(ctx context.Context, id int64) (interface{}, error) {
+	var res interface{}
+	err := r.news.Stream(ctx, domain.NewsFilter{ID: id, Limit: 1}, func(n domain.News) error {
+		res = n
+		return nil
+	})
+	return res, err
+}
+
+func (r *resolver) newsList(p graphql.ResolveParams) (interface{}, error) {
+	filter := domain.NewsFilter{
+		Limit: pageSize(p.Args),
+		Page:  int64(intArg(p.Args, "page", 1)),
+	}
+	filter.Title, _ = p.Args["title"].(string)
+	filter.SortBy, _ = p.Args["sortBy"].(string)
+	filter.SortOrder, _ = p.Args["sortOrder"].(string)
+	if status, ok := p.Args["status"].(domain.NewsStatus); ok {
+		filter.Status = string(status)
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	var err error
+	if filter.AuthorID, err = optionalIDArg(p.Args, "authorId"); err != nil {
+		return nil, err
+	}
+	if filter.TopicID, err = optionalIDArg(p.Args, "topicId"); err != nil {
+		return nil, err
+	}
+	if t, ok := p.Args["startDate"].(time.Time); ok {
+		filter.StartDate = t
+	}
+	if t, ok := p.Args["endDate"].(time.Time); ok {
+		filter.EndDate = t
+	}
+
+	page := newsPage{filter: filter, items: []domain.News{}}
+	err = r.news.Stream(p.Context, filter, func(n domain.News) error {
+		page.items = append(page.items, n)
+		return nil
+	})
+	return page, err
+}
+
+func (r *resolver) newsTotal(p graphql.ResolveParams) (interface{}, error) {
+	return r.news.Count(p.Context, p.Source.(newsPage).filter)
+}
+
+func (r *resolver) newsTotalPages(p graphql.ResolveParams) (interface{}, error) {
+	page := p.Source.(newsPage)
+	total, err := r.news.Count(p.Context, page.filter)
+	if err != nil {
+		return nil, err
+	}
+	return totalPages(total, page.filter.Limit), nil
+}
+
+func (r *resolver) newsAuthor(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loadersFrom(p.Context).authors.Load(p.Context, p.Source.(domain.News).Author.ID)
+	return func() (interface{}, error) {
+		author, found, err := thunk()
+		if err != nil || !found {
+			return nil, err
+		}
+		return author, nil
+	}, nil
+}
+
+func (r *resolver) newsTopics(p graphql.ResolveParams) (interface{}, error) {
+	l := loadersFrom(p.Context).topics
+	var thunks []func() (domain.Topic, bool, error)
+	for _, t := range p.Source.(domain.News).Topics {
+		thunks = append(thunks, l.Load(p.Context, t.ID))
+	}
+	return func() (interface{}, error) {
+		res := make([]domain.Topic, 0, len(thunks))
+		for _, thunk := range thunks {
+			t, found, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				res = append(res, t)
+			}
+		}
+		return res, nil
+	}, nil
+}
+
+func (r *resolver) topicByID(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	thunk := loadersFrom(p.Context).topics.Load(p.Context, id)
+	return func() (interface{}, error) {
+		t, found, err := thunk()
+		if err != nil || !found {
+			return nil, err
+		}
+		return t, nil
+	}, nil
+}
+
+func (r *resolver) topicList(p graphql.ResolveParams) (interface{}, error) {
+	filter := domain.TopicFilter{
+		Limit: pageSize(p.Args),
+		Page:  int64(intArg(p.Args, "page", 1)),
+	}
+	filter.Name, _ = p.Args["name"].(string)
+	filter.SortBy, _ = p.Args["sortBy"].(string)
+	filter.SortOrder, _ = p.Args["sortOrder"].(string)
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	items, total, err := r.topics.Fetch(p.Context, filter)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []domain.Topic{}
+	}
+	return topicPage{filter: filter, items: items, total: total}, nil
+}
+
+func (r *resolver) topicNewsCount(p graphql.ResolveParams) (interface{}, error) {
+	thunk := loadersFrom(p.Context).newsCounts.Load(p.Context, p.Source.(domain.Topic).ID)
+	return func() (interface{}, error) {
+		count, _, err := thunk()
+		return count, err
+	}, nil
+}
+
+func (r *resolver) authorByID(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	thunk := loadersFrom(p.Context).authors.Load(p.Context, id)
+	return func() (interface{}, error) {
+		author, found, err := thunk()
+		if err != nil || !found {
+			return nil, err
+		}
+		return author, nil
+	}, nil
+}
+
+func (r *resolver) authorsByIDs(p graphql.ResolveParams) (interface{}, error) {
+	l := loadersFrom(p.Context).authors
+	var thunks []func() (domain.Author, bool, error)
+	for _, value := range p.Args["ids"].([]interface{}) {
+		id, err := parseID(value)
+		if err != nil {
+			return nil, err
+		}
+		thunks = append(thunks, l.Load(p.Context, id))
+	}
+	return func() (interface{}, error) {
+		res := make([]domain.Author, 0, len(thunks))
+		for _, thunk := range thunks {
+			author, found, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			if found {
+				res = append(res, author)
+			}
+		}
+		return res, nil
+	}, nil
+}
+
+func (r *resolver) authorNews(p graphql.ResolveParams) (interface{}, error) {
+	limit := int64(intArg(p.Args, "limit", defaultAuthorNews))
+	if limit < 1 {
+		return []domain.News{}, nil
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	thunk := loadersFrom(p.Context).latestNews(limit).Load(p.Context, p.Source.(domain.Author).ID)
+	return func() (interface{}, error) {
+		list, _, err := thunk()
+		if list == nil {
+			list = []domain.News{}
+		}
+		return list, err
+	}, nil
+}
+
+func (r *resolver) createNews(p graphql.ResolveParams) (interface{}, error) {
+	input := p.Args["input"].(map[string]interface{})
+	req := &news.CreateNewsReq{
+		Title:    input["title"].(string),
+		Content:  input["content"].(string),
+		Status:   input["status"].(domain.NewsStatus),
+		TopicIDs: []int64{},
+	}
+	var err error
+	if req.AuthorID, err = idArg(input, "authorId"); err != nil {
+		return nil, err
+	}
+	if req.TopicIDs, err = idListArg(input, "topicIds"); err != nil {
+		return nil, err
+	}
+
+	if err = r.news.Store(p.Context, req); err != nil {
+		return nil, err
+	}
+	return r.loadNews(p.Context, req.ID)
+}
+
+func (r *resolver) updateNews(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	input := p.Args["input"].(map[string]interface{})
+	req := &news.UpdateNewsReq{ID: &id}
+	if title, ok := input["title"].(string); ok {
+		req.Title = &title
+	}
+	if content, ok := input["content"].(string); ok {
+		req.Content = &content
+	}
+	if status, ok := input["status"].(domain.NewsStatus); ok {
+		req.Status = &status
+	}
+	if _, ok := input["authorId"]; ok {
+		authorID, err := idArg(input, "authorId")
+		if err != nil {
+			return nil, err
+		}
+		req.AuthorID = &authorID
+	}
+	if _, ok := input["topicIds"]; ok {
+		topicIDs, err := idListArg(input, "topicIds")
+		if err != nil {
+			return nil, err
+		}
+		req.TopicIDs = &topicIDs
+	}
+
+	if err = r.news.Update(p.Context, req); err != nil {
+		return nil, err
+	}
+	return r.loadNews(p.Context, id)
+}
+
+func (r *resolver) deleteNews(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err = r.news.Delete(p.Context, id, 0); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+func (r *resolver) createTopic(p graphql.ResolveParams) (interface{}, error) {
+	t := &domain.Topic{Name: p.Args["name"].(string)}
+	if err := r.topics.Store(p.Context, t); err != nil {
+		return nil, err
+	}
+	return r.loadTopic(p.Context, t.ID)
+}
+
+func (r *resolver) updateTopic(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err = r.topics.Update(p.Context, &domain.Topic{ID: id, Name: p.Args["name"].(string)}); err != nil {
+		return nil, err
+	}
+	return r.loadTopic(p.Context, id)
+}
+
+func (r *resolver) deleteTopic(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+	if err = r.topics.Delete(p.Context, id, 0); err != nil {
+		return nil, err
+	}
+	return true, nil
+}
+
+// loadTopic reads a topic after a mutation, bypassing the loader cache
+func (r *resolver) loadTopic(ctx context.Context, id int64) (interface{}, error) {
+	list, err := r.topics.GetByIDs(ctx, []int64{id})
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+func parseID(value interface{}) (int64, error) {
+	s := fmt.Sprint(value)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid ID %q", domain.ErrBadParamInput, s)
+	}
+	return id, nil
+}
+
+func idArg(args map[string]interface{}, name string) (int64, error) {
+	return parseID(args[name])
+}
+
+func optionalIDArg(args map[string]interface{}, name string) (int64, error) {
+	if args[name] == nil {
+		return 0, nil
+	}
+	return parseID(args[name])
+}
+
+func idListArg(args map[string]interface{}, name string) ([]int64, error) {
+	values, _ := args[name].([]interface{})
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		id, err := parseID(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func intArg(args map[string]interface{}, name string, fallback int) int {
+	if value, ok := args[name].(int); ok {
+		return value
+	}
+	return fallback
+}
+
+// pageSize reads the limit argument of a list, bounded by maxPageSize
+func pageSize(args map[string]interface{}) int64 {
+	limit := intArg(args, "limit", defaultPageSize)
+	switch {
+	case limit < 1:
+		return defaultPageSize
+	case limit > maxPageSize:
+		return maxPageSize
+	}
+	return int64(limit)
+}
+
+func totalPages(total, limit int64) int64 {
+	return (total + limit - 1) / limit
+}
@@ -0,0 +1,106 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+var (
+	// ErrQueryTooDeep is returned when a query nests more fields than allowed
+	ErrQueryTooDeep = errors.New("query is too deep")
+	// ErrQueryTooComplex is returned when a query may resolve more fields than allowed
+	ErrQueryTooComplex = errors.New("query is too complex")
+	// ErrMutationNotAllowed is returned for mutations sent in GET requests
+	ErrMutationNotAllowed = errors.New("mutations must be sent with POST")
+)
+
+// Config bounds the cost of the queries accepted by a Server
+type Config struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Server executes GraphQL requests against the schema of news, topics and
+// authors
+type Server struct {
+	schema graphql.Schema
+	news   NewsService
+	topics TopicService
+	config Config
+}
+
+// NewServer will create a Server executing requests with the given services
+func NewServer(ns NewsService, ts TopicService, config Config) (*Server, error) {
+	schema, err := NewSchema(ns, ts)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{schema: schema, news: ns, topics: ts, config: config}, nil
+}
+
+// Execute parses and validates req, rejects it when it exceeds the limits and
+// runs it otherwise. The returned error tells the request itself is invalid,
+// the result then only holds the errors. Mutations are rejected unless
+// allowMutations is set.
+func (s *Server) Execute(ctx context.Context, req Request, allowMutations bool) (*graphql.Result, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, err
+	}
+
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, errors.New(validation.Errors[0].Message)
+	}
+
+	if err = s.checkRequest(doc, req, allowMutations); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, err
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, newLoaders(s.news, s.topics)),
+	}), nil
+}
+
+func (s *Server) checkRequest(doc *ast.Document, req Request, allowMutations bool) error {
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return err
+	}
+	if op.Operation == ast.OperationTypeMutation && !allowMutations {
+		return ErrMutationNotAllowed
+	}
+
+	cost, err := operationCost(&s.schema, doc, req.OperationName, req.Variables)
+	if err != nil {
+		return err
+	}
+	if s.config.MaxDepth > 0 && cost.depth > s.config.MaxDepth {
+		return fmt.Errorf("%w: depth %d exceeds the limit of %d", ErrQueryTooDeep, cost.depth, s.config.MaxDepth)
+	}
+	if s.config.MaxComplexity > 0 && cost.complexity > s.config.MaxComplexity {
+		return fmt.Errorf("%w: complexity %d exceeds the limit of %d", ErrQueryTooComplex, cost.complexity, s.config.MaxComplexity)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package gql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/gql"
+)
+
+type stubNewsService struct {
+	list        []domain.News
+	authorCalls [][]int64
+	latestCalls int
+	stored      *news.CreateNewsReq
+}
+
+func (s *stubNewsService) Stream(_ context.Context, filter domain.NewsFilter, fn func(domain.News) error) error {
+	for _, n := range s.list {
+		if filter.ID != 0 && n.ID != filter.ID {
+			continue
+		}
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubNewsService) Count(context.Context, domain.NewsFilter) (int64, error) {
+	return int64(len(s.list)), nil
+}
+
+func (s *stubNewsService) LatestByAuthors(_ context.Context, ids []int64, limit int64) ([]domain.News, error) {
+	s.latestCalls++
+	var res []domain.News
+	for _, n := range s.list {
+		for _, id := range ids {
+			if n.Author.ID == id {
+				res = append(res, n)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (s *stubNewsService) AuthorsByIDs(_ context.Context, ids []int64) ([]domain.Author, error) {
+	s.authorCalls = append(s.authorCalls, ids)
+	var res []domain.Author
+	for _, id := range ids {
+		if id < 10 {
+			res = append(res, domain.Author{ID: id, Name: "Author"})
+		}
+	}
+	return res, nil
+}
+
+func (s *stubNewsService) Store(_ context.Context, req *news.CreateNewsReq) error {
+	req.ID = int64(len(s.list) + 1)
+	s.stored = req
+	s.list = append(s.list, domain.News{ID: req.ID, Title: req.Title, Content: req.Content, Status: req.Status,
+		Author: domain.AuthorNews{ID: req.AuthorID}})
+	return nil
+}
+
+func (s *stubNewsService) Update(context.Context, *news.UpdateNewsReq) error {
+	return nil
+}
+
+func (s *stubNewsService) Delete(context.Context, int64, int64) error {
+	return nil
+}
+
+type stubTopicService struct {
+	getCalls   [][]int64
+	countCalls int
+}
+
+func (s *stubTopicService) Fetch(context.Context, domain.TopicFilter) ([]domain.Topic, int64, error) {
+	return []domain.Topic{{ID: 1, Name: "Health"}}, 1, nil
+}
+
+func (s *stubTopicService) GetByIDs(_ context.Context, ids []int64) ([]domain.Topic, error) {
+	s.getCalls = append(s.getCalls, ids)
+	var res []domain.Topic
+	for _, id := range ids {
+		res = append(res, domain.Topic{ID: id, Name: "Topic"})
+	}
+	return res, nil
+}
+
+func (s *stubTopicService) CountNews(_ context.Context, ids []int64) (map[int64]int64, error) {
+	s.countCalls++
+	res := map[int64]int64{}
+	for _, id := range ids {
+		res[id] = id * 2
+	}
+	return res, nil
+}
+
+func (s *stubTopicService) Store(context.Context, *domain.Topic) error {
+	return nil
+}
+
+func (s *stubTopicService) Update(context.Context, *domain.Topic) error {
+	return nil
+}
+
+func (s *stubTopicService) Delete(context.Context, int64, int64) error {
+	return nil
+}
+
+func newServer(t *testing.T, config gql.Config) (*gql.Server, *stubNewsService, *stubTopicService) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	ns := &stubNewsService{list: []domain.News{
+		{ID: 1, Title: "First", Status: domain.Published, CreatedAt: created, Author: domain.AuthorNews{ID: 1},
+			Topics: []domain.TopicNews{{ID: 1}, {ID: 2}}},
+		{ID: 2, Title: "Second", Status: domain.Draft, CreatedAt: created, Author: domain.AuthorNews{ID: 2},
+			Topics: []domain.TopicNews{{ID: 2}, {ID: 3}}},
+		{ID: 3, Title: "Third", Status: domain.Draft, CreatedAt: created, Author: domain.AuthorNews{ID: 1}},
+	}}
+	ts := &stubTopicService{}
+	server, err := gql.NewServer(ns, ts, config)
+	require.NoError(t, err)
+	return server, ns, ts
+}
+
+func TestExecuteBatchesNestedFields(t *testing.T) {
+	server, ns, ts := newServer(t, gql.Config{})
+
+	result, err := server.Execute(context.Background(), gql.Request{Query: `{
+		newsList(limit: 10) {
+			total
+			items { id title status author { name news(limit: 2) { id } } topics { id newsCount } }
+		}
+	}`}, false)
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+
+	// one call per level instead of one per news
+	require.Len(t, ns.authorCalls, 1)
+	assert.ElementsMatch(t, []int64{1, 2}, ns.authorCalls[0])
+	require.Len(t, ts.getCalls, 1)
+	assert.ElementsMatch(t, []int64{1, 2, 3}, ts.getCalls[0])
+	assert.Equal(t, 1, ts.countCalls)
+	assert.Equal(t, 1, ns.latestCalls)
+
+	page := result.Data.(map[string]interface{})["newsList"].(map[string]interface{})
+	assert.Equal(t, 3, page["total"])
+	items := page["items"].([]interface{})
+	require.Len(t, items, 3)
+	first := items[0].(map[string]interface{})
+	assert.Equal(t, "published", first["status"])
+	assert.Len(t, first["topics"], 2)
+	assert.Len(t, first["author"].(map[string]interface{})["news"], 2)
+}
+
+func TestExecuteLimits(t *testing.T) {
+	server, _, _ := newServer(t, gql.Config{MaxDepth: 4, MaxComplexity: 100})
+
+	_, err := server.Execute(context.Background(), gql.Request{
+		Query: `{ newsList { items { author { news { author { name } } } } } }`,
+	}, false)
+	assert.ErrorIs(t, err, gql.ErrQueryTooDeep)
+
+	// authors + 1 author * (news + 50 news * 2 fields) = 102
+	_, err = server.Execute(context.Background(), gql.Request{
+		Query:     `query($limit: Int) { authors(ids: [1]) { news(limit: $limit) { ...fields } } } fragment fields on News { id title }`,
+		Variables: map[string]interface{}{"limit": float64(50)},
+	}, false)
+	assert.ErrorIs(t, err, gql.ErrQueryTooComplex)
+
+	_, err = server.Execute(context.Background(), gql.Request{Query: `{ __schema { types { name fields { name } } } }`}, false)
+	assert.NoError(t, err)
+}
+
+func TestExecuteMutations(t *testing.T) {
+	server, ns, _ := newServer(t, gql.Config{})
+	query := `mutation { createNews(input: {title: "New", content: "Body", authorId: 1, status: draft, topicIds: [1, 2]}) { id title author { id } } }`
+
+	_, err := server.Execute(context.Background(), gql.Request{Query: query}, false)
+	assert.ErrorIs(t, err, gql.ErrMutationNotAllowed)
+
+	result, err := server.Execute(context.Background(), gql.Request{Query: query}, true)
+	require.NoError(t, err)
+	require.Empty(t, result.Errors)
+	require.NotNil(t, ns.stored)
+	assert.Equal(t, []int64{1, 2}, ns.stored.TopicIDs)
+	assert.Equal(t, domain.Draft, ns.stored.Status)
+
+	created := result.Data.(map[string]interface{})["createNews"].(map[string]interface{})
+	assert.Equal(t, "4", created["id"])
+	assert.Equal(t, "1", created["author"].(map[string]interface{})["id"])
+}
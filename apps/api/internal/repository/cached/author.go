@@ -0,0 +1,67 @@
+package cached
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/bxcodec/go-clean-arch/news"
+)
+
+// AuthorRepository caches the reads of an author repository. The authors are
+// not written through the API, their entries only expire.
+type AuthorRepository struct {
+	next  news.AuthorRepository
+	cache *cache.Cache
+}
+
+// NewAuthorRepository will create a caching decorator of next
+func NewAuthorRepository(next news.AuthorRepository, c *cache.Cache) *AuthorRepository {
+	return &AuthorRepository{next: next, cache: c}
+}
+
+func (m *AuthorRepository) GetByID(ctx context.Context, id int64) (domain.Author, error) {
+	return m.getOne(ctx, key("author.GetByID", id), func(ctx context.Context) (domain.Author, error) {
+		return m.next.GetByID(ctx, id)
+	})
+}
+
+func (m *AuthorRepository) GetByName(ctx context.Context, name string) (domain.Author, error) {
+	return m.getOne(ctx, key("author.GetByName", name), func(ctx context.Context) (domain.Author, error) {
+		return m.next.GetByName(ctx, name)
+	})
+}
+
+func (m *AuthorRepository) getOne(ctx context.Context, key string, get func(ctx context.Context) (domain.Author, error)) (domain.Author, error) {
+	if repository.InTransaction(ctx) {
+		return get(ctx)
+	}
+	res, err := m.cache.Load(ctx, key, func() (interface{}, []string, error) {
+		a, err := get(fill(ctx))
+		return a, []string{authorTag(a.ID)}, err
+	})
+	if err != nil {
+		return domain.Author{}, err
+	}
+	return res.(domain.Author), nil
+}
+
+func (m *AuthorRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Author, error) {
+	if repository.InTransaction(ctx) {
+		return m.next.GetByIDs(ctx, ids)
+	}
+	res, err := m.cache.Load(ctx, key("author.GetByIDs", ids), func() (interface{}, []string, error) {
+		list, err := m.next.GetByIDs(fill(ctx), ids)
+		tags := make([]string, 0, len(ids))
+		for _, id := range ids {
+			tags = append(tags, authorTag(id))
+		}
+		return list, tags, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(res.([]domain.Author)), nil
+}
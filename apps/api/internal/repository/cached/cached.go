@@ -0,0 +1,69 @@
+// Package cached decorates the repositories with a cache.Cache. The reads are
+// cached with the tags of the entities they return, and the writes invalidate
+// these tags once their transaction is committed. Reads inside a transaction
+// are not cached, so that they see its writes, and the reads filling the cache
+// run on the primary database.
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+const (
+	// newsQueries tags the results which any news write may change, e.g.
+	// listings and counts
+	newsQueries = "news:*"
+	// topicQueries tags the results which any topic write may change
+	topicQueries = "topic:*"
+)
+
+func newsTag(id int64) string {
+	return "news:" + strconv.FormatInt(id, 10)
+}
+
+func topicTag(id int64) string {
+	return "topic:" + strconv.FormatInt(id, 10)
+}
+
+func authorTag(id int64) string {
+	return "author:" + strconv.FormatInt(id, 10)
+}
+
+// newsTags returns the tags of the news, of their author and of their topics
+func newsTags(list ...domain.News) []string {
+	tags := make([]string, 0, 2*len(list))
+	for _, n := range list {
+		tags = append(tags, newsTag(n.ID), authorTag(n.Author.ID))
+		for _, t := range n.Topics {
+			tags = append(tags, topicTag(t.ID))
+		}
+	}
+	return tags
+}
+
+// key identifies the call of method with args
+func key(method string, args ...interface{}) string {
+	b, _ := json.Marshal(args)
+	return method + string(b)
+}
+
+// fill returns the context of the reads filling the cache, which go to the
+// primary: a replica may not have replayed the write which invalidated the
+// entry yet, and its stale rows would be cached until the TTL
+func fill(ctx context.Context) context.Context {
+	return repository.WithPrimary(ctx)
+}
+
+// invalidate removes the entries carrying tags from c once the transaction
+// of ctx, if any, is committed
+func invalidate(ctx context.Context, c *cache.Cache, tags ...string) {
+	repository.AfterCommit(ctx, func() {
+		c.Invalidate(tags...)
+	})
+}
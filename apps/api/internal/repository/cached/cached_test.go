@@ -0,0 +1,177 @@
+package cached_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/bxcodec/go-clean-arch/internal/repository/cached"
+	newsService "github.com/bxcodec/go-clean-arch/news"
+	"github.com/bxcodec/go-clean-arch/topic"
+)
+
+// countingNewsRepo serves two news and counts the reads reaching it
+type countingNewsRepo struct {
+	newsService.NewsRepository
+	reads int
+}
+
+func (m *countingNewsRepo) Fetch(_ context.Context, _ domain.NewsFilter) ([]domain.News, int64, error) {
+	m.reads++
+	return []domain.News{
+		{ID: 1, Author: domain.AuthorNews{ID: 1}, Topics: []domain.TopicNews{{ID: 1}}},
+		{ID: 2, Author: domain.AuthorNews{ID: 1}},
+	}, 2, nil
+}
+
+func (m *countingNewsRepo) GetByID(_ context.Context, id int64) (domain.News, error) {
+	m.reads++
+	if id > 2 {
+		return domain.News{}, domain.ErrNotFound
+	}
+	n := domain.News{ID: id, Title: "Health Benefits"}
+	if id == 1 {
+		n.Topics = []domain.TopicNews{{ID: 1}}
+	}
+	return n, nil
+}
+
+func (m *countingNewsRepo) Update(context.Context, *news.UpdateNewsReq) error {
+	return nil
+}
+
+func (m *countingNewsRepo) Store(context.Context, *news.CreateNewsReq) error {
+	return nil
+}
+
+type countingTopicRepo struct {
+	topic.TopicRepository
+	reads int
+}
+
+func (m *countingTopicRepo) GetByID(_ context.Context, id int64) (domain.Topic, error) {
+	m.reads++
+	return domain.Topic{ID: id, Name: "Health"}, nil
+}
+
+func (m *countingTopicRepo) Update(context.Context, *domain.Topic) error {
+	return nil
+}
+
+func TestNewsRepository(t *testing.T) {
+	c := cache.New(100, time.Minute)
+	next := &countingNewsRepo{}
+	repo := cached.NewNewsRepository(next, c)
+	topics := cached.NewTopicRepository(&countingTopicRepo{}, c)
+	ctx := context.Background()
+
+	list, total, err := repo.Fetch(ctx, domain.NewsFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, int64(2), total)
+	// the callers may change their copy
+	list[0].Title = "Changed"
+	list, _, err = repo.Fetch(ctx, domain.NewsFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, list[0].Title)
+	_, _, err = repo.Fetch(ctx, domain.NewsFilter{Page: 2, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.reads)
+
+	for _, id := range []int64{1, 2} {
+		_, err = repo.GetByID(ctx, id)
+		require.NoError(t, err)
+	}
+	_, err = repo.GetByID(ctx, 3)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	next.reads = 0
+
+	// updating a news invalidates it and the listings, not the other news
+	id := int64(2)
+	require.NoError(t, repo.Update(ctx, &news.UpdateNewsReq{ID: &id}))
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, next.reads)
+	_, err = repo.GetByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.reads)
+	_, _, err = repo.Fetch(ctx, domain.NewsFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.reads)
+
+	// updating a topic invalidates the news carrying it
+	next.reads = 0
+	require.NoError(t, topics.Update(ctx, &domain.Topic{ID: 1}))
+	_, err = repo.GetByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 0, next.reads)
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	_, _, err = repo.Fetch(ctx, domain.NewsFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.reads)
+
+	// a new news invalidates the listings only
+	next.reads = 0
+	require.NoError(t, repo.Store(ctx, &news.CreateNewsReq{}))
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	_, _, err = repo.Fetch(ctx, domain.NewsFilter{Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, next.reads)
+}
+
+// routedTopicRepo reads its topics through a repository.DB
+type routedTopicRepo struct {
+	topic.TopicRepository
+	db *repository.DB
+}
+
+func (m *routedTopicRepo) GetByID(ctx context.Context, id int64) (domain.Topic, error) {
+	t := domain.Topic{ID: id}
+	err := m.db.Reader(ctx).QueryRowContext(ctx, "SELECT name FROM topic WHERE id = ?", id).Scan(&t.Name)
+	return t, err
+}
+
+func TestFillsReadFromThePrimary(t *testing.T) {
+	primary, primaryMock, err := sqlmock.New()
+	require.NoError(t, err)
+	replica, replicaMock, err := sqlmock.New()
+	require.NoError(t, err)
+	db := repository.NewDB(primary, replica)
+	repo := cached.NewTopicRepository(&routedTopicRepo{db: db}, cache.New(100, time.Minute))
+
+	// a replica may not have replayed the write which invalidated the entry
+	primaryMock.ExpectQuery("SELECT name FROM topic").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Health"))
+	to, err := repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Health", to.Name)
+
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+	assert.NoError(t, replicaMock.ExpectationsWereMet())
+	assert.Equal(t, int64(0), db.Stats()["replica1"].Queries)
+}
+
+func TestCollectedTags(t *testing.T) {
+	c := cache.New(100, time.Minute)
+	repo := cached.NewNewsRepository(&countingNewsRepo{}, c)
+
+	ctx, tags := cache.WithCollector(context.Background())
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"news:1", "author:0", "topic:1"}, tags())
+
+	// hits collect the tags too
+	ctx, tags = cache.WithCollector(context.Background())
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"news:1", "author:0", "topic:1"}, tags())
+}
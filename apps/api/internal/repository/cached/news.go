@@ -0,0 +1,152 @@
+package cached
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	newsService "github.com/bxcodec/go-clean-arch/news"
+)
+
+// NewsRepository caches Fetch, Count, FetchLatestByAuthors and GetByID of a
+// news repository
+type NewsRepository struct {
+	next  newsService.NewsRepository
+	cache *cache.Cache
+}
+
+// NewNewsRepository will create a caching decorator of next
+func NewNewsRepository(next newsService.NewsRepository, c *cache.Cache) *NewsRepository {
+	return &NewsRepository{next: next, cache: c}
+}
+
+// newsPage is a cached result of Fetch
+type newsPage struct {
+	list  []domain.News
+	total int64
+}
+
+func (m *NewsRepository) Fetch(ctx context.Context, filter domain.NewsFilter) ([]domain.News, int64, error) {
+	if repository.InTransaction(ctx) {
+		return m.next.Fetch(ctx, filter)
+	}
+	res, err := m.cache.Load(ctx, key("news.Fetch", filter), func() (interface{}, []string, error) {
+		list, total, err := m.next.Fetch(fill(ctx), filter)
+		return newsPage{list: list, total: total}, append(newsTags(list...), newsQueries), err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	page := res.(newsPage)
+	// the callers fill in the authors and topics of the news they get
+	return slices.Clone(page.list), page.total, nil
+}
+
+func (m *NewsRepository) Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) error {
+	cache.Collect(ctx, newsQueries)
+	return m.next.Stream(ctx, filter, fn)
+}
+
+func (m *NewsRepository) Count(ctx context.Context, filter domain.NewsFilter) (int64, error) {
+	if repository.InTransaction(ctx) {
+		return m.next.Count(ctx, filter)
+	}
+	res, err := m.cache.Load(ctx, key("news.Count", filter), func() (interface{}, []string, error) {
+		count, err := m.next.Count(fill(ctx), filter)
+		return count, []string{newsQueries}, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.(int64), nil
+}
+
+func (m *NewsRepository) FetchLatestByAuthors(ctx context.Context, authorIDs []int64, limit int64) ([]domain.News, error) {
+	if repository.InTransaction(ctx) {
+		return m.next.FetchLatestByAuthors(ctx, authorIDs, limit)
+	}
+	res, err := m.cache.Load(ctx, key("news.FetchLatestByAuthors", authorIDs, limit), func() (interface{}, []string, error) {
+		list, err := m.next.FetchLatestByAuthors(fill(ctx), authorIDs, limit)
+		return list, append(newsTags(list...), newsQueries), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(res.([]domain.News)), nil
+}
+
+func (m *NewsRepository) Archive(ctx context.Context, filter domain.NewsFilter) ([]domain.NewsArchive, error) {
+	cache.Collect(ctx, newsQueries)
+	return m.next.Archive(ctx, filter)
+}
+
+func (m *NewsRepository) Facets(ctx context.Context, filter domain.NewsFilter, facets []string) ([]domain.Facet, error) {
+	cache.Collect(ctx, newsQueries, topicQueries)
+	return m.next.Facets(ctx, filter, facets)
+}
+
+func (m *NewsRepository) AuthorStats(ctx context.Context, filter domain.AuthorStatsFilter) ([]domain.AuthorStats, error) {
+	cache.Collect(ctx, newsQueries, topicQueries)
+	return m.next.AuthorStats(ctx, filter)
+}
+
+func (m *NewsRepository) GetByID(ctx context.Context, id int64) (domain.News, error) {
+	if repository.InTransaction(ctx) {
+		return m.next.GetByID(ctx, id)
+	}
+	res, err := m.cache.Load(ctx, key("news.GetByID", id), func() (interface{}, []string, error) {
+		n, err := m.next.GetByID(fill(ctx), id)
+		return n, newsTags(n), err
+	})
+	if err != nil {
+		return domain.News{}, err
+	}
+	return res.(domain.News), nil
+}
+
+// GetByTitle is not cached, it checks the conflicts of the writes
+func (m *NewsRepository) GetByTitle(ctx context.Context, title string) (domain.News, error) {
+	cache.Collect(ctx, newsQueries)
+	return m.next.GetByTitle(ctx, title)
+}
+
+// ExistingTitles is not cached, it checks the conflicts of the writes
+func (m *NewsRepository) ExistingTitles(ctx context.Context, titles []string) ([]string, error) {
+	cache.Collect(ctx, newsQueries)
+	return m.next.ExistingTitles(ctx, titles)
+}
+
+func (m *NewsRepository) Update(ctx context.Context, ar *news.UpdateNewsReq) error {
+	if err := m.next.Update(ctx, ar); err != nil {
+		return err
+	}
+	invalidate(ctx, m.cache, newsTag(*ar.ID), newsQueries)
+	return nil
+}
+
+func (m *NewsRepository) Store(ctx context.Context, a *news.CreateNewsReq) error {
+	if err := m.next.Store(ctx, a); err != nil {
+		return err
+	}
+	invalidate(ctx, m.cache, newsQueries)
+	return nil
+}
+
+func (m *NewsRepository) StoreBatch(ctx context.Context, list []*news.CreateNewsReq) error {
+	if err := m.next.StoreBatch(ctx, list); err != nil {
+		return err
+	}
+	invalidate(ctx, m.cache, newsQueries)
+	return nil
+}
+
+func (m *NewsRepository) Delete(ctx context.Context, id int64, version int64) error {
+	if err := m.next.Delete(ctx, id, version); err != nil {
+		return err
+	}
+	invalidate(ctx, m.cache, newsTag(id), newsQueries)
+	return nil
+}
@@ -0,0 +1,135 @@
+package cached
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/bxcodec/go-clean-arch/topic"
+)
+
+// TopicRepository caches Fetch without stats, GetByID and GetByIDs of a topic
+// repository
+type TopicRepository struct {
+	next  topic.TopicRepository
+	cache *cache.Cache
+}
+
+// NewTopicRepository will create a caching decorator of next
+func NewTopicRepository(next topic.TopicRepository, c *cache.Cache) *TopicRepository {
+	return &TopicRepository{next: next, cache: c}
+}
+
+// topicPage is a cached result of Fetch
+type topicPage struct {
+	list  []domain.Topic
+	total int64
+}
+
+func topicTags(list ...domain.Topic) []string {
+	tags := make([]string, 0, len(list))
+	for _, t := range list {
+		tags = append(tags, topicTag(t.ID))
+	}
+	return tags
+}
+
+// Fetch is not cached with stats, which depend on every news
+func (m *TopicRepository) Fetch(ctx context.Context, filter domain.TopicFilter) ([]domain.Topic, int64, error) {
+	if filter.WithStats {
+		cache.Collect(ctx, topicQueries, newsQueries)
+		return m.next.Fetch(ctx, filter)
+	}
+	if repository.InTransaction(ctx) {
+		return m.next.Fetch(ctx, filter)
+	}
+	res, err := m.cache.Load(ctx, key("topic.Fetch", filter), func() (interface{}, []string, error) {
+		list, total, err := m.next.Fetch(fill(ctx), filter)
+		return topicPage{list: list, total: total}, append(topicTags(list...), topicQueries), err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	page := res.(topicPage)
+	return slices.Clone(page.list), page.total, nil
+}
+
+func (m *TopicRepository) Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) error {
+	cache.Collect(ctx, topicQueries)
+	return m.next.Stream(ctx, filter, fn)
+}
+
+// GetByName is not cached, it checks the conflicts of the writes
+func (m *TopicRepository) GetByName(ctx context.Context, name string) (domain.Topic, error) {
+	cache.Collect(ctx, topicQueries)
+	return m.next.GetByName(ctx, name)
+}
+
+func (m *TopicRepository) GetByID(ctx context.Context, id int64) (domain.Topic, error) {
+	if repository.InTransaction(ctx) {
+		return m.next.GetByID(ctx, id)
+	}
+	res, err := m.cache.Load(ctx, key("topic.GetByID", id), func() (interface{}, []string, error) {
+		t, err := m.next.GetByID(fill(ctx), id)
+		return t, []string{topicTag(id)}, err
+	})
+	if err != nil {
+		return domain.Topic{}, err
+	}
+	return res.(domain.Topic), nil
+}
+
+func (m *TopicRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Topic, error) {
+	if repository.InTransaction(ctx) {
+		return m.next.GetByIDs(ctx, ids)
+	}
+	res, err := m.cache.Load(ctx, key("topic.GetByIDs", ids), func() (interface{}, []string, error) {
+		list, err := m.next.GetByIDs(fill(ctx), ids)
+		tags := make([]string, 0, len(ids))
+		for _, id := range ids {
+			tags = append(tags, topicTag(id))
+		}
+		return list, tags, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(res.([]domain.Topic)), nil
+}
+
+func (m *TopicRepository) CountNews(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	cache.Collect(ctx, topicQueries, newsQueries)
+	return m.next.CountNews(ctx, ids)
+}
+
+func (m *TopicRepository) Stats(ctx context.Context, id int64, weeks int64) (domain.TopicActivity, error) {
+	cache.Collect(ctx, topicTag(id), newsQueries)
+	return m.next.Stats(ctx, id, weeks)
+}
+
+func (m *TopicRepository) Update(ctx context.Context, t *domain.Topic) error {
+	if err := m.next.Update(ctx, t); err != nil {
+		return err
+	}
+	invalidate(ctx, m.cache, topicTag(t.ID), topicQueries)
+	return nil
+}
+
+func (m *TopicRepository) Store(ctx context.Context, t *domain.Topic) error {
+	if err := m.next.Store(ctx, t); err != nil {
+		return err
+	}
+	invalidate(ctx, m.cache, topicQueries)
+	return nil
+}
+
+// Delete also invalidates the news queries, the news of the topic losing it
+func (m *TopicRepository) Delete(ctx context.Context, id int64, version int64) error {
+	if err := m.next.Delete(ctx, id, version); err != nil {
+		return err
+	}
+	invalidate(ctx, m.cache, topicTag(id), topicQueries, newsQueries)
+	return nil
+}
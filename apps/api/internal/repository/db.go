@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DB routes the queries of the repositories between a primary database and
+// its read replicas. The reads go to the healthy replicas in turn, unless they
+// must see writes which the replicas may not have replayed yet:
+//   - inside a transaction, which runs on the primary
+//   - in a session which has written, see WithSession
+type DB struct {
+	Primary *sql.DB
+
+	primary  *pool
+	replicas []*pool
+	next     atomic.Uint64
+}
+
+// pool is a database the queries are routed to
+type pool struct {
+	name      string
+	db        *sql.DB
+	healthy   atomic.Bool
+	queries   atomic.Int64
+	fallbacks atomic.Int64
+}
+
+// PoolStats are the counters of a database since the DB was created
+type PoolStats struct {
+	Healthy bool  `json:"healthy"`
+	Queries int64 `json:"queries"`
+	// Fallbacks counts the reads of a replica run again on the primary after
+	// it could not be reached
+	Fallbacks int64 `json:"fallbacks,omitempty"`
+}
+
+// NewDB will create a DB routing the reads to the replicas, which are
+// healthy until CheckReplicas finds otherwise
+func NewDB(primary *sql.DB, replicas ...*sql.DB) *DB {
+	db := &DB{
+		Primary: primary,
+		primary: &pool{name: "primary", db: primary},
+	}
+	db.primary.healthy.Store(true)
+	for i, replica := range replicas {
+		p := &pool{name: fmt.Sprintf("replica%d", i+1), db: replica}
+		p.healthy.Store(true)
+		db.replicas = append(db.replicas, p)
+	}
+	return db
+}
+
+type sessionKey struct{}
+
+// session tracks whether the queries of a context have written
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession returns a context whose reads go to the primary once it has
+// written, e.g. to read the entity a request has just updated
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// WithPrimary returns a context whose reads go to the primary
+func WithPrimary(ctx context.Context) context.Context {
+	s := &session{}
+	s.wrote.Store(true)
+	return context.WithValue(ctx, sessionKey{}, s)
+}
+
+// Writer returns the connection to write with, the transaction carried by
+// ctx or the primary
+func (db *DB) Writer(ctx context.Context) Conn {
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok {
+		s.wrote.Store(true)
+	}
+	return db.conn(ctx, db.primary)
+}
+
+// Reader returns the connection to read with, the transaction carried by ctx,
+// the primary or a healthy replica
+func (db *DB) Reader(ctx context.Context) Conn {
+	return db.conn(ctx, db.readPool(ctx))
+}
+
+func (db *DB) readPool(ctx context.Context) *pool {
+	if len(db.replicas) == 0 {
+		return db.primary
+	}
+	if s, ok := ctx.Value(sessionKey{}).(*session); ok && s.wrote.Load() {
+		return db.primary
+	}
+
+	start := db.next.Add(1) - 1
+	for i := range db.replicas {
+		p := db.replicas[(start+uint64(i))%uint64(len(db.replicas))]
+		if p.healthy.Load() {
+			return p
+		}
+	}
+	return db.primary
+}
+
+func (db *DB) conn(ctx context.Context, p *pool) Conn {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return &routedConn{db: db, pool: p}
+}
+
+// CheckReplicas pings the replicas every interval until ctx is done. The
+// replicas which do not answer get no reads until they answer again.
+func (db *DB) CheckReplicas(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, p := range db.replicas {
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			err := p.db.PingContext(pingCtx)
+			cancel()
+			if healthy := err == nil; p.healthy.Swap(healthy) != healthy {
+				logrus.WithField("pool", p.name).WithError(err).Warnf("database %s healthy: %t", p.name, healthy)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stats returns the counters of the primary and of the replicas by name
+func (db *DB) Stats() map[string]PoolStats {
+	stats := map[string]PoolStats{}
+	for _, p := range append([]*pool{db.primary}, db.replicas...) {
+		stats[p.name] = PoolStats{
+			Healthy:   p.healthy.Load(),
+			Queries:   p.queries.Load(),
+			Fallbacks: p.fallbacks.Load(),
+		}
+	}
+	return stats
+}
+
+// routedConn runs the queries on a pool, and the reads of a replica which
+// can not be reached on the primary
+type routedConn struct {
+	db   *DB
+	pool *pool
+}
+
+func (c *routedConn) served(query string) {
+	c.pool.queries.Add(1)
+	logrus.WithField("pool", c.pool.name).Debug(query)
+}
+
+// fallback tells whether a query which failed with err on a replica must run
+// again on the primary, and marks the replica unhealthy
+func (c *routedConn) fallback(ctx context.Context, err error) bool {
+	if err == nil || c.pool == c.db.primary || ctx.Err() != nil {
+		return false
+	}
+	var netErr net.Error
+	if !errors.Is(err, driver.ErrBadConn) && !errors.As(err, &netErr) {
+		return false
+	}
+	logrus.WithField("pool", c.pool.name).Warn("database unreachable, falling back to the primary: ", err)
+	c.pool.healthy.Store(false)
+	c.pool.fallbacks.Add(1)
+	return true
+}
+
+func (c *routedConn) primary() *routedConn {
+	return &routedConn{db: c.db, pool: c.db.primary}
+}
+
+func (c *routedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	c.served(query)
+	res, err := c.pool.db.ExecContext(ctx, query, args...)
+	if c.fallback(ctx, err) {
+		return c.primary().ExecContext(ctx, query, args...)
+	}
+	return res, err
+}
+
+func (c *routedConn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	c.served(query)
+	stmt, err := c.pool.db.PrepareContext(ctx, query)
+	if c.fallback(ctx, err) {
+		return c.primary().PrepareContext(ctx, query)
+	}
+	return stmt, err
+}
+
+func (c *routedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	c.served(query)
+	rows, err := c.pool.db.QueryContext(ctx, query, args...)
+	if c.fallback(ctx, err) {
+		return c.primary().QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+func (c *routedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	c.served(query)
+	row := c.pool.db.QueryRowContext(ctx, query, args...)
+	if c.fallback(ctx, row.Err()) {
+		return c.primary().QueryRowContext(ctx, query, args...)
+	}
+	return row
+}
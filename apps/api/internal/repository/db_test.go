@@ -0,0 +1,107 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+func newMockDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	return db, mock
+}
+
+func query(t *testing.T, conn repository.Conn) {
+	rows, err := conn.QueryContext(context.TODO(), "SELECT id FROM topic")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+}
+
+func TestDBRoutesReads(t *testing.T) {
+	primary, primaryMock := newMockDB(t)
+	replica1, replica1Mock := newMockDB(t)
+	replica2, replica2Mock := newMockDB(t)
+	db := repository.NewDB(primary, replica1, replica2)
+
+	// the reads go to the replicas in turn
+	for _, mock := range []sqlmock.Sqlmock{replica1Mock, replica2Mock} {
+		mock.ExpectQuery("SELECT id FROM topic").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+	query(t, db.Reader(context.TODO()))
+	query(t, db.Reader(context.TODO()))
+
+	// a session reads from the primary once it has written
+	ctx := repository.WithSession(context.TODO())
+	replica1Mock.ExpectQuery("SELECT id FROM topic").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	query(t, db.Reader(ctx))
+	primaryMock.ExpectExec("INSERT INTO topic").WillReturnResult(sqlmock.NewResult(1, 1))
+	_, err := db.Writer(ctx).ExecContext(ctx, "INSERT INTO topic (name) VALUES ('Health')")
+	require.NoError(t, err)
+	primaryMock.ExpectQuery("SELECT id FROM topic").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	query(t, db.Reader(ctx))
+
+	for _, mock := range []sqlmock.Sqlmock{primaryMock, replica1Mock, replica2Mock} {
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+	stats := db.Stats()
+	assert.Equal(t, int64(2), stats["primary"].Queries)
+	assert.Equal(t, int64(2), stats["replica1"].Queries)
+	assert.Equal(t, int64(1), stats["replica2"].Queries)
+}
+
+func TestDBFallback(t *testing.T) {
+	primary, primaryMock := newMockDB(t)
+	replica, replicaMock := newMockDB(t)
+	db := repository.NewDB(primary, replica)
+
+	// a replica which can not be reached is skipped until it answers again
+	replicaMock.ExpectQuery("SELECT id FROM topic").WillReturnError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	primaryMock.ExpectQuery("SELECT id FROM topic").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	primaryMock.ExpectQuery("SELECT id FROM topic").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	query(t, db.Reader(context.TODO()))
+	query(t, db.Reader(context.TODO()))
+
+	stats := db.Stats()
+	assert.False(t, stats["replica1"].Healthy)
+	assert.Equal(t, int64(1), stats["replica1"].Fallbacks)
+	assert.Equal(t, int64(2), stats["primary"].Queries)
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go db.CheckReplicas(ctx, time.Hour)
+	assert.Eventually(t, func() bool { return db.Stats()["replica1"].Healthy }, time.Second, time.Millisecond)
+	cancel()
+}
+
+func TestDBTransaction(t *testing.T) {
+	primary, primaryMock := newMockDB(t)
+	replica, _ := newMockDB(t)
+	db := repository.NewDB(primary, replica)
+
+	primaryMock.ExpectBegin()
+	primaryMock.ExpectQuery("SELECT id FROM topic").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	primaryMock.ExpectCommit()
+
+	// the reads of a transaction run in it, on the primary
+	err := repository.NewTransactor(primary).WithinTransaction(context.TODO(), func(ctx context.Context) error {
+		rows, err := db.Reader(ctx).QueryContext(ctx, "SELECT id FROM topic")
+		if err != nil {
+			return err
+		}
+		return rows.Close()
+	})
+	require.NoError(t, err)
+	assert.NoError(t, primaryMock.ExpectationsWereMet())
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"encoding/base64"
+	"strings"
 	"time"
 )
 
@@ -9,6 +10,15 @@ const (
 	timeFormat = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
 )
 
+// likeEscaper escapes the wildcards of LIKE patterns with a backslash
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern returns the LIKE pattern of the values containing s, whose
+// % and _ are matched literally
+func ContainsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
 // DecodeCursor will decode cursor from user for mysql
 func DecodeCursor(encodedTime string) (time.Time, error) {
 	byt, err := base64.StdEncoding.DecodeString(encodedTime)
@@ -1,184 +0,0 @@
-package mysql
-
-import (
-	"context"
-	"database/sql"
-	"fmt"
-
-	"github.com/sirupsen/logrus"
-
-	"github.com/bxcodec/go-clean-arch/domain"
-	"github.com/bxcodec/go-clean-arch/internal/repository"
-)
-
-type NewsRepository struct {
-	Conn *sql.DB
-}
-
-// NewNewsRepository will create an object that represent the news.Repository interface
-func NewNewsRepository(conn *sql.DB) *NewsRepository {
-	return &NewsRepository{conn}
-}
-
-func (m *NewsRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.News, err error) {
-	rows, err := m.Conn.QueryContext(ctx, query, args...)
-	if err != nil {
-		logrus.Error(err)
-		return nil, err
-	}
-
-	defer func() {
-		errRow := rows.Close()
-		if errRow != nil {
-			logrus.Error(errRow)
-		}
-	}()
-
-	result = make([]domain.News, 0)
-	for rows.Next() {
-		t := domain.News{}
-		authorID := int64(0)
-		err = rows.Scan(
-			&t.ID,
-			&t.Title,
-			&t.Content,
-			&authorID,
-			&t.UpdatedAt,
-			&t.CreatedAt,
-		)
-
-		if err != nil {
-			logrus.Error(err)
-			return nil, err
-		}
-		t.Author = domain.AuthorNews{
-			ID: authorID,
-		}
-		result = append(result, t)
-	}
-
-	return result, nil
-}
-
-func (m *NewsRepository) Fetch(ctx context.Context, cursor string, num int64) (res []domain.News, nextCursor string, err error) {
-	query := `SELECT id,title,content, author_id, updated_at, created_at
-  						FROM news WHERE created_at > ? ORDER BY created_at LIMIT ? `
-
-	decodedCursor, err := repository.DecodeCursor(cursor)
-	if err != nil && cursor != "" {
-		return nil, "", domain.ErrBadParamInput
-	}
-
-	res, err = m.fetch(ctx, query, decodedCursor, num)
-	if err != nil {
-		return nil, "", err
-	}
-
-	if len(res) == int(num) {
-		nextCursor = repository.EncodeCursor(res[len(res)-1].CreatedAt)
-	}
-
-	return
-}
-func (m *NewsRepository) GetByID(ctx context.Context, id int64) (res domain.News, err error) {
-	query := `SELECT id,title,content, author_id, updated_at, created_at
-  						FROM news WHERE ID = ?`
-
-	list, err := m.fetch(ctx, query, id)
-	if err != nil {
-		return domain.News{}, err
-	}
-
-	if len(list) > 0 {
-		res = list[0]
-	} else {
-		return res, domain.ErrNotFound
-	}
-
-	return
-}
-
-func (m *NewsRepository) GetByTitle(ctx context.Context, title string) (res domain.News, err error) {
-	query := `SELECT id,title,content, author_id, updated_at, created_at
-  						FROM news WHERE title = ?`
-
-	list, err := m.fetch(ctx, query, title)
-	if err != nil {
-		return
-	}
-
-	if len(list) > 0 {
-		res = list[0]
-	} else {
-		return res, domain.ErrNotFound
-	}
-	return
-}
-
-func (m *NewsRepository) Store(ctx context.Context, a *domain.News) (err error) {
-	query := `INSERT  news SET title=? , content=? , author_id=?, updated_at=? , created_at=?`
-	stmt, err := m.Conn.PrepareContext(ctx, query)
-	if err != nil {
-		return
-	}
-
-	res, err := stmt.ExecContext(ctx, a.Title, a.Content, a.Author.ID, a.UpdatedAt, a.CreatedAt)
-	if err != nil {
-		return
-	}
-	lastID, err := res.LastInsertId()
-	if err != nil {
-		return
-	}
-	a.ID = lastID
-	return
-}
-
-func (m *NewsRepository) Delete(ctx context.Context, id int64) (err error) {
-	query := "DELETE FROM news WHERE id = ?"
-
-	stmt, err := m.Conn.PrepareContext(ctx, query)
-	if err != nil {
-		return
-	}
-
-	res, err := stmt.ExecContext(ctx, id)
-	if err != nil {
-		return
-	}
-
-	rowsAfected, err := res.RowsAffected()
-	if err != nil {
-		return
-	}
-
-	if rowsAfected != 1 {
-		err = fmt.Errorf("weird  Behavior. Total Affected: %d", rowsAfected)
-		return
-	}
-
-	return
-}
-func (m *NewsRepository) Update(ctx context.Context, ar *domain.News) (err error) {
-	query := `UPDATE news set title=?, content=?, author_id=?, updated_at=? WHERE ID = ?`
-
-	stmt, err := m.Conn.PrepareContext(ctx, query)
-	if err != nil {
-		return
-	}
-
-	res, err := stmt.ExecContext(ctx, ar.Title, ar.Content, ar.Author.ID, ar.UpdatedAt, ar.ID)
-	if err != nil {
-		return
-	}
-	affect, err := res.RowsAffected()
-	if err != nil {
-		return
-	}
-	if affect != 1 {
-		err = fmt.Errorf("weird  Behavior. Total Affected: %d", affect)
-		return
-	}
-
-	return
-}
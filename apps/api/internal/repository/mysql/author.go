@@ -3,27 +3,27 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
+
+	"github.com/sirupsen/logrus"
 
 	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
 )
 
 type AuthorRepository struct {
-	DB *sql.DB
+	DB *repository.DB
 }
 
-// NewMysqlAuthorRepository will create an implementation of author.Repository
-func NewAuthorRepository(db *sql.DB) *AuthorRepository {
+// NewAuthorRepository will create an implementation of author.Repository
+func NewAuthorRepository(db *repository.DB) *AuthorRepository {
 	return &AuthorRepository{
 		DB: db,
 	}
 }
 
 func (m *AuthorRepository) getOne(ctx context.Context, query string, args ...interface{}) (res domain.Author, err error) {
-	stmt, err := m.DB.PrepareContext(ctx, query)
-	if err != nil {
-		return domain.Author{}, err
-	}
-	row := stmt.QueryRowContext(ctx, args...)
+	row := m.DB.Reader(ctx).QueryRowContext(ctx, query, args...)
 	res = domain.Author{}
 
 	err = row.Scan(
@@ -32,6 +32,9 @@ func (m *AuthorRepository) getOne(ctx context.Context, query string, args ...int
 		&res.CreatedAt,
 		&res.UpdatedAt,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Author{}, domain.ErrNotFound
+	}
 	return
 }
 
@@ -39,3 +42,34 @@ func (m *AuthorRepository) GetByID(ctx context.Context, id int64) (domain.Author
 	query := `SELECT id, name, created_at, updated_at FROM author WHERE id=?`
 	return m.getOne(ctx, query, id)
 }
+
+func (m *AuthorRepository) GetByName(ctx context.Context, name string) (domain.Author, error) {
+	query := `SELECT id, name, created_at, updated_at FROM author WHERE name=?`
+	return m.getOne(ctx, query, name)
+}
+
+// GetByIDs returns the authors with the given IDs, unknown IDs are ignored
+func (m *AuthorRepository) GetByIDs(ctx context.Context, ids []int64) (res []domain.Author, err error) {
+	placeholders, args := in(ids)
+	query := `SELECT id, name, created_at, updated_at FROM author WHERE id IN (` + placeholders + `)`
+	rows, err := m.DB.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	res = make([]domain.Author, 0, len(ids))
+	for rows.Next() {
+		a := domain.Author{}
+		if err = rows.Scan(&a.ID, &a.Name, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
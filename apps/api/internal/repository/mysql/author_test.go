@@ -2,13 +2,16 @@ package mysql_test
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
 
-	repository "github.com/bxcodec/go-clean-arch/internal/repository/mysql"
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	mysqlRepo "github.com/bxcodec/go-clean-arch/internal/repository/mysql"
 )
 
 func TestGetAuthorByID(t *testing.T) {
@@ -22,13 +25,43 @@ func TestGetAuthorByID(t *testing.T) {
 
 	query := "SELECT id, name, created_at, updated_at FROM author WHERE id=\\?"
 
-	prep := mock.ExpectPrepare(query)
 	userID := int64(1)
-	prep.ExpectQuery().WithArgs(userID).WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs(userID).WillReturnRows(rows)
+	mock.ExpectQuery(query).WithArgs(int64(2)).WillReturnError(sql.ErrNoRows)
 
-	a := repository.NewAuthorRepository(db)
+	a := mysqlRepo.NewAuthorRepository(repository.NewDB(db))
 
-	anNews, err := a.GetByID(context.TODO(), userID)
+	anAuthor, err := a.GetByID(context.TODO(), userID)
 	assert.NoError(t, err)
-	assert.NotNil(t, anNews)
+	assert.Equal(t, "Iman Tumorang", anAuthor.Name)
+
+	_, err = a.GetByID(context.TODO(), 2)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuthorsByIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "name", "updated_at", "created_at"}).
+		AddRow(1, "Doni", time.Now(), time.Now()).
+		AddRow(2, "Deni", time.Now(), time.Now())
+	mock.ExpectQuery("SELECT id, name, created_at, updated_at FROM author WHERE id IN \\(\\?, \\?\\)").
+		WithArgs(int64(1), int64(2)).WillReturnRows(rows)
+	// no IDs match no author
+	mock.ExpectQuery("SELECT id, name, created_at, updated_at FROM author WHERE id IN \\(NULL\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "updated_at", "created_at"}))
+
+	a := mysqlRepo.NewAuthorRepository(repository.NewDB(db))
+	list, err := a.GetByIDs(context.TODO(), []int64{1, 2})
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	list, err = a.GetByIDs(context.TODO(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,62 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	mysqlRepo "github.com/bxcodec/go-clean-arch/internal/repository/mysql"
+	"github.com/bxcodec/go-clean-arch/internal/repository/repositorytest"
+)
+
+// TestContract runs the repository contract against the database of the
+// TEST_MYSQL_URL DSN, created with init_mysql.sql, whose data it deletes
+func TestContract(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_URL")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_URL is not set")
+	}
+	cfg, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+	cfg.ParseTime = true
+	conn, err := sql.Open("mysql", cfg.FormatDSN())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		reset(t, conn)
+		db := repository.NewDB(conn)
+		return repositorytest.Repositories{
+			News:       mysqlRepo.NewNewsRepository(db),
+			Authors:    mysqlRepo.NewAuthorRepository(db),
+			Topics:     mysqlRepo.NewTopicRepository(db),
+			NewsTopics: mysqlRepo.NewNewsTopicRepository(db),
+			Audit:      mysqlRepo.NewAuditRepository(db),
+			Transactor: repository.NewTransactor(conn),
+		}
+	})
+}
+
+// reset empties the tables, the foreign keys being checked per session
+func reset(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `SET FOREIGN_KEY_CHECKS = 0`)
+	require.NoError(t, err)
+	for _, table := range repositorytest.Tables {
+		_, err = conn.ExecContext(ctx, `TRUNCATE TABLE `+table)
+		require.NoError(t, err)
+	}
+	_, err = conn.ExecContext(ctx, `SET FOREIGN_KEY_CHECKS = 1`)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, repositorytest.SeedAuthors)
+	require.NoError(t, err)
+}
@@ -0,0 +1,28 @@
+package mysql
+
+import (
+	"strings"
+)
+
+// in returns the placeholders and the arguments of an IN list of values,
+// matching nothing when values is empty
+func in[T any](values []T) (placeholders string, args []interface{}) {
+	if len(values) == 0 {
+		return "NULL", nil
+	}
+	args = make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), args
+}
+
+// maxBindParameters is the number of parameters MySQL binds per statement
+const maxBindParameters = 65535
+
+// monthStart and weekStart truncate a DATETIME column to the first day of
+// its month and to the Monday of its week
+const (
+	monthStart = `CAST(DATE_FORMAT(%[1]s, '%%Y-%%m-01') AS DATETIME)`
+	weekStart  = `CAST(DATE_SUB(DATE(%[1]s), INTERVAL WEEKDAY(%[1]s) DAY) AS DATETIME)`
+)
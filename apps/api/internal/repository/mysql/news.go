@@ -0,0 +1,757 @@
+package mysql
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+type NewsRepository struct {
+	Conn *repository.DB
+}
+
+// NewNewsRepository will create an object that represent the news.Repository interface
+func NewNewsRepository(conn *repository.DB) *NewsRepository {
+	return &NewsRepository{conn}
+}
+
+func (nr *NewsRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.News, err error) {
+	rows, err := nr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	result = make([]domain.News, 0)
+	for rows.Next() {
+		t := domain.News{}
+		err = rows.Scan(
+			&t.ID,
+			&t.Title,
+			&t.Content,
+			&t.Author.ID,
+			&t.Status,
+			&t.Version,
+			&t.UpdatedAt,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// newsColumns lists the columns of the selectable fields and where they are
+// scanned, the id is always loaded
+var newsColumns = []struct {
+	field  string
+	column string
+	dest   func(n *domain.News) interface{}
+}{
+	{"id", "id", func(n *domain.News) interface{} { return &n.ID }},
+	{"title", "title", func(n *domain.News) interface{} { return &n.Title }},
+	{"content", "content", func(n *domain.News) interface{} { return &n.Content }},
+	{"author", "author_id", func(n *domain.News) interface{} { return &n.Author.ID }},
+	{"status", "status", func(n *domain.News) interface{} { return &n.Status }},
+	{"version", "version", func(n *domain.News) interface{} { return &n.Version }},
+	{"updated_at", "updated_at", func(n *domain.News) interface{} { return &n.UpdatedAt }},
+	{"created_at", "created_at", func(n *domain.News) interface{} { return &n.CreatedAt }},
+}
+
+// fetchFields selects the columns of the fields of filter, followed by the
+// FROM clause and the rest of the query in from
+func (nr *NewsRepository) fetchFields(ctx context.Context, filter domain.NewsFilter, from string, args ...interface{}) ([]domain.News, error) {
+	var columns []string
+	var dests []func(n *domain.News) interface{}
+	for _, c := range newsColumns {
+		if c.field == "id" || filter.Selects(c.field) {
+			columns = append(columns, c.column)
+			dests = append(dests, c.dest)
+		}
+	}
+
+	rows, err := nr.Conn.Reader(ctx).QueryContext(ctx, "SELECT "+strings.Join(columns, ", ")+from, args...)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	result := make([]domain.News, 0)
+	for rows.Next() {
+		t := domain.News{}
+		values := make([]interface{}, len(dests))
+		for i, dest := range dests {
+			values[i] = dest(&t)
+		}
+		if err = rows.Scan(values...); err != nil {
+			logrus.Error(err)
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// newsSortColumns lists the columns news can be sorted by
+var newsSortColumns = map[string]string{
+	"id":         "news.id",
+	"title":      "news.title",
+	"status":     "news.status",
+	"author_id":  "news.author_id",
+	"created_at": "news.created_at",
+	"updated_at": "news.updated_at",
+}
+
+// newsFilter builds the WHERE clause matching filter
+func newsFilter(filter domain.NewsFilter) (where string, args []interface{}) {
+	where = " WHERE 1=1"
+
+	// Add conditions based on optional filters
+	if filter.ID != 0 {
+		where += " AND news.id = ?"
+		args = append(args, filter.ID)
+	}
+	if len(filter.IDs) > 0 {
+		placeholders, ids := in(filter.IDs)
+		where += " AND news.id IN (" + placeholders + ")"
+		args = append(args, ids...)
+	}
+	if filter.Title != "" {
+		// the columns use a case insensitive collation
+		where += " AND news.title LIKE ?"
+		args = append(args, repository.ContainsPattern(filter.Title))
+	}
+	if filter.Status != "" {
+		where += " AND news.status = ?"
+		args = append(args, filter.Status)
+	}
+	if filter.AuthorID != 0 {
+		where += " AND news.author_id = ?"
+		args = append(args, filter.AuthorID)
+	}
+	if filter.TopicID != 0 {
+		where += " AND news.id IN (SELECT news_id FROM news_topic WHERE topic_id = ?)"
+		args = append(args, filter.TopicID)
+	}
+	var zeroTime time.Time
+	if filter.StartDate != zeroTime {
+		where += " AND news.created_at >= ?"
+		args = append(args, filter.StartDate)
+	}
+	if filter.EndDate != zeroTime {
+		where += " AND news.created_at <= ?"
+		args = append(args, filter.EndDate)
+	}
+	return where, args
+}
+
+// newsOrderBy builds the ORDER BY clause, unknown columns are ignored. Ties
+// are broken by id so that the pages do not overlap.
+func newsOrderBy(filter domain.NewsFilter) string {
+	column, ok := newsSortColumns[filter.SortBy]
+	if !ok {
+		return ""
+	}
+	orderDirection := "ASC" // default to ascending
+	if filter.SortOrder == "desc" {
+		orderDirection = "DESC"
+	}
+	if column == "news.id" {
+		return fmt.Sprintf(" ORDER BY news.id %s", orderDirection)
+	}
+	return fmt.Sprintf(" ORDER BY %s %s, news.id %s", column, orderDirection, orderDirection)
+}
+
+func (nr *NewsRepository) Fetch(ctx context.Context, filter domain.NewsFilter) (res []domain.News, totalData int64, err error) {
+	where, args := newsFilter(filter)
+
+	// Execute the count query
+	err = nr.Conn.Reader(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM news"+where, args...).Scan(&totalData)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// only the columns of the selected fields are loaded
+	query := " FROM news" + where + newsOrderBy(filter)
+
+	// Pagination logic
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	offset := (filter.Page - 1) * filter.Limit
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, offset)
+
+	// Execute the main query with pagination
+	res, err = nr.fetchFields(ctx, filter, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return res, totalData, nil
+}
+
+// newsWithDetails selects news with their author name and topics, scanned by
+// scanNewsWithDetails
+const newsWithDetails = `SELECT news.id, news.title, news.content, news.author_id, COALESCE(author.name, ''), news.status,
+			  news.version, news.updated_at, news.created_at,
+			  COALESCE((SELECT JSON_ARRAYAGG(JSON_OBJECT('id', topic.id, 'name', topic.name))
+			            FROM news_topic JOIN topic ON topic.id = news_topic.topic_id
+			            WHERE news_topic.news_id = news.id), JSON_ARRAY())
+			  FROM news LEFT JOIN author ON author.id = news.author_id`
+
+func scanNewsWithDetails(rows *sql.Rows) (t domain.News, err error) {
+	var topics []byte
+	err = rows.Scan(
+		&t.ID,
+		&t.Title,
+		&t.Content,
+		&t.Author.ID,
+		&t.Author.Name,
+		&t.Status,
+		&t.Version,
+		&t.UpdatedAt,
+		&t.CreatedAt,
+		&topics,
+	)
+	if err != nil {
+		logrus.Error(err)
+		return t, err
+	}
+	if err = json.Unmarshal(topics, &t.Topics); err != nil {
+		return t, err
+	}
+	// JSON_ARRAYAGG can not be ordered
+	slices.SortFunc(t.Topics, func(a, b domain.TopicNews) int { return cmp.Compare(a.ID, b.ID) })
+	return t, nil
+}
+
+// Stream calls fn for every news matching filter, with the author name and
+// topics filled, without loading them all in memory. Results are paginated
+// only when filter.Limit is set.
+func (nr *NewsRepository) Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) (err error) {
+	where, args := newsFilter(filter)
+
+	orderBy := newsOrderBy(filter)
+	if orderBy == "" {
+		orderBy = " ORDER BY news.created_at, news.id"
+	}
+	query := newsWithDetails + where + orderBy
+
+	if filter.Limit > 0 {
+		if filter.Page < 1 {
+			filter.Page = 1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	}
+
+	return nr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		t, err := scanNewsWithDetails(rows)
+		if err != nil {
+			return err
+		}
+		return fn(t)
+	})
+}
+
+// Count returns the number of news matching filter
+func (nr *NewsRepository) Count(ctx context.Context, filter domain.NewsFilter) (total int64, err error) {
+	where, args := newsFilter(filter)
+	err = nr.Conn.Reader(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM news"+where, args...).Scan(&total)
+	return
+}
+
+// FetchLatestByAuthors returns up to limit of the latest news of every given
+// author, with their author name and topics, in a single query
+func (nr *NewsRepository) FetchLatestByAuthors(ctx context.Context, authorIDs []int64, limit int64) (res []domain.News, err error) {
+	placeholders, args := in(authorIDs)
+	query := newsWithDetails + ` JOIN (
+			      SELECT id, ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY created_at DESC, id DESC) AS news_rank
+			      FROM news WHERE author_id IN (` + placeholders + `)
+			  ) latest ON latest.id = news.id
+			  WHERE latest.news_rank <= ? ORDER BY news.author_id, latest.news_rank`
+
+	res = make([]domain.News, 0)
+	err = nr.eachRow(ctx, query, append(args, limit), func(rows *sql.Rows) error {
+		t, err := scanNewsWithDetails(rows)
+		if err != nil {
+			return err
+		}
+		res = append(res, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Archive counts the news matching filter per month of creation
+func (nr *NewsRepository) Archive(ctx context.Context, filter domain.NewsFilter) (res []domain.NewsArchive, err error) {
+	where, args := newsFilter(filter)
+	query := `SELECT ` + fmt.Sprintf(monthStart, "news.created_at") + ` AS period, COUNT(*), MAX(news.updated_at)
+			  FROM news` + where + ` GROUP BY period ORDER BY period`
+
+	res = make([]domain.NewsArchive, 0)
+	err = nr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		t := domain.NewsArchive{}
+		if err := rows.Scan(&t.Month, &t.Total, &t.LastModified); err != nil {
+			logrus.Error(err)
+			return err
+		}
+		res = append(res, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// maxFacetValues bounds the values counted per facet
+const maxFacetValues = 50
+
+// facetQueries select the value, label and count of every facet, from is
+// followed by the WHERE clause of the filter and groupBy
+var facetQueries = map[string]struct {
+	from    string
+	groupBy string
+}{
+	"status": {
+		from:    `SELECT news.status, '', COUNT(*) FROM news`,
+		groupBy: ` GROUP BY news.status ORDER BY COUNT(*) DESC, news.status`,
+	},
+	"topic": {
+		from: `SELECT CAST(topic.id AS CHAR), topic.name, COUNT(*) FROM news
+			  JOIN news_topic ON news_topic.news_id = news.id JOIN topic ON topic.id = news_topic.topic_id`,
+		groupBy: ` GROUP BY topic.id, topic.name ORDER BY COUNT(*) DESC, topic.id`,
+	},
+	"author": {
+		from:    `SELECT CAST(news.author_id AS CHAR), COALESCE(author.name, ''), COUNT(*) FROM news LEFT JOIN author ON author.id = news.author_id`,
+		groupBy: ` GROUP BY news.author_id, author.name ORDER BY COUNT(*) DESC, news.author_id`,
+	},
+	"month": {
+		from:    `SELECT DATE_FORMAT(news.created_at, '%Y-%m'), '', COUNT(*) FROM news`,
+		groupBy: ` GROUP BY 1 ORDER BY 1 DESC`,
+	},
+}
+
+// Facets counts the news matching filter per value of every facet with one
+// grouped query each, leaving out the condition of filter on the facet
+func (nr *NewsRepository) Facets(ctx context.Context, filter domain.NewsFilter, facets []string) (res []domain.Facet, err error) {
+	res = make([]domain.Facet, 0, len(facets))
+	for _, name := range facets {
+		q, ok := facetQueries[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown facet %q", domain.ErrBadParamInput, name)
+		}
+		where, args := newsFilter(filter.WithoutFacet(name))
+		query := q.from + where + q.groupBy + fmt.Sprintf(" LIMIT %d", maxFacetValues)
+
+		facet := domain.Facet{Name: name, Values: []domain.FacetValue{}}
+		err = nr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+			var v domain.FacetValue
+			if err := rows.Scan(&v.Value, &v.Label, &v.Count); err != nil {
+				logrus.Error(err)
+				return err
+			}
+			facet.Values = append(facet.Values, v)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, facet)
+	}
+	return res, nil
+}
+
+// statsIntervals maps the domain.StatsIntervals to the truncation of a column
+// to the start of its period
+var statsIntervals = map[string]string{"week": weekStart, "month": monthStart}
+
+// newsWords counts the words of the content of a news
+const newsWords = `CASE WHEN TRIM(REGEXP_REPLACE(news.content, '[[:space:]]+', ' ')) = '' THEN 0
+			      ELSE CHAR_LENGTH(TRIM(REGEXP_REPLACE(news.content, '[[:space:]]+', ' ')))
+			         - CHAR_LENGTH(REPLACE(TRIM(REGEXP_REPLACE(news.content, '[[:space:]]+', ' ')), ' ', '')) + 1 END`
+
+// AuthorStats returns the stats of the authors of the news matching filter,
+// the authors with the most published news first
+func (nr *NewsRepository) AuthorStats(ctx context.Context, filter domain.AuthorStatsFilter) (res []domain.AuthorStats, err error) {
+	interval, ok := statsIntervals[filter.Interval]
+	if !ok {
+		interval = monthStart
+	}
+	where, args := newsFilter(filter.NewsFilter())
+
+	// the publication of a news is the first audit entry leaving it published
+	query := `SELECT news.author_id, COALESCE(author.name, ''), COUNT(*),
+			         COUNT(CASE WHEN news.status = 'draft' THEN 1 END),
+			         COUNT(CASE WHEN news.status = 'published' THEN 1 END) AS total_published,
+			         COUNT(CASE WHEN news.status = 'deleted' THEN 1 END),
+			         COALESCE(ROUND(AVG(CASE WHEN news.status <> 'deleted' THEN ` + newsWords + ` END), 1), 0),
+			         ROUND(AVG(CASE WHEN news.status = 'published'
+			                   THEN TIMESTAMPDIFF(SECOND, news.created_at, published.published_at) / 3600 END), 2)
+			  FROM news LEFT JOIN author ON author.id = news.author_id
+			  LEFT JOIN (
+			      SELECT entity_id, MIN(created_at) AS published_at FROM audit_log
+			      WHERE entity_type = 'news' AND JSON_UNQUOTE(JSON_EXTRACT(` + "`after`" + `, '$.status')) = 'published'
+			      GROUP BY entity_id
+			  ) published ON published.entity_id = news.id` + where + `
+			  GROUP BY news.author_id, author.name
+			  ORDER BY total_published DESC, news.author_id`
+	res = make([]domain.AuthorStats, 0)
+	index := map[int64]int{}
+	err = nr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		a := domain.AuthorStats{TopTopics: []domain.AuthorTopic{}, Periods: []domain.AuthorPeriod{}}
+		var hoursToPublish sql.NullFloat64
+		if err := rows.Scan(&a.AuthorID, &a.Name, &a.Total, &a.Draft, &a.Published, &a.Deleted,
+			&a.AverageWords, &hoursToPublish); err != nil {
+			return err
+		}
+		if hoursToPublish.Valid {
+			a.AverageHoursToPublish = &hoursToPublish.Float64
+		}
+		index[a.AuthorID] = len(res)
+		res = append(res, a)
+		return nil
+	})
+	if err != nil || len(res) == 0 {
+		return res, err
+	}
+
+	query = `SELECT news.author_id, ` + fmt.Sprintf(interval, "news.created_at") + ` AS period, COUNT(*),
+			         COUNT(CASE WHEN news.status = 'published' THEN 1 END)
+			  FROM news` + where + ` GROUP BY news.author_id, period ORDER BY news.author_id, period`
+	err = nr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		var authorID int64
+		var p domain.AuthorPeriod
+		if err := rows.Scan(&authorID, &p.Start, &p.Total, &p.Published); err != nil {
+			return err
+		}
+		if i, ok := index[authorID]; ok {
+			res[i].Periods = append(res[i].Periods, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = `SELECT author_id, topic_id, name, total FROM (
+			      SELECT counted.*, ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY total DESC, topic_id) AS topic_rank
+			      FROM (
+			          SELECT news.author_id, topic.id AS topic_id, topic.name, COUNT(*) AS total
+			          FROM news JOIN news_topic ON news_topic.news_id = news.id
+			          JOIN topic ON topic.id = news_topic.topic_id` + where + `
+			          GROUP BY news.author_id, topic.id, topic.name
+			      ) counted
+			  ) ranked WHERE topic_rank <= ` + fmt.Sprint(maxTopTopics) + ` ORDER BY author_id, topic_rank`
+	err = nr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		var authorID int64
+		var t domain.AuthorTopic
+		if err := rows.Scan(&authorID, &t.ID, &t.Name, &t.Count); err != nil {
+			return err
+		}
+		if i, ok := index[authorID]; ok {
+			res[i].TopTopics = append(res[i].TopTopics, t)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// maxTopTopics is the number of top topics loaded with the stats of an author
+const maxTopTopics = 3
+
+// eachRow runs query and calls fn for every row
+func (nr *NewsRepository) eachRow(ctx context.Context, query string, args []interface{}, fn func(rows *sql.Rows) error) error {
+	rows, err := nr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		if err = fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (nr *NewsRepository) GetByID(ctx context.Context, id int64) (res domain.News, err error) {
+	query := `SELECT id, title, content, author_id, status, version, updated_at, created_at
+			  FROM news WHERE id = ?`
+
+	list, err := nr.fetch(ctx, query, id)
+	if err != nil {
+		return domain.News{}, err
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+
+	return
+}
+
+func (nr *NewsRepository) GetByTitle(ctx context.Context, title string) (res domain.News, err error) {
+	query := `SELECT id, title, content, author_id, status, version, updated_at, created_at
+			  FROM news WHERE title = ?`
+
+	list, err := nr.fetch(ctx, query, title)
+	if err != nil {
+		return
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+	return
+}
+
+func (nr *NewsRepository) Store(ctx context.Context, n *news.CreateNewsReq) (err error) {
+	query := `INSERT INTO news (title, content, author_id, status, updated_at, created_at)
+			  VALUES (?, ?, ?, ?, ?, ?)`
+	res, err := nr.Conn.Writer(ctx).ExecContext(ctx, query, n.Title, n.Content, n.AuthorID, n.Status, time.Now(), time.Now())
+	if err != nil {
+		return
+	}
+	n.ID, err = res.LastInsertId()
+	return
+}
+
+// StoreBatch inserts all news with a statement per maxBindParameters / 6 of
+// them and sets their IDs and missing timestamps, titles must be unique
+// within the batch
+func (nr *NewsRepository) StoreBatch(ctx context.Context, list []*news.CreateNewsReq) error {
+	for chunk := range slices.Chunk(list, maxBindParameters/6) {
+		if err := nr.storeBatch(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeBatch inserts a chunk of StoreBatch with a single statement
+func (nr *NewsRepository) storeBatch(ctx context.Context, list []*news.CreateNewsReq) (err error) {
+	if len(list) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO news (title, content, author_id, status, updated_at, created_at) VALUES `
+	args := make([]interface{}, 0, len(list)*6)
+	byTitle := make(map[string]*news.CreateNewsReq, len(list))
+	titles := make([]string, 0, len(list))
+	now := time.Now()
+	for i, n := range list {
+		if i > 0 {
+			query += ", "
+		}
+		query += "(?, ?, ?, ?, ?, ?)"
+
+		if n.CreatedAt.IsZero() {
+			n.CreatedAt = now
+		}
+		if n.UpdatedAt.IsZero() {
+			n.UpdatedAt = n.CreatedAt
+		}
+		args = append(args, n.Title, n.Content, n.AuthorID, n.Status, n.UpdatedAt, n.CreatedAt)
+		byTitle[n.Title] = n
+		titles = append(titles, n.Title)
+	}
+
+	conn := nr.Conn.Writer(ctx)
+	res, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	firstID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	// The IDs of a multi-row insert are only consecutive with some
+	// innodb_autoinc_lock_mode, so they are matched back by title from the
+	// first ID of the statement
+	placeholders, titleArgs := in(titles)
+	rows, err := conn.QueryContext(ctx, `SELECT id, title FROM news WHERE id >= ? AND title IN (`+placeholders+`)`,
+		append([]interface{}{firstID}, titleArgs...)...)
+	if err != nil {
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var id int64
+		var title string
+		if err = rows.Scan(&id, &title); err != nil {
+			return err
+		}
+		if n, ok := byTitle[title]; ok && n.ID == 0 {
+			n.ID = id
+		}
+	}
+	return rows.Err()
+}
+
+// ExistingTitles returns which of the given titles are already used
+func (nr *NewsRepository) ExistingTitles(ctx context.Context, titles []string) (res []string, err error) {
+	placeholders, args := in(titles)
+	query := `SELECT title FROM news WHERE title IN (` + placeholders + `)`
+
+	res = make([]string, 0)
+	err = nr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return err
+		}
+		res = append(res, title)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Delete removes the news, only at the given version unless it is 0
+func (nr *NewsRepository) Delete(ctx context.Context, id int64, version int64) (err error) {
+	query := "DELETE FROM news WHERE id = ? AND (? = 0 OR version = ?)"
+
+	stmt, err := nr.Conn.Writer(ctx).PrepareContext(ctx, query)
+	if err != nil {
+		return
+	}
+	defer func(stmt *sql.Stmt) {
+		err := stmt.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(stmt)
+
+	res, err := stmt.ExecContext(ctx, id, version, version)
+	if err != nil {
+		return
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 && version != 0 {
+		return domain.ErrPreconditionFailed
+	}
+	if rowsAffected != 1 {
+		err = fmt.Errorf("unexpected behavior: total affected rows = %d", rowsAffected)
+		return
+	}
+
+	return
+}
+
+// Update changes the fields set in cnr and increments the version of the
+// news. When cnr.Version is set the news is only changed at that version, so
+// concurrent updates can not overwrite each other.
+func (nr *NewsRepository) Update(ctx context.Context, cnr *news.UpdateNewsReq) (err error) {
+	// Ensure cnr.ID is provided
+	if cnr.ID == nil {
+		return errors.New("news ID is required for update")
+	}
+
+	// Dynamically build the update query based on which fields are set
+	query := `UPDATE news SET `
+	var args []interface{}
+	if cnr.Title != nil {
+		query += "title = ?, "
+		args = append(args, *cnr.Title)
+	}
+	if cnr.Content != nil {
+		query += "content = ?, "
+		args = append(args, *cnr.Content)
+	}
+	if cnr.AuthorID != nil {
+		query += "author_id = ?, "
+		args = append(args, *cnr.AuthorID)
+	}
+	if cnr.Status != nil {
+		query += "status = ?, "
+		args = append(args, *cnr.Status)
+	}
+
+	// Always update the updated_at timestamp and the version, which also
+	// makes MySQL count the row as affected when no field changes
+	query += "updated_at = ?, version = version + 1 WHERE id = ?"
+	args = append(args, time.Now(), *cnr.ID)
+	if cnr.Version != nil {
+		query += " AND version = ?"
+		args = append(args, *cnr.Version)
+	}
+
+	res, err := nr.Conn.Writer(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 && cnr.Version != nil {
+		return domain.ErrPreconditionFailed
+	}
+	if affected != 1 {
+		err = fmt.Errorf("unexpected behavior: total affected rows = %d", affected)
+		return
+	}
+
+	return nil
+}
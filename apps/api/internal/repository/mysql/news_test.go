@@ -0,0 +1,145 @@
+package mysql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	mysqlRepo "github.com/bxcodec/go-clean-arch/internal/repository/mysql"
+)
+
+func TestFetch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "title", "status"}).
+		AddRow(1, "title 1", "published").
+		AddRow(2, "title 2", "published")
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM news WHERE 1=1 AND news.title LIKE \\? AND news.status = \\?").
+		WithArgs("%title%", "published").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(12))
+	mock.ExpectQuery("SELECT id, title, status FROM news WHERE 1=1 AND news.title LIKE \\? AND news.status = \\? "+
+		"ORDER BY news.title DESC, news.id DESC LIMIT \\? OFFSET \\?").
+		WithArgs("%title%", "published", int64(10), int64(10)).
+		WillReturnRows(rows)
+
+	a := mysqlRepo.NewNewsRepository(repository.NewDB(db))
+	list, total, err := a.Fetch(context.TODO(), domain.NewsFilter{
+		Title: "title", Status: "published", SortBy: "title", SortOrder: "desc",
+		Page: 2, Limit: 10, Fields: []string{"title", "status"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, int64(12), total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetNewsByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	columns := []string{"id", "title", "content", "author_id", "status", "version", "updated_at", "created_at"}
+	query := "SELECT id, title, content, author_id, status, version, updated_at, created_at FROM news WHERE id = \\?"
+	mock.ExpectQuery(query).WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "title 1", "Content 1", 1, "draft", 3, time.Now(), time.Now()))
+	mock.ExpectQuery(query).WithArgs(int64(2)).WillReturnRows(sqlmock.NewRows(columns))
+
+	a := mysqlRepo.NewNewsRepository(repository.NewDB(db))
+	anNews, err := a.GetByID(context.TODO(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), anNews.Version)
+
+	_, err = a.GetByID(context.TODO(), 2)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreNews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	n := &news.CreateNewsReq{Title: "Judul", Content: "Content", AuthorID: 1, Status: domain.Draft}
+	mock.ExpectExec("INSERT INTO news \\(title, content, author_id, status, updated_at, created_at\\) VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\)").
+		WithArgs(n.Title, n.Content, n.AuthorID, n.Status, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(12, 1))
+
+	a := mysqlRepo.NewNewsRepository(repository.NewDB(db))
+	err = a.Store(context.TODO(), n)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), n.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreNewsBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	list := []*news.CreateNewsReq{
+		{Title: "First", Content: "Content", AuthorID: 1, Status: domain.Draft},
+		{Title: "Second", Content: "Content", AuthorID: 2, Status: domain.Published},
+	}
+	mock.ExpectExec("INSERT INTO news .* VALUES \\(\\?, \\?, \\?, \\?, \\?, \\?\\), \\(\\?, \\?, \\?, \\?, \\?, \\?\\)").
+		WillReturnResult(sqlmock.NewResult(7, 2))
+	// the IDs are matched back by title
+	mock.ExpectQuery("SELECT id, title FROM news WHERE id >= \\? AND title IN \\(\\?, \\?\\)").
+		WithArgs(int64(7), "First", "Second").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(9, "Second").AddRow(7, "First"))
+
+	a := mysqlRepo.NewNewsRepository(repository.NewDB(db))
+	require.NoError(t, a.StoreBatch(context.TODO(), list))
+	assert.Equal(t, int64(7), list[0].ID)
+	assert.Equal(t, int64(9), list[1].ID)
+	assert.False(t, list[0].CreatedAt.IsZero())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateNews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	id, version, title := int64(1), int64(2), "Judul"
+	query := "UPDATE news SET title = \\?, updated_at = \\?, version = version \\+ 1 WHERE id = \\? AND version = \\?"
+	mock.ExpectExec(query).WithArgs(title, sqlmock.AnyArg(), id, version).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(title, sqlmock.AnyArg(), id, version).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	a := mysqlRepo.NewNewsRepository(repository.NewDB(db))
+	req := &news.UpdateNewsReq{ID: &id, Title: &title, Version: &version}
+	assert.NoError(t, a.Update(context.TODO(), req))
+	// the news was updated meanwhile
+	assert.ErrorIs(t, a.Update(context.TODO(), req), domain.ErrPreconditionFailed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteNews(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	query := "DELETE FROM news WHERE id = \\? AND \\(\\? = 0 OR version = \\?\\)"
+	prep := mock.ExpectPrepare(query)
+	prep.ExpectExec().WithArgs(int64(12), int64(0), int64(0)).WillReturnResult(sqlmock.NewResult(12, 1))
+
+	a := mysqlRepo.NewNewsRepository(repository.NewDB(db))
+	err = a.Delete(context.TODO(), 12, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
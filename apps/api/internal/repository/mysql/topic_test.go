@@ -0,0 +1,78 @@
+package mysql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	mysqlRepo "github.com/bxcodec/go-clean-arch/internal/repository/mysql"
+)
+
+func TestFetchTopicsWithStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM topic WHERE 1=1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	// the topics without published news go last
+	mock.ExpectQuery("FROM topic LEFT JOIN .* ORDER BY last_published_at IS NULL, last_published_at DESC, id LIMIT \\? OFFSET \\?").
+		WithArgs(int64(10), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "updated_at", "created_at", "published", "draft", "last_published_at"}).
+			AddRow(1, "Health", 1, time.Now(), time.Now(), 2, 1, time.Now()).
+			AddRow(2, "Travel", 1, time.Now(), time.Now(), 0, 0, nil))
+	mock.ExpectQuery("WHERE news_topic.topic_id IN \\(\\?, \\?\\) AND news.status = 'published'").
+		WithArgs(int64(1), int64(2), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"topic_id", "author_id", "name", "total"}).AddRow(1, 1, "Doni", 2))
+
+	tr := mysqlRepo.NewTopicRepository(repository.NewDB(db))
+	list, total, err := tr.Fetch(context.TODO(), domain.TopicFilter{
+		Page: 1, Limit: 10, WithStats: true, SortBy: "last_published_at", SortOrder: "desc",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, list, 2)
+	assert.Equal(t, []domain.TopicAuthor{{ID: 1, Name: "Doni", Count: 2}}, list[0].Stats.TopAuthors)
+	assert.NotNil(t, list[0].Stats.LastPublishedAt)
+	assert.Empty(t, list[1].Stats.TopAuthors)
+	assert.Nil(t, list[1].Stats.LastPublishedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTopicStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectQuery("FROM topic LEFT JOIN .* WHERE id = \\?").WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "version", "updated_at", "created_at", "published", "draft", "last_published_at"}).
+			AddRow(1, "Health", 1, time.Now(), time.Now(), 1, 0, time.Now()))
+	mock.ExpectQuery("WHERE news_topic.topic_id IN \\(\\?\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"topic_id", "author_id", "name", "total"}))
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	thisWeek := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	mock.ExpectQuery("GROUP BY week").
+		WithArgs(thisWeek.AddDate(0, 0, -14), thisWeek.AddDate(0, 0, 7), int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"week", "published", "draft"}).AddRow(thisWeek.AddDate(0, 0, -7), 1, 0))
+
+	tr := mysqlRepo.NewTopicRepository(repository.NewDB(db))
+	activity, err := tr.Stats(context.TODO(), 1, 3)
+	require.NoError(t, err)
+	// the weeks without news are filled, oldest first
+	assert.Equal(t, []domain.TopicWeek{
+		{Week: thisWeek.AddDate(0, 0, -14)},
+		{Week: thisWeek.AddDate(0, 0, -7), Published: 1},
+		{Week: thisWeek},
+	}, activity.Weeks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
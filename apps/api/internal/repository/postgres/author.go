@@ -3,23 +3,27 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 
 	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
 )
 
 type AuthorRepository struct {
-	DB *sql.DB
+	DB *repository.DB
 }
 
 // NewAuthorRepository will create an implementation of author.Repository
-func NewAuthorRepository(db *sql.DB) *AuthorRepository {
+func NewAuthorRepository(db *repository.DB) *AuthorRepository {
 	return &AuthorRepository{
 		DB: db,
 	}
 }
 
 func (m *AuthorRepository) getOne(ctx context.Context, query string, args ...interface{}) (res domain.Author, err error) {
-	stmt, err := m.DB.PrepareContext(ctx, query)
+	stmt, err := m.DB.Reader(ctx).PrepareContext(ctx, query)
 	if err != nil {
 		return domain.Author{}, err
 	}
@@ -32,6 +36,9 @@ func (m *AuthorRepository) getOne(ctx context.Context, query string, args ...int
 		&res.CreatedAt,
 		&res.UpdatedAt,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Author{}, domain.ErrNotFound
+	}
 	return
 }
 
@@ -39,3 +46,33 @@ func (m *AuthorRepository) GetByID(ctx context.Context, id int64) (domain.Author
 	query := `SELECT id, name, created_at, updated_at FROM author WHERE id=$1`
 	return m.getOne(ctx, query, id)
 }
+
+func (m *AuthorRepository) GetByName(ctx context.Context, name string) (domain.Author, error) {
+	query := `SELECT id, name, created_at, updated_at FROM author WHERE name=$1`
+	return m.getOne(ctx, query, name)
+}
+
+// GetByIDs returns the authors with the given IDs, unknown IDs are ignored
+func (m *AuthorRepository) GetByIDs(ctx context.Context, ids []int64) (res []domain.Author, err error) {
+	query := `SELECT id, name, created_at, updated_at FROM author WHERE id = ANY($1)`
+	rows, err := m.DB.Reader(ctx).QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	res = make([]domain.Author, 0, len(ids))
+	for rows.Next() {
+		a := domain.Author{}
+		if err = rows.Scan(&a.ID, &a.Name, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
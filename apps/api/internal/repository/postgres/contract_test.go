@@ -0,0 +1,44 @@
+package postgres_test
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	postgresRepo "github.com/bxcodec/go-clean-arch/internal/repository/postgres"
+	"github.com/bxcodec/go-clean-arch/internal/repository/repositorytest"
+)
+
+// TestContract runs the repository contract against the database of
+// TEST_POSTGRES_URL, created with init_postgres.sql, whose data it deletes
+func TestContract(t *testing.T) {
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+	conn, err := sql.Open("postgres", url)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		_, err := conn.Exec(`TRUNCATE ` + strings.Join(repositorytest.Tables, ", ") + ` RESTART IDENTITY CASCADE`)
+		require.NoError(t, err)
+		_, err = conn.Exec(repositorytest.SeedAuthors)
+		require.NoError(t, err)
+
+		db := repository.NewDB(conn)
+		return repositorytest.Repositories{
+			News:       postgresRepo.NewNewsRepository(db),
+			Authors:    postgresRepo.NewAuthorRepository(db),
+			Topics:     postgresRepo.NewTopicRepository(db),
+			NewsTopics: postgresRepo.NewNewsTopicRepository(db),
+			Audit:      postgresRepo.NewAuditRepository(db),
+			Transactor: repository.NewTransactor(conn),
+		}
+	})
+}
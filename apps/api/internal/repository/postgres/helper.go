@@ -0,0 +1,4 @@
+package postgres
+
+// maxBindParameters is the number of parameters Postgres binds per statement
+const maxBindParameters = 65535
@@ -4,23 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
 )
 
 type NewsTopicRepository struct {
-	Conn *sql.DB
+	Conn *repository.DB
 }
 
 // NewNewsTopicRepository will create an object that represent the newsTopic.Repository interface
-func NewNewsTopicRepository(conn *sql.DB) *NewsTopicRepository {
+func NewNewsTopicRepository(conn *repository.DB) *NewsTopicRepository {
 	return &NewsTopicRepository{conn}
 }
 
 func (ntr *NewsTopicRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.NewsTopic, err error) {
-	rows, err := ntr.Conn.QueryContext(ctx, query, args...)
+	rows, err := ntr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		logrus.Error(err)
 		return nil, err
@@ -83,14 +85,46 @@ func (ntr *NewsTopicRepository) GetByTopicID(ctx context.Context, topicId int64)
 func (ntr *NewsTopicRepository) Store(ctx context.Context, nt *domain.NewsTopic) (err error) {
 	query := `INSERT INTO news_topic (news_id, topic_id)
 			  VALUES ($1, $2) RETURNING news_id, topic_id`
-	err = ntr.Conn.QueryRowContext(ctx, query, nt.NewsID, nt.TopicID).Scan(&nt.NewsID, &nt.TopicID)
+	err = ntr.Conn.Writer(ctx).QueryRowContext(ctx, query, nt.NewsID, nt.TopicID).Scan(&nt.NewsID, &nt.TopicID)
+	return
+}
+
+// StoreBatch inserts all the relations with a statement per
+// maxBindParameters / 2 of them
+func (ntr *NewsTopicRepository) StoreBatch(ctx context.Context, list []domain.NewsTopic) error {
+	for chunk := range slices.Chunk(list, maxBindParameters/2) {
+		if err := ntr.storeBatch(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeBatch inserts a chunk of StoreBatch with a single statement
+func (ntr *NewsTopicRepository) storeBatch(ctx context.Context, list []domain.NewsTopic) (err error) {
+	if len(list) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO news_topic (news_id, topic_id) VALUES `
+	args := make([]interface{}, 0, len(list)*2)
+	for i, nt := range list {
+		if i > 0 {
+			query += ", "
+		}
+		query += fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2)
+		args = append(args, nt.NewsID, nt.TopicID)
+	}
+	query += " ON CONFLICT (news_id, topic_id) DO NOTHING"
+
+	_, err = ntr.Conn.Writer(ctx).ExecContext(ctx, query, args...)
 	return
 }
 
 func (ntr *NewsTopicRepository) Delete(ctx context.Context, newsId int64, topicId int64) (err error) {
 	query := "DELETE FROM news_topic WHERE news_id = $1 AND topic_id = $2"
 
-	stmt, err := ntr.Conn.PrepareContext(ctx, query)
+	stmt, err := ntr.Conn.Writer(ctx).PrepareContext(ctx, query)
 	if err != nil {
 		return
 	}
@@ -116,7 +150,7 @@ func (ntr *NewsTopicRepository) Delete(ctx context.Context, newsId int64, topicI
 func (ntr *NewsTopicRepository) DeleteByNewsID(ctx context.Context, newsId int64) (err error) {
 	query := "DELETE FROM news_topic WHERE news_id = $1"
 
-	stmt, err := ntr.Conn.PrepareContext(ctx, query)
+	stmt, err := ntr.Conn.Writer(ctx).PrepareContext(ctx, query)
 	if err != nil {
 		return
 	}
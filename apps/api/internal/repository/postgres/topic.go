@@ -8,19 +8,21 @@ import (
 	"time"
 
 	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/lib/pq"
 )
 
 type TopicRepository struct {
-	Conn *sql.DB
+	Conn *repository.DB
 }
 
 // NewTopicRepository will create an object that represent the topic.Repository interface
-func NewTopicRepository(conn *sql.DB) *TopicRepository {
+func NewTopicRepository(conn *repository.DB) *TopicRepository {
 	return &TopicRepository{conn}
 }
 
 func (tr *TopicRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.Topic, err error) {
-	rows, err := tr.Conn.QueryContext(ctx, query, args...)
+	rows, err := tr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		logrus.Error(err)
 		return nil, err
@@ -38,6 +40,7 @@ func (tr *TopicRepository) fetch(ctx context.Context, query string, args ...inte
 		err = rows.Scan(
 			&t.ID,
 			&t.Name,
+			&t.Version,
 			&t.UpdatedAt,
 			&t.CreatedAt,
 		)
@@ -50,41 +53,182 @@ func (tr *TopicRepository) fetch(ctx context.Context, query string, args ...inte
 	return result, nil
 }
 
-func (tr *TopicRepository) Fetch(ctx context.Context, filter domain.TopicFilter) (res []domain.Topic, totalData int64, err error) {
-	query := `SELECT id, name, updated_at, created_at
-			  FROM topic WHERE 1=1`
-	countQuery := "SELECT COUNT(*) FROM topic WHERE 1=1"
+// topicSortColumns lists the columns topics can be sorted by
+var topicSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
 
-	var args []interface{}
+// topicFilter builds the WHERE clause matching filter, its arguments start at $1
+func topicFilter(filter domain.TopicFilter) (where string, args []interface{}) {
+	where = " WHERE 1=1"
 	argIndex := 1 // Start index for query parameters
 
 	// Add conditions based on optional filters
 	if filter.ID != 0 {
-		query += fmt.Sprintf(" AND id = $%d", argIndex)
-		countQuery += fmt.Sprintf(" AND id = $%d", argIndex)
+		where += fmt.Sprintf(" AND id = $%d", argIndex)
 		args = append(args, filter.ID)
 		argIndex++
 	}
 	if filter.Name != "" {
-		query += fmt.Sprintf(" AND name ILIKE $%d", argIndex)
-		countQuery += fmt.Sprintf(" AND name ILIKE $%d", argIndex)
-		args = append(args, fmt.Sprintf("%%%s%%", filter.Name)) // Add wildcards
-		argIndex++
+		where += fmt.Sprintf(" AND name ILIKE $%d", argIndex)
+		args = append(args, repository.ContainsPattern(filter.Name))
+	}
+	return where, args
+}
+
+// topicStatsSortColumns lists the stats topics can be sorted by when they
+// are loaded, topics with the same stats are sorted by id
+var topicStatsSortColumns = map[string]string{
+	"published":         "published",
+	"draft":             "draft",
+	"last_published_at": "last_published_at",
+}
+
+// topicOrderBy builds the ORDER BY clause, unknown columns are ignored
+func topicOrderBy(filter domain.TopicFilter) string {
+	orderDirection := "ASC" // default to ascending
+	if filter.SortOrder == "desc" {
+		orderDirection = "DESC"
+	}
+	if column, ok := topicStatsSortColumns[filter.SortBy]; ok && filter.WithStats {
+		return fmt.Sprintf(" ORDER BY %s %s NULLS LAST, id", column, orderDirection)
 	}
+	column, ok := topicSortColumns[filter.SortBy]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" ORDER BY %s %s", column, orderDirection)
+}
+
+// topicWithStats selects the topics with the counts of their news, scanned
+// by fetchWithStats
+const topicWithStats = `SELECT id, name, version, updated_at, created_at,
+			  COALESCE(stats.published, 0) AS published, COALESCE(stats.draft, 0) AS draft, stats.last_published_at
+			  FROM topic LEFT JOIN (
+			      SELECT news_topic.topic_id,
+			             COUNT(*) FILTER (WHERE news.status = 'published') AS published,
+			             COUNT(*) FILTER (WHERE news.status = 'draft') AS draft,
+			             MAX(news.created_at) FILTER (WHERE news.status = 'published') AS last_published_at
+			      FROM news_topic JOIN news ON news.id = news_topic.news_id
+			      GROUP BY news_topic.topic_id
+			  ) stats ON stats.topic_id = topic.id`
+
+// maxTopAuthors is the number of top authors loaded with the stats of a topic
+const maxTopAuthors = 3
+
+// fetchWithStats runs a topicWithStats query and loads the top authors of
+// the topics
+func (tr *TopicRepository) fetchWithStats(ctx context.Context, query string, args ...interface{}) (result []domain.Topic, err error) {
+	rows, err := tr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	result = make([]domain.Topic, 0)
+	ids := make([]int64, 0)
+	for rows.Next() {
+		t := domain.Topic{Stats: &domain.TopicStats{TopAuthors: []domain.TopicAuthor{}}}
+		var lastPublishedAt sql.NullTime
+		err = rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.Version,
+			&t.UpdatedAt,
+			&t.CreatedAt,
+			&t.Stats.Published,
+			&t.Stats.Draft,
+			&lastPublishedAt,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return nil, err
+		}
+		if lastPublishedAt.Valid {
+			t.Stats.LastPublishedAt = &lastPublishedAt.Time
+		}
+		result = append(result, t)
+		ids = append(ids, t.ID)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	authors, err := tr.topAuthors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		if top, ok := authors[result[i].ID]; ok {
+			result[i].Stats.TopAuthors = top
+		}
+	}
+	return result, nil
+}
+
+// topAuthors returns the authors with the most published news of every given
+// topic, in a single query
+func (tr *TopicRepository) topAuthors(ctx context.Context, ids []int64) (res map[int64][]domain.TopicAuthor, err error) {
+	res = make(map[int64][]domain.TopicAuthor, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	query := `SELECT topic_id, author_id, name, total FROM (
+			      SELECT news_topic.topic_id, news.author_id, COALESCE(author.name, '') AS name, COUNT(*) AS total,
+			             ROW_NUMBER() OVER (PARTITION BY news_topic.topic_id ORDER BY COUNT(*) DESC, news.author_id) AS rank
+			      FROM news_topic JOIN news ON news.id = news_topic.news_id
+			      LEFT JOIN author ON author.id = news.author_id
+			      WHERE news_topic.topic_id = ANY($1) AND news.status = 'published'
+			      GROUP BY news_topic.topic_id, news.author_id, author.name
+			  ) ranked WHERE rank <= $2 ORDER BY topic_id, rank`
+	rows, err := tr.Conn.Reader(ctx).QueryContext(ctx, query, pq.Array(ids), maxTopAuthors)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var topicID int64
+		var a domain.TopicAuthor
+		if err = rows.Scan(&topicID, &a.ID, &a.Name, &a.Count); err != nil {
+			return nil, err
+		}
+		res[topicID] = append(res[topicID], a)
+	}
+	return res, rows.Err()
+}
+
+func (tr *TopicRepository) Fetch(ctx context.Context, filter domain.TopicFilter) (res []domain.Topic, totalData int64, err error) {
+	where, args := topicFilter(filter)
 
 	// Execute the count query
-	err = tr.Conn.QueryRowContext(ctx, countQuery, args...).Scan(&totalData)
+	err = tr.Conn.Reader(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM topic"+where, args...).Scan(&totalData)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Sorting logic
-	if filter.SortBy != "" {
-		orderDirection := "ASC" // default to ascending
-		if filter.SortOrder == "desc" {
-			orderDirection = "DESC"
-		}
-		query += fmt.Sprintf(" ORDER BY %s %s", filter.SortBy, orderDirection)
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic` + where + topicOrderBy(filter)
+	fetch := tr.fetch
+	if filter.WithStats {
+		query = topicWithStats + where + topicOrderBy(filter)
+		fetch = tr.fetchWithStats
 	}
 
 	// Pagination logic
@@ -92,11 +236,11 @@ func (tr *TopicRepository) Fetch(ctx context.Context, filter domain.TopicFilter)
 		filter.Page = 1
 	}
 	offset := (filter.Page - 1) * filter.Limit
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
 	args = append(args, filter.Limit, offset)
 
 	// Execute the main query with pagination
-	res, err = tr.fetch(ctx, query, args...)
+	res, err = fetch(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -104,8 +248,60 @@ func (tr *TopicRepository) Fetch(ctx context.Context, filter domain.TopicFilter)
 	return res, totalData, nil
 }
 
+// Stream calls fn for every topic matching filter without loading them all
+// in memory. Results are paginated only when filter.Limit is set.
+func (tr *TopicRepository) Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) (err error) {
+	where, args := topicFilter(filter)
+
+	orderBy := topicOrderBy(filter)
+	if orderBy == "" {
+		orderBy = " ORDER BY id"
+	}
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic` + where + orderBy
+
+	if filter.Limit > 0 {
+		if filter.Page < 1 {
+			filter.Page = 1
+		}
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+		args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	}
+
+	rows, err := tr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		t := domain.Topic{}
+		err = rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.Version,
+			&t.UpdatedAt,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return err
+		}
+		if err = fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 func (tr *TopicRepository) GetByID(ctx context.Context, id int64) (res domain.Topic, err error) {
-	query := `SELECT id, name, updated_at, created_at
+	query := `SELECT id, name, version, updated_at, created_at
 			  FROM topic WHERE id = $1`
 
 	list, err := tr.fetch(ctx, query, id)
@@ -140,8 +336,85 @@ func (tr *TopicRepository) GetByNewsID(ctx context.Context, id int64) (res domai
 	return
 }
 
+// GetByIDs returns the topics with the given IDs, unknown IDs are ignored
+func (tr *TopicRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Topic, error) {
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic WHERE id = ANY($1)`
+	return tr.fetch(ctx, query, pq.Array(ids))
+}
+
+// CountNews returns the number of news of every given topic, topics without
+// news are missing from the result
+func (tr *TopicRepository) CountNews(ctx context.Context, ids []int64) (res map[int64]int64, err error) {
+	query := `SELECT topic_id, COUNT(*) FROM news_topic WHERE topic_id = ANY($1) GROUP BY topic_id`
+	rows, err := tr.Conn.Reader(ctx).QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	res = make(map[int64]int64, len(ids))
+	for rows.Next() {
+		var topicID, total int64
+		if err = rows.Scan(&topicID, &total); err != nil {
+			return nil, err
+		}
+		res[topicID] = total
+	}
+	return res, rows.Err()
+}
+
+// Stats returns the stats of a topic with the counts of its news created
+// during each of the given number of weeks up to now, weeks without news
+// included
+func (tr *TopicRepository) Stats(ctx context.Context, id int64, weeks int64) (res domain.TopicActivity, err error) {
+	topics, err := tr.fetchWithStats(ctx, topicWithStats+" WHERE id = $1", id)
+	if err != nil {
+		return res, err
+	}
+	if len(topics) == 0 {
+		return res, domain.ErrNotFound
+	}
+	res = domain.TopicActivity{TopicID: id, TopicStats: *topics[0].Stats, Weeks: make([]domain.TopicWeek, 0, weeks)}
+
+	query := `SELECT weeks.week,
+			         COUNT(news.id) FILTER (WHERE news.status = 'published'),
+			         COUNT(news.id) FILTER (WHERE news.status = 'draft')
+			  FROM generate_series(date_trunc('week', $2::timestamp) - ($3::int - 1) * interval '1 week',
+			                       date_trunc('week', $2::timestamp), interval '1 week') AS weeks(week)
+			  LEFT JOIN news ON date_trunc('week', news.created_at) = weeks.week
+			              AND news.id IN (SELECT news_id FROM news_topic WHERE topic_id = $1)
+			  GROUP BY weeks.week ORDER BY weeks.week`
+	rows, err := tr.Conn.Reader(ctx).QueryContext(ctx, query, id, time.Now(), weeks)
+	if err != nil {
+		logrus.Error(err)
+		return res, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		var w domain.TopicWeek
+		if err = rows.Scan(&w.Week, &w.Published, &w.Draft); err != nil {
+			return res, err
+		}
+		res.Weeks = append(res.Weeks, w)
+	}
+	return res, rows.Err()
+}
+
 func (tr *TopicRepository) GetByName(ctx context.Context, name string) (res domain.Topic, err error) {
-	query := `SELECT id, name, updated_at, created_at
+	query := `SELECT id, name, version, updated_at, created_at
 			  FROM topic WHERE name = $1`
 
 	list, err := tr.fetch(ctx, query, name)
@@ -161,19 +434,20 @@ func (tr *TopicRepository) GetByName(ctx context.Context, name string) (res doma
 func (tr *TopicRepository) Store(ctx context.Context, a *domain.Topic) (err error) {
 	query := `INSERT INTO topic (name, updated_at, created_at)
 			  VALUES ($1, $2, $3) RETURNING id`
-	err = tr.Conn.QueryRowContext(ctx, query, a.Name, time.Now(), time.Now()).Scan(&a.ID)
+	err = tr.Conn.Writer(ctx).QueryRowContext(ctx, query, a.Name, time.Now(), time.Now()).Scan(&a.ID)
 	return
 }
 
-func (tr *TopicRepository) Delete(ctx context.Context, id int64) (err error) {
-	query := "DELETE FROM topic WHERE id = $1"
+// Delete removes the topic, only at the given version unless it is 0
+func (tr *TopicRepository) Delete(ctx context.Context, id int64, version int64) (err error) {
+	query := "DELETE FROM topic WHERE id = $1 AND ($2 = 0 OR version = $2)"
 
-	stmt, err := tr.Conn.PrepareContext(ctx, query)
+	stmt, err := tr.Conn.Writer(ctx).PrepareContext(ctx, query)
 	if err != nil {
 		return
 	}
 
-	res, err := stmt.ExecContext(ctx, id)
+	res, err := stmt.ExecContext(ctx, id, version)
 	if err != nil {
 		return
 	}
@@ -183,6 +457,9 @@ func (tr *TopicRepository) Delete(ctx context.Context, id int64) (err error) {
 		return
 	}
 
+	if rowsAffected == 0 && version != 0 {
+		return domain.ErrPreconditionFailed
+	}
 	if rowsAffected != 1 {
 		err = fmt.Errorf("unexpected behavior: total affected rows = %d", rowsAffected)
 		return
@@ -191,15 +468,18 @@ func (tr *TopicRepository) Delete(ctx context.Context, id int64) (err error) {
 	return
 }
 
+// Update renames the topic and increments its version, only at to.Version
+// unless it is 0
 func (tr *TopicRepository) Update(ctx context.Context, to *domain.Topic) (err error) {
-	query := `UPDATE topic SET name=$1, updated_at=$2 WHERE id = $3`
+	query := `UPDATE topic SET name=$1, updated_at=$2, version = version + 1
+			  WHERE id = $3 AND ($4 = 0 OR version = $4)`
 
-	stmt, err := tr.Conn.PrepareContext(ctx, query)
+	stmt, err := tr.Conn.Writer(ctx).PrepareContext(ctx, query)
 	if err != nil {
 		return
 	}
 
-	res, err := stmt.ExecContext(ctx, to.Name, to.UpdatedAt, to.ID)
+	res, err := stmt.ExecContext(ctx, to.Name, time.Now(), to.ID, to.Version)
 	if err != nil {
 		return
 	}
@@ -207,6 +487,9 @@ func (tr *TopicRepository) Update(ctx context.Context, to *domain.Topic) (err er
 	if err != nil {
 		return
 	}
+	if affected == 0 && to.Version != 0 {
+		return domain.ErrPreconditionFailed
+	}
 	if affected != 1 {
 		err = fmt.Errorf("unexpected behavior: total affected rows = %d", affected)
 		return
@@ -0,0 +1,634 @@
+// Package repositorytest is the contract of the repositories, which every
+// database backend runs in its tests so that they behave the same.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/audit"
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	newsService "github.com/bxcodec/go-clean-arch/news"
+	"github.com/bxcodec/go-clean-arch/topic"
+)
+
+// Tables are the tables of the repositories, the relations first
+var Tables = []string{"audit_log", "news_topic", "news", "topic", "author"}
+
+// SeedAuthors inserts the authors the contract expects, which have no
+// repository to create them
+const SeedAuthors = `INSERT INTO author (id, name) VALUES (1, 'Doni'), (2, 'Deni')`
+
+// Repositories are the repositories of a backend
+type Repositories struct {
+	News       newsService.NewsRepository
+	Authors    newsService.AuthorRepository
+	Topics     topic.TopicRepository
+	NewsTopics newsService.NewsTopicRepository
+	Audit      AuditRepository
+	Transactor newsService.Transactor
+	// SingleWriter skips the writes made outside of a running transaction,
+	// for the databases like SQLite whose transactions take their only write
+	// lock when they begin: such a write would wait for the transaction, which
+	// waits for it
+	SingleWriter bool
+}
+
+// AuditRepository is the audit log repository of the news and audit services
+type AuditRepository interface {
+	newsService.AuditRepository
+	audit.AuditRepository
+}
+
+// Run runs the contract against the repositories returned by open for every
+// test, on empty Tables but for the SeedAuthors
+func Run(t *testing.T, open func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r Repositories)
+	}{
+		{"News", testNews},
+		{"NewsBatch", testNewsBatch},
+		{"LargeBatches", testLargeBatches},
+		{"NewsQueries", testNewsQueries},
+		{"Topics", testTopics},
+		{"TopicStats", testTopicStats},
+		{"NewsTopics", testNewsTopics},
+		{"Authors", testAuthors},
+		{"Audit", testAudit},
+		{"Transactions", testTransactions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, open(t))
+		})
+	}
+}
+
+func storeNews(t *testing.T, r Repositories, title string, authorID int64, status domain.NewsStatus, content string) int64 {
+	t.Helper()
+	n := &news.CreateNewsReq{Title: title, Content: content, AuthorID: authorID, Status: status}
+	require.NoError(t, r.News.Store(context.Background(), n))
+	require.NotZero(t, n.ID)
+	return n.ID
+}
+
+func storeTopic(t *testing.T, r Repositories, name string) int64 {
+	t.Helper()
+	to := &domain.Topic{Name: name}
+	require.NoError(t, r.Topics.Store(context.Background(), to))
+	require.NotZero(t, to.ID)
+	return to.ID
+}
+
+func ids(list []domain.News) []int64 {
+	res := make([]int64, len(list))
+	for i, n := range list {
+		res[i] = n.ID
+	}
+	return res
+}
+
+func testNews(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	id := storeNews(t, r, "Health Benefits", 1, domain.Published, "Eat well")
+
+	got, err := r.News.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Health Benefits", got.Title)
+	assert.Equal(t, "Eat well", got.Content)
+	assert.Equal(t, int64(1), got.Author.ID)
+	assert.Equal(t, domain.Published, got.Status)
+	assert.Equal(t, int64(1), got.Version)
+	assert.False(t, got.CreatedAt.IsZero())
+
+	got, err = r.News.GetByTitle(ctx, "Health Benefits")
+	require.NoError(t, err)
+	assert.Equal(t, id, got.ID)
+	_, err = r.News.GetByID(ctx, id+100)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = r.News.GetByTitle(ctx, "Unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// updates change the given fields at the given version
+	title, version := "Health Benefits of Sleep", int64(1)
+	require.NoError(t, r.News.Update(ctx, &news.UpdateNewsReq{ID: &id, Title: &title, Version: &version}))
+	got, err = r.News.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, title, got.Title)
+	assert.Equal(t, "Eat well", got.Content)
+	assert.Equal(t, int64(2), got.Version)
+	err = r.News.Update(ctx, &news.UpdateNewsReq{ID: &id, Title: &title, Version: &version})
+	assert.ErrorIs(t, err, domain.ErrPreconditionFailed)
+	status := domain.Draft
+	require.NoError(t, r.News.Update(ctx, &news.UpdateNewsReq{ID: &id, Status: &status}))
+	got, err = r.News.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Draft, got.Status)
+	assert.Equal(t, int64(3), got.Version)
+
+	assert.ErrorIs(t, r.News.Delete(ctx, id, 1), domain.ErrPreconditionFailed)
+	require.NoError(t, r.News.Delete(ctx, id, 3))
+	_, err = r.News.GetByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testNewsBatch(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	createdAt := time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)
+	list := []*news.CreateNewsReq{
+		{Title: "Health Benefits", Content: "Eat well", AuthorID: 1, Status: domain.Published, CreatedAt: createdAt},
+		{Title: "AI and the Future of Work", Content: "Machines learn", AuthorID: 2, Status: domain.Draft},
+		{Title: "Travel Destinations", Content: "Pack light", AuthorID: 1, Status: domain.Draft},
+	}
+	require.NoError(t, r.News.StoreBatch(ctx, list))
+	require.NoError(t, r.News.StoreBatch(ctx, nil))
+
+	for _, n := range list {
+		require.NotZero(t, n.ID)
+		got, err := r.News.GetByID(ctx, n.ID)
+		require.NoError(t, err)
+		assert.Equal(t, n.Title, got.Title)
+	}
+	got, err := r.News.GetByID(ctx, list[0].ID)
+	require.NoError(t, err)
+	assert.True(t, createdAt.Equal(got.CreatedAt), "created at %s", got.CreatedAt)
+	assert.False(t, list[1].CreatedAt.IsZero())
+
+	titles, err := r.News.ExistingTitles(ctx, []string{"Health Benefits", "Travel Destinations", "Unknown"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Health Benefits", "Travel Destinations"}, titles)
+}
+
+// testLargeBatches stores batches binding more parameters than any database
+// accepts in a statement, 36000 each
+func testLargeBatches(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	const size = 6000
+	list := make([]*news.CreateNewsReq, size)
+	for i := range list {
+		list[i] = &news.CreateNewsReq{Title: "News " + strconv.Itoa(i), Content: "Content", AuthorID: 1, Status: domain.Draft}
+	}
+	topics := []int64{storeTopic(t, r, "Health"), storeTopic(t, r, "Technology"), storeTopic(t, r, "Travel")}
+
+	err := r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.News.StoreBatch(ctx, list); err != nil {
+			return err
+		}
+		relations := make([]domain.NewsTopic, 0, len(list)*len(topics))
+		entries := make([]*domain.AuditLog, 0, len(list)*2/3)
+		for i, n := range list {
+			for _, topicID := range topics {
+				relations = append(relations, domain.NewsTopic{NewsID: n.ID, TopicID: topicID})
+			}
+			if i%3 != 0 {
+				entries = append(entries, &domain.AuditLog{Actor: "alice", Action: domain.AuditCreate,
+					EntityType: domain.AuditEntityNews, EntityID: n.ID, After: []byte(`{}`)})
+			}
+		}
+		if err := r.NewsTopics.StoreBatch(ctx, relations); err != nil {
+			return err
+		}
+		return r.Audit.StoreBatch(ctx, entries)
+	})
+	require.NoError(t, err)
+
+	ids := make(map[int64]bool, size)
+	for _, n := range list {
+		ids[n.ID] = true
+	}
+	assert.Len(t, ids, size)
+	assert.False(t, ids[0])
+
+	count, err := r.News.Count(ctx, domain.NewsFilter{TopicID: topics[2]})
+	require.NoError(t, err)
+	assert.Equal(t, int64(size), count)
+	_, total, err := r.Audit.Fetch(ctx, domain.AuditFilter{Limit: 1, Page: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(size*2/3), total)
+}
+
+// newsFixture stores four news in two months with their topics, and the
+// publication of the first one in the audit log
+type newsFixture struct {
+	health, ai, travel, climate int64
+	healthTopic, techTopic      int64
+}
+
+func storeNewsFixture(t *testing.T, r Repositories) newsFixture {
+	ctx := context.Background()
+	list := []*news.CreateNewsReq{
+		{Title: "Health Benefits", Content: "Eat well", AuthorID: 1, Status: domain.Published,
+			CreatedAt: time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)},
+		{Title: "AI and the Future of Work", Content: "Machines learn fast", AuthorID: 2, Status: domain.Published,
+			CreatedAt: time.Date(2024, 10, 27, 14, 0, 0, 0, time.UTC)},
+		{Title: "Travel Destinations", Content: "Pack light\nand go", AuthorID: 1, Status: domain.Draft,
+			CreatedAt: time.Date(2024, 9, 26, 11, 0, 0, 0, time.UTC)},
+		{Title: "Climate Change", Content: "Act now", AuthorID: 2, Status: domain.Deleted,
+			CreatedAt: time.Date(2024, 9, 25, 8, 0, 0, 0, time.UTC)},
+	}
+	require.NoError(t, r.News.StoreBatch(ctx, list))
+	f := newsFixture{health: list[0].ID, ai: list[1].ID, travel: list[2].ID, climate: list[3].ID}
+
+	f.healthTopic = storeTopic(t, r, "Health")
+	f.techTopic = storeTopic(t, r, "Technology")
+	require.NoError(t, r.NewsTopics.StoreBatch(ctx, []domain.NewsTopic{
+		{NewsID: f.health, TopicID: f.healthTopic},
+		{NewsID: f.ai, TopicID: f.techTopic},
+		{NewsID: f.travel, TopicID: f.healthTopic},
+	}))
+
+	entry := &domain.AuditLog{Actor: "alice", Action: domain.AuditUpdate, EntityType: domain.AuditEntityNews,
+		EntityID: f.health, Before: []byte(`{"status":"draft"}`), After: []byte(`{"status":"published"}`)}
+	require.NoError(t, r.Audit.Store(ctx, entry))
+	return f
+}
+
+func testNewsQueries(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	f := storeNewsFixture(t, r)
+
+	fetch := func(filter domain.NewsFilter) ([]int64, int64) {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = 10
+		}
+		list, total, err := r.News.Fetch(ctx, filter)
+		require.NoError(t, err)
+		return ids(list), total
+	}
+
+	// titles match case insensitively
+	list, total := fetch(domain.NewsFilter{Title: "health"})
+	assert.Equal(t, []int64{f.health}, list)
+	assert.Equal(t, int64(1), total)
+	list, _ = fetch(domain.NewsFilter{Status: "published", SortBy: "title"})
+	assert.Equal(t, []int64{f.ai, f.health}, list)
+	list, _ = fetch(domain.NewsFilter{AuthorID: 1, SortBy: "created_at", SortOrder: "desc"})
+	assert.Equal(t, []int64{f.health, f.travel}, list)
+	list, _ = fetch(domain.NewsFilter{TopicID: f.healthTopic, SortBy: "id"})
+	assert.Equal(t, []int64{f.health, f.travel}, list)
+	list, _ = fetch(domain.NewsFilter{StartDate: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), SortBy: "id"})
+	assert.Equal(t, []int64{f.health, f.ai}, list)
+	list, _ = fetch(domain.NewsFilter{EndDate: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), SortBy: "id"})
+	assert.Equal(t, []int64{f.travel, f.climate}, list)
+	list, _ = fetch(domain.NewsFilter{IDs: []int64{f.health, f.climate}, SortBy: "id"})
+	assert.Equal(t, []int64{f.health, f.climate}, list)
+
+	// ties are broken by id, so that the pages do not overlap
+	list, _ = fetch(domain.NewsFilter{SortBy: "author_id"})
+	assert.Equal(t, []int64{f.health, f.travel, f.ai, f.climate}, list)
+	list, _ = fetch(domain.NewsFilter{SortBy: "author_id", SortOrder: "desc"})
+	assert.Equal(t, []int64{f.climate, f.ai, f.travel, f.health}, list)
+
+	// the total counts every page
+	list, total = fetch(domain.NewsFilter{SortBy: "id", Limit: 1, Page: 2})
+	assert.Equal(t, []int64{f.ai}, list)
+	assert.Equal(t, int64(4), total)
+
+	// only the selected fields are loaded
+	sparse, _, err := r.News.Fetch(ctx, domain.NewsFilter{ID: f.health, Limit: 10, Fields: []string{"title"}})
+	require.NoError(t, err)
+	require.Len(t, sparse, 1)
+	assert.Equal(t, domain.News{ID: f.health, Title: "Health Benefits"}, sparse[0])
+
+	count, err := r.News.Count(ctx, domain.NewsFilter{Status: "published"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// the streamed news carry their author name and topics, oldest first
+	var streamed []domain.News
+	require.NoError(t, r.News.Stream(ctx, domain.NewsFilter{}, func(n domain.News) error {
+		streamed = append(streamed, n)
+		return nil
+	}))
+	assert.Equal(t, []int64{f.climate, f.travel, f.ai, f.health}, ids(streamed))
+	assert.Equal(t, domain.AuthorNews{ID: 1, Name: "Doni"}, streamed[3].Author)
+	assert.Equal(t, []domain.TopicNews{{ID: f.healthTopic, Name: "Health"}}, streamed[3].Topics)
+	assert.Empty(t, streamed[0].Topics)
+	errStop := errors.New("stop")
+	err = r.News.Stream(ctx, domain.NewsFilter{Limit: 2, Page: 2}, func(n domain.News) error { return errStop })
+	assert.ErrorIs(t, err, errStop)
+
+	latest, err := r.News.FetchLatestByAuthors(ctx, []int64{1, 2}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{f.health, f.ai}, ids(latest))
+	assert.Equal(t, "Deni", latest[1].Author.Name)
+
+	archive, err := r.News.Archive(ctx, domain.NewsFilter{})
+	require.NoError(t, err)
+	require.Len(t, archive, 2)
+	assert.Equal(t, "2024-09", archive[0].Month.Format("2006-01"))
+	assert.Equal(t, int64(2), archive[0].Total)
+	assert.Equal(t, "2024-10", archive[1].Month.Format("2006-01"))
+	assert.False(t, archive[1].LastModified.IsZero())
+
+	// the facets leave out their own condition
+	facets, err := r.News.Facets(ctx, domain.NewsFilter{Status: "published"}, domain.NewsFacets)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Facet{
+		{Name: "status", Values: []domain.FacetValue{
+			{Value: "published", Count: 2}, {Value: "deleted", Count: 1}, {Value: "draft", Count: 1},
+		}},
+		{Name: "topic", Values: []domain.FacetValue{
+			{Value: strconv.FormatInt(f.healthTopic, 10), Label: "Health", Count: 1}, {Value: strconv.FormatInt(f.techTopic, 10), Label: "Technology", Count: 1},
+		}},
+		{Name: "author", Values: []domain.FacetValue{
+			{Value: "1", Label: "Doni", Count: 1}, {Value: "2", Label: "Deni", Count: 1},
+		}},
+		{Name: "month", Values: []domain.FacetValue{{Value: "2024-10", Count: 2}}},
+	}, facets)
+	_, err = r.News.Facets(ctx, domain.NewsFilter{}, []string{"color"})
+	assert.ErrorIs(t, err, domain.ErrBadParamInput)
+
+	stats, err := r.News.AuthorStats(ctx, domain.AuthorStatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	doni, deni := stats[0], stats[1]
+	assert.Equal(t, int64(1), doni.AuthorID)
+	assert.Equal(t, "Doni", doni.Name)
+	assert.Equal(t, [4]int64{2, 1, 1, 0}, [4]int64{doni.Total, doni.Draft, doni.Published, doni.Deleted})
+	assert.InDelta(t, 3, doni.AverageWords, 0.01)
+	assert.NotNil(t, doni.AverageHoursToPublish)
+	assert.Equal(t, []domain.AuthorTopic{{ID: f.healthTopic, Name: "Health", Count: 2}}, doni.TopTopics)
+	require.Len(t, doni.Periods, 2)
+	assert.Equal(t, "2024-09-01", doni.Periods[0].Start.Format(time.DateOnly))
+	assert.Equal(t, domain.AuthorPeriod{Start: doni.Periods[1].Start, Total: 1, Published: 1}, doni.Periods[1])
+	// the deleted news are not counted in the words
+	assert.Equal(t, [4]int64{2, 0, 1, 1}, [4]int64{deni.Total, deni.Draft, deni.Published, deni.Deleted})
+	assert.InDelta(t, 3, deni.AverageWords, 0.01)
+	assert.Nil(t, deni.AverageHoursToPublish)
+
+	weekly, err := r.News.AuthorStats(ctx, domain.AuthorStatsFilter{AuthorID: 1, Interval: "week"})
+	require.NoError(t, err)
+	require.Len(t, weekly, 1)
+	require.Len(t, weekly[0].Periods, 2)
+	assert.Equal(t, "2024-10-28", weekly[0].Periods[1].Start.Format(time.DateOnly))
+
+	// titles match the case of every letter, and their wildcards literally
+	economy := storeNews(t, r, "Économie: 100% Über", 1, domain.Draft, "Body")
+	for _, title := range []string{"über", "ÉCONOMIE", "100%", "%"} {
+		list, _ = fetch(domain.NewsFilter{Title: title})
+		assert.Equal(t, []int64{economy}, list, title)
+	}
+	list, _ = fetch(domain.NewsFilter{Title: "_"})
+	assert.Empty(t, list)
+}
+
+func testTopics(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	health := storeTopic(t, r, "Health")
+	tech := storeTopic(t, r, "Technology")
+	travel := storeTopic(t, r, "Travel")
+
+	got, err := r.Topics.GetByID(ctx, health)
+	require.NoError(t, err)
+	assert.Equal(t, "Health", got.Name)
+	assert.Equal(t, int64(1), got.Version)
+	got, err = r.Topics.GetByName(ctx, "Technology")
+	require.NoError(t, err)
+	assert.Equal(t, tech, got.ID)
+	_, err = r.Topics.GetByID(ctx, travel+100)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = r.Topics.GetByName(ctx, "Unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	topics, err := r.Topics.GetByIDs(ctx, []int64{health, travel, travel + 100})
+	require.NoError(t, err)
+	assert.Len(t, topics, 2)
+
+	list, total, err := r.Topics.Fetch(ctx, domain.TopicFilter{Name: "tech", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, list, 1)
+	assert.Equal(t, tech, list[0].ID)
+	list, total, err = r.Topics.Fetch(ctx, domain.TopicFilter{SortBy: "name", SortOrder: "desc", Limit: 2, Page: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, list, 2)
+	assert.Equal(t, []string{"Travel", "Technology"}, []string{list[0].Name, list[1].Name})
+
+	var streamed []string
+	require.NoError(t, r.Topics.Stream(ctx, domain.TopicFilter{}, func(to domain.Topic) error {
+		streamed = append(streamed, to.Name)
+		return nil
+	}))
+	assert.Equal(t, []string{"Health", "Technology", "Travel"}, streamed)
+
+	require.NoError(t, r.Topics.Update(ctx, &domain.Topic{ID: health, Name: "Wellness", Version: 1}))
+	got, err = r.Topics.GetByID(ctx, health)
+	require.NoError(t, err)
+	assert.Equal(t, "Wellness", got.Name)
+	assert.Equal(t, int64(2), got.Version)
+	assert.ErrorIs(t, r.Topics.Update(ctx, &domain.Topic{ID: health, Name: "Health", Version: 1}), domain.ErrPreconditionFailed)
+
+	assert.ErrorIs(t, r.Topics.Delete(ctx, health, 1), domain.ErrPreconditionFailed)
+	require.NoError(t, r.Topics.Delete(ctx, health, 2))
+	require.NoError(t, r.Topics.Delete(ctx, travel, 0))
+	_, err = r.Topics.GetByID(ctx, health)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// names match the case of every letter, and their wildcards literally
+	economy := storeTopic(t, r, "Économie_Über")
+	for _, name := range []string{"über", "ÉCONOMIE", "_"} {
+		list, _, err = r.Topics.Fetch(ctx, domain.TopicFilter{Name: name, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, list, 1, name)
+		assert.Equal(t, economy, list[0].ID)
+	}
+	list, _, err = r.Topics.Fetch(ctx, domain.TopicFilter{Name: "%", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func testTopicStats(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	health := storeTopic(t, r, "Health")
+	tech := storeTopic(t, r, "Technology")
+	travel := storeTopic(t, r, "Travel")
+	require.NoError(t, r.NewsTopics.StoreBatch(ctx, []domain.NewsTopic{
+		{NewsID: storeNews(t, r, "Health Benefits", 1, domain.Published, "Eat well"), TopicID: health},
+		{NewsID: storeNews(t, r, "Sleep Well", 2, domain.Draft, "Sleep"), TopicID: health},
+		{NewsID: storeNews(t, r, "AI and the Future of Work", 2, domain.Published, "Machines"), TopicID: tech},
+	}))
+
+	list, _, err := r.Topics.Fetch(ctx, domain.TopicFilter{WithStats: true, SortBy: "published", SortOrder: "desc", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, []int64{health, tech, travel}, []int64{list[0].ID, list[1].ID, list[2].ID})
+	assert.Equal(t, int64(1), list[0].Stats.Published)
+	assert.Equal(t, int64(1), list[0].Stats.Draft)
+	assert.NotNil(t, list[0].Stats.LastPublishedAt)
+	assert.Equal(t, []domain.TopicAuthor{{ID: 1, Name: "Doni", Count: 1}}, list[0].Stats.TopAuthors)
+	assert.Nil(t, list[2].Stats.LastPublishedAt)
+	assert.Empty(t, list[2].Stats.TopAuthors)
+
+	// the topics without published news go last
+	list, _, err = r.Topics.Fetch(ctx, domain.TopicFilter{WithStats: true, SortBy: "last_published_at", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.Equal(t, travel, list[2].ID)
+
+	counts, err := r.Topics.CountNews(ctx, []int64{health, tech, travel})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]int64{health: 2, tech: 1}, counts)
+
+	activity, err := r.Topics.Stats(ctx, health, 4)
+	require.NoError(t, err)
+	assert.Equal(t, health, activity.TopicID)
+	assert.Equal(t, int64(1), activity.Published)
+	require.Len(t, activity.Weeks, 4)
+	thisWeek := activity.Weeks[3]
+	assert.Equal(t, time.Monday, thisWeek.Week.Weekday())
+	assert.Equal(t, [2]int64{1, 1}, [2]int64{thisWeek.Published, thisWeek.Draft})
+	assert.Equal(t, [2]int64{0, 0}, [2]int64{activity.Weeks[0].Published, activity.Weeks[0].Draft})
+	_, err = r.Topics.Stats(ctx, travel+100, 4)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testNewsTopics(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	health := storeNews(t, r, "Health Benefits", 1, domain.Published, "Eat well")
+	ai := storeNews(t, r, "AI and the Future of Work", 2, domain.Published, "Machines")
+	healthTopic := storeTopic(t, r, "Health")
+	techTopic := storeTopic(t, r, "Technology")
+
+	require.NoError(t, r.NewsTopics.Store(ctx, &domain.NewsTopic{NewsID: health, TopicID: healthTopic}))
+	// the existing relations are skipped
+	require.NoError(t, r.NewsTopics.StoreBatch(ctx, []domain.NewsTopic{
+		{NewsID: health, TopicID: techTopic},
+		{NewsID: ai, TopicID: techTopic},
+		{NewsID: health, TopicID: healthTopic},
+	}))
+
+	relations, err := r.NewsTopics.GetByNewsID(ctx, health)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []domain.NewsTopic{{NewsID: health, TopicID: healthTopic}, {NewsID: health, TopicID: techTopic}}, relations)
+	relations, err = r.NewsTopics.GetByTopicID(ctx, techTopic)
+	require.NoError(t, err)
+	assert.Len(t, relations, 2)
+	_, err = r.NewsTopics.GetByNewsID(ctx, ai+100)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.NoError(t, r.NewsTopics.DeleteByNewsID(ctx, ai))
+	_, err = r.NewsTopics.GetByNewsID(ctx, ai)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// deleting a news deletes its relations
+	require.NoError(t, r.News.Delete(ctx, health, 0))
+	_, err = r.NewsTopics.GetByTopicID(ctx, healthTopic)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testAuthors(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	a, err := r.Authors.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Doni", a.Name)
+	a, err = r.Authors.GetByName(ctx, "Deni")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), a.ID)
+	_, err = r.Authors.GetByID(ctx, 99)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = r.Authors.GetByName(ctx, "Unknown")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	list, err := r.Authors.GetByIDs(ctx, []int64{1, 2, 99})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+func testAudit(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	created := &domain.AuditLog{Actor: "alice", Action: domain.AuditCreate, EntityType: domain.AuditEntityNews,
+		EntityID: 1, After: []byte(`{"title":"Health Benefits"}`), RequestID: "req-1", ClientIP: "127.0.0.1"}
+	require.NoError(t, r.Audit.Store(ctx, created))
+	assert.NotZero(t, created.ID)
+	assert.False(t, created.CreatedAt.IsZero())
+	require.NoError(t, r.Audit.Store(ctx, &domain.AuditLog{Actor: "alice", Action: domain.AuditUpdate,
+		EntityType: domain.AuditEntityNews, EntityID: 1,
+		Before: []byte(`{"title":"Health Benefits"}`), After: []byte(`{"title":"Sleep"}`)}))
+	require.NoError(t, r.Audit.StoreBatch(ctx, []*domain.AuditLog{
+		{Actor: "bob", Action: domain.AuditCreate, EntityType: domain.AuditEntityTopic, EntityID: 1, After: []byte(`{}`)},
+		{Actor: "bob", Action: domain.AuditCreate, EntityType: domain.AuditEntityTopic, EntityID: 2, After: []byte(`{}`)},
+	}))
+	require.NoError(t, r.Audit.StoreBatch(ctx, nil))
+
+	fetch := func(filter domain.AuditFilter) ([]domain.AuditLog, int64) {
+		t.Helper()
+		if filter.Limit == 0 {
+			filter.Limit = 10
+		}
+		list, total, err := r.Audit.Fetch(ctx, filter)
+		require.NoError(t, err)
+		return list, total
+	}
+
+	// the latest entries come first
+	list, total := fetch(domain.AuditFilter{})
+	assert.Equal(t, int64(4), total)
+	require.Len(t, list, 4)
+	assert.Equal(t, int64(2), list[0].EntityID)
+	_, total = fetch(domain.AuditFilter{EntityType: "news"})
+	assert.Equal(t, int64(2), total)
+	_, total = fetch(domain.AuditFilter{Actor: "bob", EntityID: 2})
+	assert.Equal(t, int64(1), total)
+	_, total = fetch(domain.AuditFilter{Action: "update"})
+	assert.Equal(t, int64(1), total)
+	_, total = fetch(domain.AuditFilter{StartDate: time.Now().Add(time.Hour)})
+	assert.Zero(t, total)
+	list, total = fetch(domain.AuditFilter{Limit: 1, Page: 2})
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(4), total)
+
+	// the entries are streamed oldest first with their snapshots
+	var streamed []domain.AuditLog
+	require.NoError(t, r.Audit.Stream(ctx, domain.AuditFilter{EntityType: "news"}, func(entry domain.AuditLog) error {
+		streamed = append(streamed, entry)
+		return nil
+	}))
+	require.Len(t, streamed, 2)
+	assert.Equal(t, created.ID, streamed[0].ID)
+	assert.Equal(t, "req-1", streamed[0].RequestID)
+	assert.Equal(t, "127.0.0.1", streamed[0].ClientIP)
+	assert.Empty(t, streamed[0].Before)
+	assert.JSONEq(t, `{"title":"Health Benefits"}`, string(streamed[0].After))
+	assert.JSONEq(t, `{"title":"Health Benefits"}`, string(streamed[1].Before))
+}
+
+func testTransactions(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+	err := r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if !r.SingleWriter {
+			storeTopic(t, r, "Outside")
+		}
+		require.NoError(t, r.Topics.Store(ctx, &domain.Topic{Name: "Health"}))
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+	_, err = r.Topics.GetByName(ctx, "Health")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	if !r.SingleWriter {
+		_, err = r.Topics.GetByName(ctx, "Outside")
+		assert.NoError(t, err)
+	}
+
+	// the reads of a transaction see its writes
+	require.NoError(t, r.Transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := r.Topics.Store(ctx, &domain.Topic{Name: "Health"}); err != nil {
+			return err
+		}
+		_, err := r.Topics.GetByName(ctx, "Health")
+		return err
+	}))
+	_, err = r.Topics.GetByName(ctx, "Health")
+	assert.NoError(t, err)
+}
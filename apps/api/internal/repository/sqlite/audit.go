@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+type AuditRepository struct {
+	Conn *repository.DB
+}
+
+// NewAuditRepository will create an object that represent the audit.Repository interface
+func NewAuditRepository(conn *repository.DB) *AuditRepository {
+	return &AuditRepository{conn}
+}
+
+// auditFilter builds the WHERE clause shared by Fetch and Stream
+func auditFilter(filter domain.AuditFilter) (where string, args []interface{}) {
+	where = " WHERE 1=1"
+
+	if filter.EntityType != "" {
+		where += " AND entity_type = ?"
+		args = append(args, filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		where += " AND entity_id = ?"
+		args = append(args, filter.EntityID)
+	}
+	if filter.Actor != "" {
+		where += " AND actor = ?"
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		where += " AND action = ?"
+		args = append(args, filter.Action)
+	}
+	var zeroTime time.Time
+	if filter.StartDate != zeroTime {
+		where += " AND created_at >= ?"
+		args = append(args, filter.StartDate.UTC())
+	}
+	if filter.EndDate != zeroTime {
+		where += " AND created_at <= ?"
+		args = append(args, filter.EndDate.UTC())
+	}
+	return where, args
+}
+
+func (ar *AuditRepository) stream(ctx context.Context, fn func(domain.AuditLog) error, query string, args ...interface{}) (err error) {
+	rows, err := ar.Conn.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		t := domain.AuditLog{}
+		var before, after []byte
+		err = rows.Scan(
+			&t.ID,
+			&t.Actor,
+			&t.Action,
+			&t.EntityType,
+			&t.EntityID,
+			&before,
+			&after,
+			&t.RequestID,
+			&t.ClientIP,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return err
+		}
+		t.Before, t.After = before, after
+		if err = fn(t); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+const auditColumns = `SELECT id, actor, action, entity_type, entity_id, "before", "after", request_id, client_ip, created_at
+			  FROM audit_log`
+
+func (ar *AuditRepository) Fetch(ctx context.Context, filter domain.AuditFilter) (res []domain.AuditLog, totalData int64, err error) {
+	where, args := auditFilter(filter)
+
+	err = ar.Conn.Reader(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log"+where, args...).Scan(&totalData)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Pagination logic
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	offset := (filter.Page - 1) * filter.Limit
+	query := auditColumns + where + " ORDER BY id DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, offset)
+
+	res = make([]domain.AuditLog, 0)
+	err = ar.stream(ctx, func(entry domain.AuditLog) error {
+		res = append(res, entry)
+		return nil
+	}, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return res, totalData, nil
+}
+
+// Stream calls fn for every entry matching filter, oldest first, without
+// loading them all in memory
+func (ar *AuditRepository) Stream(ctx context.Context, filter domain.AuditFilter, fn func(domain.AuditLog) error) error {
+	where, args := auditFilter(filter)
+	return ar.stream(ctx, fn, auditColumns+where+" ORDER BY id", args...)
+}
+
+// auditInsert inserts audit entries, followed by the placeholders of their values
+const auditInsert = `INSERT INTO audit_log (actor, action, entity_type, entity_id, "before", "after", request_id, client_ip, created_at) VALUES `
+
+// auditValues returns the arguments of a, a missing snapshot is stored as NULL
+func auditValues(a *domain.AuditLog) []interface{} {
+	var before, after interface{}
+	if a.Before != nil {
+		before = string(a.Before)
+	}
+	if a.After != nil {
+		after = string(a.After)
+	}
+	return []interface{}{a.Actor, a.Action, a.EntityType, a.EntityID, before, after, a.RequestID, a.ClientIP, a.CreatedAt.UTC()}
+}
+
+func (ar *AuditRepository) Store(ctx context.Context, a *domain.AuditLog) (err error) {
+	a.CreatedAt = time.Now()
+	res, err := ar.Conn.Writer(ctx).ExecContext(ctx, auditInsert+"(?, ?, ?, ?, ?, ?, ?, ?, ?)", auditValues(a)...)
+	if err != nil {
+		return
+	}
+	a.ID, err = res.LastInsertId()
+	return
+}
+
+// StoreBatch inserts all entries with a statement per maxBindParameters / 9
+// of them
+func (ar *AuditRepository) StoreBatch(ctx context.Context, list []*domain.AuditLog) error {
+	for chunk := range slices.Chunk(list, maxBindParameters/9) {
+		if err := ar.storeBatch(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeBatch inserts a chunk of StoreBatch with a single statement
+func (ar *AuditRepository) storeBatch(ctx context.Context, list []*domain.AuditLog) (err error) {
+	if len(list) == 0 {
+		return nil
+	}
+
+	query := auditInsert
+	args := make([]interface{}, 0, len(list)*9)
+	now := time.Now()
+	for i, a := range list {
+		if i > 0 {
+			query += ", "
+		}
+		query += "(?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		a.CreatedAt = now
+		args = append(args, auditValues(a)...)
+	}
+
+	_, err = ar.Conn.Writer(ctx).ExecContext(ctx, query, args...)
+	return
+}
@@ -0,0 +1,41 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+	"github.com/bxcodec/go-clean-arch/internal/repository/repositorytest"
+	sqliteRepo "github.com/bxcodec/go-clean-arch/internal/repository/sqlite"
+)
+
+// TestContract runs the repository contract against a new database file for
+// every test
+func TestContract(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		conn, err := sqliteRepo.Open(context.Background(), filepath.Join(t.TempDir(), "news.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+
+		// the schema has its own authors
+		_, err = conn.Exec(`DELETE FROM author`)
+		require.NoError(t, err)
+		_, err = conn.Exec(repositorytest.SeedAuthors)
+		require.NoError(t, err)
+
+		db := repository.NewDB(conn)
+		return repositorytest.Repositories{
+			News:       sqliteRepo.NewNewsRepository(db),
+			Authors:    sqliteRepo.NewAuthorRepository(db),
+			Topics:     sqliteRepo.NewTopicRepository(db),
+			NewsTopics: sqliteRepo.NewNewsTopicRepository(db),
+			Audit:      sqliteRepo.NewAuditRepository(db),
+			Transactor: repository.NewTransactor(conn),
+			// the transactions take the write lock when they begin, see Open
+			SingleWriter: true,
+		}
+	})
+}
@@ -0,0 +1,66 @@
+// Package sqlite implements the repositories on an embedded SQLite database,
+// for local development and single node deployments.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	_ "embed"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+//go:embed schema.sql
+var schema string
+
+func init() {
+	// word_count(text) counts the words separated by white space, which
+	// SQLite can not split without regular expressions
+	sqlite.MustRegisterDeterministicScalarFunction("word_count", 1,
+		func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch v := args[0].(type) {
+			case string:
+				return int64(len(strings.Fields(v))), nil
+			case []byte:
+				return int64(len(strings.Fields(string(v)))), nil
+			}
+			return int64(0), nil
+		})
+	// unicode_lower(text) lower-cases every letter, the lower function of
+	// SQLite only the ASCII ones
+	sqlite.MustRegisterDeterministicScalarFunction("unicode_lower", 1,
+		func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			switch v := args[0].(type) {
+			case string:
+				return strings.ToLower(v), nil
+			case []byte:
+				return strings.ToLower(string(v)), nil
+			}
+			return args[0], nil
+		})
+}
+
+// Open opens the SQLite database at path and creates its tables when they do
+// not exist. The connections enforce the foreign keys, wait for the locks of
+// the others, take the write lock when a transaction begins, and write the
+// times in the format of the SQLite date functions.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	params := url.Values{
+		"_pragma":      {"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"},
+		"_time_format": {"sqlite"},
+		"_txlock":      {"immediate"},
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if _, err = db.ExecContext(ctx, schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create the schema: %w", err)
+	}
+	return db, nil
+}
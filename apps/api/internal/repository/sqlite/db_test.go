@@ -0,0 +1,38 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqliteRepo "github.com/bxcodec/go-clean-arch/internal/repository/sqlite"
+)
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "news.db")
+	db, err := sqliteRepo.Open(ctx, path)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO topic (name) VALUES ('Health')`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	// opening again keeps the data and does not seed the authors twice
+	db, err = sqliteRepo.Open(ctx, path)
+	require.NoError(t, err)
+	defer db.Close()
+	var topics, authors int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM topic`).Scan(&topics))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM author`).Scan(&authors))
+	assert.Equal(t, 1, topics)
+	assert.Equal(t, 4, authors)
+
+	// the audit log is append-only
+	_, err = db.Exec(`INSERT INTO audit_log (actor, action, entity_type, entity_id) VALUES ('alice', 'create', 'topic', 1)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`DELETE FROM audit_log`)
+	assert.ErrorContains(t, err, "append-only")
+}
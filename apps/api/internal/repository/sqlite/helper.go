@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// in returns the placeholders and the arguments of an IN list of values,
+// matching nothing when values is empty
+func in[T any](values []T) (placeholders string, args []interface{}) {
+	if len(values) == 0 {
+		return "NULL", nil
+	}
+	args = make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", "), args
+}
+
+// maxBindParameters is the number of parameters SQLite binds per statement,
+// SQLITE_MAX_VARIABLE_NUMBER as compiled in the driver
+const maxBindParameters = 32766
+
+// monthStart and weekStart truncate a DATETIME column to the first day of
+// its month and to the Monday of its week
+const (
+	monthStart = `strftime('%%Y-%%m-01', %[1]s)`
+	weekStart  = `date(%[1]s, 'weekday 0', '-6 days')`
+)
+
+// timeLayouts are the layouts of the times written by the driver, by SQLite
+// and by the date functions
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+// nullTime scans the times computed by the queries, which SQLite returns as
+// text unlike the DATETIME columns
+type nullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (t *nullTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		t.Time, t.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		t.Time, t.Valid = v, true
+		return nil
+	case string:
+		for _, layout := range timeLayouts {
+			if parsed, err := time.Parse(layout, v); err == nil {
+				t.Time, t.Valid = parsed, true
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("can not scan %T %v into a time", value, value)
+}
@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+type NewsTopicRepository struct {
+	Conn *repository.DB
+}
+
+// NewNewsTopicRepository will create an object that represent the newsTopic.Repository interface
+func NewNewsTopicRepository(conn *repository.DB) *NewsTopicRepository {
+	return &NewsTopicRepository{conn}
+}
+
+func (ntr *NewsTopicRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.NewsTopic, err error) {
+	rows, err := ntr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	result = make([]domain.NewsTopic, 0)
+	for rows.Next() {
+		t := domain.NewsTopic{}
+		err = rows.Scan(
+			&t.NewsID,
+			&t.TopicID,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+func (ntr *NewsTopicRepository) GetByNewsID(ctx context.Context, newsId int64) (res []domain.NewsTopic, err error) {
+	query := `SELECT news_id, topic_id
+			  FROM news_topic WHERE news_id = ?`
+
+	list, err := ntr.fetch(ctx, query, newsId)
+	if err != nil {
+		return []domain.NewsTopic{}, err
+	}
+
+	if len(list) == 0 {
+		return res, domain.ErrNotFound
+	}
+
+	return list, nil
+}
+
+func (ntr *NewsTopicRepository) GetByTopicID(ctx context.Context, topicId int64) (res []domain.NewsTopic, err error) {
+	query := `SELECT news_id, topic_id
+			  FROM news_topic WHERE topic_id = ?`
+
+	list, err := ntr.fetch(ctx, query, topicId)
+	if err != nil {
+		return []domain.NewsTopic{}, err
+	}
+
+	if len(list) == 0 {
+		return res, domain.ErrNotFound
+	}
+
+	return list, nil
+}
+
+func (ntr *NewsTopicRepository) Store(ctx context.Context, nt *domain.NewsTopic) (err error) {
+	query := `INSERT INTO news_topic (news_id, topic_id)
+			  VALUES (?, ?)`
+	_, err = ntr.Conn.Writer(ctx).ExecContext(ctx, query, nt.NewsID, nt.TopicID)
+	return
+}
+
+// StoreBatch inserts all the relations with a statement per
+// maxBindParameters / 2 of them
+func (ntr *NewsTopicRepository) StoreBatch(ctx context.Context, list []domain.NewsTopic) error {
+	for chunk := range slices.Chunk(list, maxBindParameters/2) {
+		if err := ntr.storeBatch(ctx, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storeBatch inserts a chunk of StoreBatch with a single statement
+func (ntr *NewsTopicRepository) storeBatch(ctx context.Context, list []domain.NewsTopic) (err error) {
+	if len(list) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO news_topic (news_id, topic_id) VALUES `
+	args := make([]interface{}, 0, len(list)*2)
+	for i, nt := range list {
+		if i > 0 {
+			query += ", "
+		}
+		query += "(?, ?)"
+		args = append(args, nt.NewsID, nt.TopicID)
+	}
+	query += " ON CONFLICT (news_id, topic_id) DO NOTHING"
+
+	_, err = ntr.Conn.Writer(ctx).ExecContext(ctx, query, args...)
+	return
+}
+
+func (ntr *NewsTopicRepository) Delete(ctx context.Context, newsId int64, topicId int64) (err error) {
+	query := "DELETE FROM news_topic WHERE news_id = ? AND topic_id = ?"
+
+	res, err := ntr.Conn.Writer(ctx).ExecContext(ctx, query, newsId, topicId)
+	if err != nil {
+		return
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected != 1 {
+		err = fmt.Errorf("unexpected behavior: total affected rows = %d", rowsAffected)
+		return
+	}
+
+	return
+}
+
+func (ntr *NewsTopicRepository) DeleteByNewsID(ctx context.Context, newsId int64) (err error) {
+	query := "DELETE FROM news_topic WHERE news_id = ?"
+
+	res, err := ntr.Conn.Writer(ctx).ExecContext(ctx, query, newsId)
+	if err != nil {
+		return
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected != 1 {
+		err = fmt.Errorf("unexpected behavior: total affected rows = %d", rowsAffected)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,83 @@
+-- Schema of the SQLite database, run by Open at every start
+
+-- Table structure for table `news`
+CREATE TABLE IF NOT EXISTS news
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    title      VARCHAR(45) NOT NULL,
+    content    TEXT        NOT NULL,
+    author_id  INTEGER  DEFAULT 0,
+    status     VARCHAR(20) NOT NULL,
+    version    INTEGER     NOT NULL DEFAULT 1,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Table structure for table `topic`
+CREATE TABLE IF NOT EXISTS topic
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(100) NOT NULL,
+    version    INTEGER      NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Table structure for table `news_topic` (associates `news` with `topic`)
+CREATE TABLE IF NOT EXISTS news_topic
+(
+    id       INTEGER PRIMARY KEY AUTOINCREMENT,
+    news_id  INTEGER NOT NULL REFERENCES news (id) ON DELETE CASCADE,
+    topic_id INTEGER NOT NULL REFERENCES topic (id) ON DELETE CASCADE,
+    UNIQUE (news_id, topic_id)
+);
+
+-- Table structure for table `author`
+CREATE TABLE IF NOT EXISTS author
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    name       VARCHAR(200) DEFAULT '',
+    created_at DATETIME     DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME     DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The authors can not be created through the API
+INSERT OR IGNORE INTO author (id, name, created_at, updated_at)
+VALUES (1, 'Doni', '2017-05-18 13:50:19', '2017-05-18 13:50:19'),
+       (2, 'Deni', '2017-05-19 14:00:00', '2017-05-19 14:00:00'),
+       (3, 'Dani', '2017-05-20 15:00:00', '2017-05-20 15:00:00'),
+       (4, 'Dini', '2017-05-21 16:00:00', '2017-05-21 16:00:00');
+
+-- Table structure for table `audit_log` (append-only trail of every mutation)
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor       VARCHAR(200) NOT NULL,
+    action      VARCHAR(20)  NOT NULL,
+    entity_type VARCHAR(20)  NOT NULL,
+    entity_id   INTEGER      NOT NULL,
+    "before"    TEXT,
+    "after"     TEXT,
+    request_id  VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip   VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor);
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log (created_at);
+
+-- Reject any change to existing audit entries
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+    BEFORE UPDATE
+    ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+    BEFORE DELETE
+    ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,457 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+type TopicRepository struct {
+	Conn *repository.DB
+}
+
+// NewTopicRepository will create an object that represent the topic.Repository interface
+func NewTopicRepository(conn *repository.DB) *TopicRepository {
+	return &TopicRepository{conn}
+}
+
+func (tr *TopicRepository) fetch(ctx context.Context, query string, args ...interface{}) (result []domain.Topic, err error) {
+	result = make([]domain.Topic, 0)
+	err = tr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		t := domain.Topic{}
+		err := rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.Version,
+			&t.UpdatedAt,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return err
+		}
+		result = append(result, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// eachRow runs query and calls fn for every row
+func (tr *TopicRepository) eachRow(ctx context.Context, query string, args []interface{}, fn func(rows *sql.Rows) error) error {
+	rows, err := tr.Conn.Reader(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logrus.Error(err)
+		}
+	}(rows)
+
+	for rows.Next() {
+		if err = fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// topicSortColumns lists the columns topics can be sorted by
+var topicSortColumns = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// topicFilter builds the WHERE clause matching filter
+func topicFilter(filter domain.TopicFilter) (where string, args []interface{}) {
+	where = " WHERE 1=1"
+
+	// Add conditions based on optional filters
+	if filter.ID != 0 {
+		where += " AND id = ?"
+		args = append(args, filter.ID)
+	}
+	if filter.Name != "" {
+		// LIKE only ignores the case of the ASCII letters
+		where += ` AND unicode_lower(name) LIKE unicode_lower(?) ESCAPE '\'`
+		args = append(args, repository.ContainsPattern(filter.Name))
+	}
+	return where, args
+}
+
+// topicStatsSortColumns lists the stats topics can be sorted by when they
+// are loaded, topics with the same stats are sorted by id
+var topicStatsSortColumns = map[string]string{
+	"published":         "published",
+	"draft":             "draft",
+	"last_published_at": "last_published_at",
+}
+
+// topicOrderBy builds the ORDER BY clause, unknown columns are ignored
+func topicOrderBy(filter domain.TopicFilter) string {
+	orderDirection := "ASC" // default to ascending
+	if filter.SortOrder == "desc" {
+		orderDirection = "DESC"
+	}
+	if column, ok := topicStatsSortColumns[filter.SortBy]; ok && filter.WithStats {
+		// the topics without published news go last
+		return fmt.Sprintf(" ORDER BY %s %s NULLS LAST, id", column, orderDirection)
+	}
+	column, ok := topicSortColumns[filter.SortBy]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" ORDER BY %s %s", column, orderDirection)
+}
+
+// topicWithStats selects the topics with the counts of their news, scanned
+// by fetchWithStats
+const topicWithStats = `SELECT id, name, version, updated_at, created_at,
+			  COALESCE(stats.published, 0) AS published, COALESCE(stats.draft, 0) AS draft, stats.last_published_at
+			  FROM topic LEFT JOIN (
+			      SELECT news_topic.topic_id,
+			             COUNT(*) FILTER (WHERE news.status = 'published') AS published,
+			             COUNT(*) FILTER (WHERE news.status = 'draft') AS draft,
+			             MAX(news.created_at) FILTER (WHERE news.status = 'published') AS last_published_at
+			      FROM news_topic JOIN news ON news.id = news_topic.news_id
+			      GROUP BY news_topic.topic_id
+			  ) stats ON stats.topic_id = topic.id`
+
+// maxTopAuthors is the number of top authors loaded with the stats of a topic
+const maxTopAuthors = 3
+
+// fetchWithStats runs a topicWithStats query and loads the top authors of
+// the topics
+func (tr *TopicRepository) fetchWithStats(ctx context.Context, query string, args ...interface{}) (result []domain.Topic, err error) {
+	result = make([]domain.Topic, 0)
+	ids := make([]int64, 0)
+	err = tr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		t := domain.Topic{Stats: &domain.TopicStats{TopAuthors: []domain.TopicAuthor{}}}
+		var lastPublishedAt nullTime
+		err := rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.Version,
+			&t.UpdatedAt,
+			&t.CreatedAt,
+			&t.Stats.Published,
+			&t.Stats.Draft,
+			&lastPublishedAt,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return err
+		}
+		if lastPublishedAt.Valid {
+			t.Stats.LastPublishedAt = &lastPublishedAt.Time
+		}
+		result = append(result, t)
+		ids = append(ids, t.ID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := tr.topAuthors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result {
+		if top, ok := authors[result[i].ID]; ok {
+			result[i].Stats.TopAuthors = top
+		}
+	}
+	return result, nil
+}
+
+// topAuthors returns the authors with the most published news of every given
+// topic, in a single query
+func (tr *TopicRepository) topAuthors(ctx context.Context, ids []int64) (res map[int64][]domain.TopicAuthor, err error) {
+	res = make(map[int64][]domain.TopicAuthor, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+
+	placeholders, args := in(ids)
+	query := `SELECT topic_id, author_id, name, total FROM (
+			      SELECT news_topic.topic_id, news.author_id, COALESCE(author.name, '') AS name, COUNT(*) AS total,
+			             ROW_NUMBER() OVER (PARTITION BY news_topic.topic_id ORDER BY COUNT(*) DESC, news.author_id) AS author_rank
+			      FROM news_topic JOIN news ON news.id = news_topic.news_id
+			      LEFT JOIN author ON author.id = news.author_id
+			      WHERE news_topic.topic_id IN (` + placeholders + `) AND news.status = 'published'
+			      GROUP BY news_topic.topic_id, news.author_id, author.name
+			  ) ranked WHERE author_rank <= ? ORDER BY topic_id, author_rank`
+	err = tr.eachRow(ctx, query, append(args, maxTopAuthors), func(rows *sql.Rows) error {
+		var topicID int64
+		var a domain.TopicAuthor
+		if err := rows.Scan(&topicID, &a.ID, &a.Name, &a.Count); err != nil {
+			return err
+		}
+		res[topicID] = append(res[topicID], a)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (tr *TopicRepository) Fetch(ctx context.Context, filter domain.TopicFilter) (res []domain.Topic, totalData int64, err error) {
+	where, args := topicFilter(filter)
+
+	// Execute the count query
+	err = tr.Conn.Reader(ctx).QueryRowContext(ctx, "SELECT COUNT(*) FROM topic"+where, args...).Scan(&totalData)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic` + where + topicOrderBy(filter)
+	fetch := tr.fetch
+	if filter.WithStats {
+		query = topicWithStats + where + topicOrderBy(filter)
+		fetch = tr.fetchWithStats
+	}
+
+	// Pagination logic
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	offset := (filter.Page - 1) * filter.Limit
+	query += " LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, offset)
+
+	// Execute the main query with pagination
+	res, err = fetch(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return res, totalData, nil
+}
+
+// Stream calls fn for every topic matching filter without loading them all
+// in memory. Results are paginated only when filter.Limit is set.
+func (tr *TopicRepository) Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) (err error) {
+	where, args := topicFilter(filter)
+
+	orderBy := topicOrderBy(filter)
+	if orderBy == "" {
+		orderBy = " ORDER BY id"
+	}
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic` + where + orderBy
+
+	if filter.Limit > 0 {
+		if filter.Page < 1 {
+			filter.Page = 1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+	}
+
+	return tr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		t := domain.Topic{}
+		err := rows.Scan(
+			&t.ID,
+			&t.Name,
+			&t.Version,
+			&t.UpdatedAt,
+			&t.CreatedAt,
+		)
+		if err != nil {
+			logrus.Error(err)
+			return err
+		}
+		return fn(t)
+	})
+}
+
+func (tr *TopicRepository) GetByID(ctx context.Context, id int64) (res domain.Topic, err error) {
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic WHERE id = ?`
+
+	list, err := tr.fetch(ctx, query, id)
+	if err != nil {
+		return domain.Topic{}, err
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+
+	return
+}
+
+// GetByIDs returns the topics with the given IDs, unknown IDs are ignored
+func (tr *TopicRepository) GetByIDs(ctx context.Context, ids []int64) ([]domain.Topic, error) {
+	placeholders, args := in(ids)
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic WHERE id IN (` + placeholders + `)`
+	return tr.fetch(ctx, query, args...)
+}
+
+// CountNews returns the number of news of every given topic, topics without
+// news are missing from the result
+func (tr *TopicRepository) CountNews(ctx context.Context, ids []int64) (res map[int64]int64, err error) {
+	placeholders, args := in(ids)
+	query := `SELECT topic_id, COUNT(*) FROM news_topic WHERE topic_id IN (` + placeholders + `) GROUP BY topic_id`
+
+	res = make(map[int64]int64, len(ids))
+	err = tr.eachRow(ctx, query, args, func(rows *sql.Rows) error {
+		var topicID, total int64
+		if err := rows.Scan(&topicID, &total); err != nil {
+			return err
+		}
+		res[topicID] = total
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Stats returns the stats of a topic with the counts of its news created
+// during each of the given number of weeks up to now, weeks without news
+// included
+func (tr *TopicRepository) Stats(ctx context.Context, id int64, weeks int64) (res domain.TopicActivity, err error) {
+	topics, err := tr.fetchWithStats(ctx, topicWithStats+" WHERE id = ?", id)
+	if err != nil {
+		return res, err
+	}
+	if len(topics) == 0 {
+		return res, domain.ErrNotFound
+	}
+	res = domain.TopicActivity{TopicID: id, TopicStats: *topics[0].Stats, Weeks: make([]domain.TopicWeek, 0, max(weeks, 0))}
+	if weeks < 1 {
+		return res, nil
+	}
+
+	// the weeks without news are filled here from the Monday of the first
+	// week, in UTC like the stored times
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	thisWeek := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	for i := weeks - 1; i >= 0; i-- {
+		res.Weeks = append(res.Weeks, domain.TopicWeek{Week: thisWeek.AddDate(0, 0, -7*int(i))})
+	}
+
+	query := `SELECT ` + fmt.Sprintf(weekStart, "news.created_at") + ` AS week,
+			         COUNT(*) FILTER (WHERE news.status = 'published'),
+			         COUNT(*) FILTER (WHERE news.status = 'draft')
+			  FROM news
+			  WHERE news.created_at >= ? AND news.created_at < ?
+			    AND news.id IN (SELECT news_id FROM news_topic WHERE topic_id = ?)
+			  GROUP BY week`
+	err = tr.eachRow(ctx, query, []interface{}{res.Weeks[0].Week, thisWeek.AddDate(0, 0, 7), id}, func(rows *sql.Rows) error {
+		var w domain.TopicWeek
+		var week nullTime
+		if err := rows.Scan(&week, &w.Published, &w.Draft); err != nil {
+			return err
+		}
+		for i := range res.Weeks {
+			if res.Weeks[i].Week.Equal(week.Time) {
+				res.Weeks[i].Published, res.Weeks[i].Draft = w.Published, w.Draft
+			}
+		}
+		return nil
+	})
+	return res, err
+}
+
+func (tr *TopicRepository) GetByName(ctx context.Context, name string) (res domain.Topic, err error) {
+	query := `SELECT id, name, version, updated_at, created_at
+			  FROM topic WHERE name = ?`
+
+	list, err := tr.fetch(ctx, query, name)
+	if err != nil {
+		return domain.Topic{}, err
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+
+	return
+}
+
+func (tr *TopicRepository) Store(ctx context.Context, a *domain.Topic) (err error) {
+	query := `INSERT INTO topic (name, updated_at, created_at)
+			  VALUES (?, ?, ?)`
+	now := time.Now().UTC()
+	res, err := tr.Conn.Writer(ctx).ExecContext(ctx, query, a.Name, now, now)
+	if err != nil {
+		return
+	}
+	a.ID, err = res.LastInsertId()
+	return
+}
+
+// Delete removes the topic, only at the given version unless it is 0
+func (tr *TopicRepository) Delete(ctx context.Context, id int64, version int64) (err error) {
+	query := "DELETE FROM topic WHERE id = ? AND (? = 0 OR version = ?)"
+
+	res, err := tr.Conn.Writer(ctx).ExecContext(ctx, query, id, version, version)
+	if err != nil {
+		return
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if rowsAffected == 0 && version != 0 {
+		return domain.ErrPreconditionFailed
+	}
+	if rowsAffected != 1 {
+		err = fmt.Errorf("unexpected behavior: total affected rows = %d", rowsAffected)
+		return
+	}
+
+	return
+}
+
+// Update renames the topic and increments its version, only at to.Version
+// unless it is 0
+func (tr *TopicRepository) Update(ctx context.Context, to *domain.Topic) (err error) {
+	query := `UPDATE topic SET name = ?, updated_at = ?, version = version + 1
+			  WHERE id = ? AND (? = 0 OR version = ?)`
+
+	res, err := tr.Conn.Writer(ctx).ExecContext(ctx, query, to.Name, time.Now().UTC(), to.ID, to.Version, to.Version)
+	if err != nil {
+		return
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return
+	}
+	if affected == 0 && to.Version != 0 {
+		return domain.ErrPreconditionFailed
+	}
+	if affected != 1 {
+		err = fmt.Errorf("unexpected behavior: total affected rows = %d", affected)
+		return
+	}
+
+	return
+}
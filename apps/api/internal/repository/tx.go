@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Conn is the part of *sql.DB and *sql.Tx used by the repositories
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+type afterCommitKey struct{}
+
+// txHooks are the functions to run once a transaction is committed
+type txHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// ConnFromContext returns the transaction carried by ctx, or db when there is none
+func ConnFromContext(ctx context.Context, db *sql.DB) Conn {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// InTransaction tells whether ctx carries a transaction
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return ok
+}
+
+// AfterCommit runs fn once the transaction carried by ctx is committed, or
+// right away when there is none. fn is dropped if the transaction is rolled
+// back.
+func AfterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(afterCommitKey{}).(*txHooks)
+	if !ok {
+		fn()
+		return
+	}
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	hooks.fns = append(hooks.fns, fn)
+}
+
+// Transactor runs functions inside a database transaction
+type Transactor struct {
+	DB *sql.DB
+}
+
+// NewTransactor will create a Transactor for the given database
+func NewTransactor(db *sql.DB) *Transactor {
+	return &Transactor{DB: db}
+}
+
+// WithinTransaction runs fn with a context carrying a transaction, which is
+// committed when fn succeeds and rolled back otherwise. Nested calls join the
+// outer transaction.
+func (t *Transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	hooks := &txHooks{}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				logrus.Error(errRollback)
+			}
+			return
+		}
+		if err = tx.Commit(); err == nil {
+			for _, fn := range hooks.fns {
+				fn()
+			}
+		}
+	}()
+
+	return fn(context.WithValue(context.WithValue(ctx, txKey{}, tx), afterCommitKey{}, hooks))
+}
@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+func TestWithinTransactionCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO topic").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	var committed bool
+	transactor := repository.NewTransactor(db)
+	err = transactor.WithinTransaction(context.TODO(), func(ctx context.Context) error {
+		assert.NotEqual(t, db, repository.ConnFromContext(ctx, db))
+		assert.True(t, repository.InTransaction(ctx))
+
+		// Nested calls join the outer transaction
+		return transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			repository.AfterCommit(ctx, func() { committed = true })
+			_, err := repository.ConnFromContext(ctx, db).ExecContext(ctx, "INSERT INTO topic (name) VALUES ('Health')")
+			assert.False(t, committed)
+			return err
+		})
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.True(t, committed)
+}
+
+func TestWithinTransactionRollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	errExpected := errors.New("failed to store audit log")
+	err = repository.NewTransactor(db).WithinTransaction(context.TODO(), func(ctx context.Context) error {
+		repository.AfterCommit(ctx, func() { t.Error("the hooks of a rolled back transaction must not run") })
+		return errExpected
+	})
+	assert.ErrorIs(t, err, errExpected)
+	assert.False(t, repository.InTransaction(context.TODO()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, db, repository.ConnFromContext(context.TODO(), db))
+}
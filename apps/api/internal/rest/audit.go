@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+)
+
+// AuditService represents the audit log use cases
+//
+//go:generate mockery --name AuditService
+type AuditService interface {
+	Fetch(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditLog, int64, error)
+	Export(ctx context.Context, filter domain.AuditFilter, fn func(domain.AuditLog) error) error
+}
+
+// AuditConfig configures the access to the audit log
+type AuditConfig struct {
+	// AdminKey is the API key required in the X-API-Key header, the audit
+	// log is not served to anyone when it is empty
+	AdminKey string
+}
+
+// AuditHandler represents the HTTP handler for the audit log
+type AuditHandler struct {
+	Service AuditService
+	Config  AuditConfig
+}
+
+// NewAuditHandler initializes the audit log endpoints
+func NewAuditHandler(mux *http.ServeMux, svc AuditService, config AuditConfig) {
+	handler := &AuditHandler{
+		Service: svc,
+		Config:  config,
+	}
+	handleRoutes(mux, map[string]http.HandlerFunc{
+		"audit.list":   negotiate(handler.requireAdmin(handler.Fetch)),
+		"audit.export": handler.requireAdmin(handler.Export),
+	})
+}
+
+// requireAdmin rejects the requests which do not carry the admin key
+func (a *AuditHandler) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return RequireAdminKey(a.Config.AdminKey, next).ServeHTTP
+}
+
+// RequireAdminKey answers 401 Unauthorized to the requests which do not send
+// adminKey in the X-API-Key header, and to every request when it is empty
+func RequireAdminKey(adminKey string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if adminKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) != 1 {
+			writeError(w, r, domain.ErrUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseAuditFilter reads the audit log filters from the query parameters
+func parseAuditFilter(query url.Values) domain.AuditFilter {
+	filter := domain.AuditFilter{
+		EntityType: query.Get("entity_type"),
+		Actor:      query.Get("actor"),
+		Action:     query.Get("action"),
+	}
+
+	if entityIDStr := query.Get("entity_id"); entityIDStr != "" {
+		if entityID, err := strconv.ParseInt(entityIDStr, 10, 64); err == nil {
+			filter.EntityID = entityID
+		}
+	}
+	if startDateStr := query.Get("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse(time.RFC3339, startDateStr); err == nil {
+			filter.StartDate = startDate
+		}
+	}
+	if endDateStr := query.Get("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse(time.RFC3339, endDateStr); err == nil {
+			filter.EndDate = endDate
+		}
+	}
+	return filter
+}
+
+// Fetch handles GET requests to list the audit log, newest first
+func (a *AuditHandler) Fetch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultLimit
+	}
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = defaultPage
+	}
+
+	filter := parseAuditFilter(query)
+	filter.Limit = int64(limit)
+	filter.Page = int64(page)
+
+	ctx := r.Context()
+	list, totalData, err := a.Service.Fetch(ctx, filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	response := dto.Response{
+		Data: list,
+		Meta: paginate(w, r, filter.Page, filter.Limit, totalData),
+	}
+	writeResponse(w, r, http.StatusOK, response)
+}
+
+// Export handles GET requests to download the audit log as NDJSON, oldest first
+func (a *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filter := parseAuditFilter(r.URL.Query())
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.ndjson"`)
+
+	// Headers are only sent with the first entry, so an error before it can
+	// still be reported with the proper status code
+	written := false
+	encoder := json.NewEncoder(w)
+	err := a.Service.Export(r.Context(), filter, func(entry domain.AuditLog) error {
+		written = true
+		return encoder.Encode(entry)
+	})
+	switch {
+	case err != nil && !written:
+		w.Header().Del("Content-Disposition")
+		writeError(w, r, err)
+	case err != nil:
+		logrus.Error(err)
+	}
+}
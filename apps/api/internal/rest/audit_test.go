@@ -0,0 +1,54 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type stubAuditService struct{}
+
+func (stubAuditService) Fetch(context.Context, domain.AuditFilter) ([]domain.AuditLog, int64, error) {
+	return []domain.AuditLog{{ID: 1, EntityType: domain.AuditEntityNews, EntityID: 1, Action: domain.AuditCreate}}, 1, nil
+}
+
+func (stubAuditService) Export(_ context.Context, _ domain.AuditFilter, fn func(domain.AuditLog) error) error {
+	return fn(domain.AuditLog{ID: 1, EntityType: domain.AuditEntityNews, EntityID: 1, Action: domain.AuditCreate})
+}
+
+func TestAuditRequiresAdminKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		adminKey string
+		apiKey   string
+		status   int
+	}{
+		{name: "missing key", adminKey: "secret", status: http.StatusUnauthorized},
+		{name: "wrong key", adminKey: "secret", apiKey: "guess", status: http.StatusUnauthorized},
+		{name: "admin key", adminKey: "secret", apiKey: "secret", status: http.StatusOK},
+		{name: "not configured", apiKey: "secret", status: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			rest.NewAuditHandler(mux, stubAuditService{}, rest.AuditConfig{AdminKey: tc.adminKey})
+
+			for _, path := range []string{"/admin/audit", "/admin/audit/export"} {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if tc.apiKey != "" {
+					req.Header.Set("X-API-Key", tc.apiKey)
+				}
+				rr := httptest.NewRecorder()
+				mux.ServeHTTP(rr, req)
+				assert.Equal(t, tc.status, rr.Code, path)
+			}
+		})
+	}
+}
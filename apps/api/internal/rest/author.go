@@ -0,0 +1,172 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// AuthorService represents the author use cases
+type AuthorService interface {
+	AuthorsByIDs(ctx context.Context, ids []int64) ([]domain.Author, error)
+	LatestByAuthors(ctx context.Context, authorIDs []int64, limit int64) ([]domain.News, error)
+	AuthorStats(ctx context.Context, filter domain.AuthorStatsFilter) ([]domain.AuthorStats, error)
+}
+
+// AuthorHandler represents the HTTP handler for authors
+type AuthorHandler struct {
+	Service AuthorService
+}
+
+const maxAuthorNews = 100
+
+// NewAuthorHandler initializes the author endpoints
+func NewAuthorHandler(mux *http.ServeMux, svc AuthorService) {
+	handler := &AuthorHandler{
+		Service: svc,
+	}
+	handleRoutes(mux, map[string]http.HandlerFunc{
+		"author.list":          negotiate(handler.Fetch),
+		"author.get":           negotiate(withID(handler.GetByID)),
+		"author.news":          negotiate(withID(handler.News)),
+		"author.stats":         negotiate(withID(handler.AuthorStats)),
+		"stats.authors":        negotiate(handler.Stats),
+		"stats.authors.export": handler.ExportStats,
+	})
+}
+
+// Fetch handles GET /author?ids=1,2 and returns the authors with the given
+// IDs, unknown IDs are skipped
+func (a *AuthorHandler) Fetch(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if len(ids) == 0 {
+		writeResponse(w, r, http.StatusOK, []domain.Author{})
+		return
+	}
+
+	authors, err := a.Service.AuthorsByIDs(r.Context(), ids)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, linkAuthors(r, authors))
+}
+
+// GetByID retrieves an author by the given ID
+func (a *AuthorHandler) GetByID(w http.ResponseWriter, r *http.Request, id int64) {
+	authors, err := a.Service.AuthorsByIDs(r.Context(), []int64{id})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if len(authors) == 0 {
+		writeError(w, r, domain.ErrNotFound)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, linkAuthors(r, authors)[0])
+}
+
+// News lists the latest news of an author, limit defaults to 10
+func (a *AuthorHandler) News(w http.ResponseWriter, r *http.Request, id int64) {
+	limit := int64(defaultLimit)
+	if l, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && l > 0 {
+		limit = min(l, maxAuthorNews)
+	}
+
+	list, err := a.Service.LatestByAuthors(r.Context(), []int64{id}, limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeResponse(w, r, http.StatusOK, linkNews(r, list))
+}
+
+// parseAuthorStatsFilter reads the date range and interval of the author
+// stats, invalid dates are ignored like in the news filter
+func parseAuthorStatsFilter(query url.Values) (domain.AuthorStatsFilter, error) {
+	filter := domain.AuthorStatsFilter{Interval: query.Get("interval")}
+	if filter.Interval == "" {
+		filter.Interval = "month"
+	}
+	if !slices.Contains(domain.StatsIntervals, filter.Interval) {
+		return filter, fmt.Errorf("%w: interval must be one of %s", domain.ErrBadParamInput, strings.Join(domain.StatsIntervals, ", "))
+	}
+	if startDate, err := time.Parse(time.RFC3339, query.Get("start_date")); err == nil {
+		filter.StartDate = startDate
+	}
+	if endDate, err := time.Parse(time.RFC3339, query.Get("end_date")); err == nil {
+		filter.EndDate = endDate
+	}
+	return filter, nil
+}
+
+// AuthorStats returns the stats of an author over the news created between
+// start_date and end_date
+func (a *AuthorHandler) AuthorStats(w http.ResponseWriter, r *http.Request, id int64) {
+	filter, err := parseAuthorStatsFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	filter.AuthorID = id
+
+	stats, err := a.Service.AuthorStats(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if len(stats) == 0 {
+		writeError(w, r, domain.ErrNotFound)
+		return
+	}
+	writeConditional(w, r, stats[0], 0, time.Time{})
+}
+
+// Stats handles GET /stats/authors, the stats of every author with news
+// created between start_date and end_date, the most published first
+func (a *AuthorHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuthorStatsFilter(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	stats, err := a.Service.AuthorStats(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeConditional(w, r, stats, 0, time.Time{})
+}
+
+// ExportStats downloads the stats of GET /stats/authors without their
+// periods, as CSV, NDJSON or XLSX
+func (a *AuthorHandler) ExportStats(w http.ResponseWriter, r *http.Request) {
+	writeExport(w, r, "author-stats", authorStatsExportColumns, func(write func(row []interface{}) error) error {
+		filter, err := parseAuthorStatsFilter(r.URL.Query())
+		if err != nil {
+			return err
+		}
+		stats, err := a.Service.AuthorStats(r.Context(), filter)
+		if err != nil {
+			return err
+		}
+		for _, s := range stats {
+			if err = write(authorStatsExportRow(s)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
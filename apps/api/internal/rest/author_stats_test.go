@@ -0,0 +1,86 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type stubAuthorService struct {
+	rest.AuthorService
+	filter domain.AuthorStatsFilter
+}
+
+func (s *stubAuthorService) AuthorStats(_ context.Context, filter domain.AuthorStatsFilter) ([]domain.AuthorStats, error) {
+	s.filter = filter
+	if filter.AuthorID > 1 {
+		return nil, domain.ErrNotFound
+	}
+	hours := 4.5
+	return []domain.AuthorStats{{
+		AuthorID:              1,
+		Name:                  "Deni",
+		Total:                 3,
+		Draft:                 1,
+		Published:             2,
+		AverageWords:          120.5,
+		AverageHoursToPublish: &hours,
+		TopTopics:             []domain.AuthorTopic{{ID: 1, Name: "Health", Count: 2}, {ID: 4, Name: "Environment", Count: 1}},
+		Periods: []domain.AuthorPeriod{
+			{Start: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), Total: 3, Published: 2},
+		},
+	}}, nil
+}
+
+func TestAuthorStats(t *testing.T) {
+	svc := &stubAuthorService{}
+	mux := http.NewServeMux()
+	rest.NewAuthorHandler(mux, svc)
+
+	rr := serve(mux, http.MethodGet, "/author/1/stats?start_date=2024-10-01T00:00:00Z&interval=week", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, domain.AuthorStatsFilter{
+		AuthorID:  1,
+		StartDate: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		Interval:  "week",
+	}, svc.filter)
+	assert.JSONEq(t, `{
+		"author_id": 1, "name": "Deni", "total": 3, "draft": 1, "published": 2, "deleted": 0,
+		"average_words": 120.5, "average_hours_to_publish": 4.5,
+		"top_topics": [{"id": 1, "name": "Health", "count": 2}, {"id": 4, "name": "Environment", "count": 1}],
+		"periods": [{"start": "2024-10-01T00:00:00Z", "total": 3, "published": 2}]
+	}`, rr.Body.String())
+
+	rr = serve(mux, http.MethodGet, "/author/2/stats", "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr = serve(mux, http.MethodGet, "/author/1/stats?interval=day", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serve(mux, http.MethodGet, "/stats/authors?end_date=2024-11-01T00:00:00Z", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, domain.AuthorStatsFilter{EndDate: time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), Interval: "month"}, svc.filter)
+	assert.Contains(t, rr.Body.String(), `[{"author_id":1,"name":"Deni"`)
+	rr = serve(mux, http.MethodPost, "/stats/authors", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestExportAuthorStats(t *testing.T) {
+	mux := http.NewServeMux()
+	rest.NewAuthorHandler(mux, &stubAuthorService{})
+
+	rr := serve(mux, http.MethodGet, "/stats/authors/export", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `attachment; filename="author-stats.csv"`, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, "author_id,name,total,draft,published,deleted,average_words,average_hours_to_publish,top_topic_ids,top_topics\n"+
+		"1,Deni,3,1,2,0,120.5,4.5,1|4,Health|Environment\n", rr.Body.String())
+
+	rr = serve(mux, http.MethodGet, "/stats/authors/export?interval=year", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,228 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+)
+
+const (
+	// maxBatchSize bounds the operations of a batch and the IDs of a
+	// multi-get
+	maxBatchSize = 100
+	// maxBatchBodySize bounds the body of a batch request
+	maxBatchBodySize = 4 << 20
+)
+
+// batchRequest is the body of POST /news/batch
+type batchRequest struct {
+	Mode       news.BatchMode          `json:"mode"`
+	Operations []batchOperationRequest `json:"operations"`
+}
+
+// batchOperationRequest is one operation of a batch request. Data is the
+// news of a create, or the fields to change of an update.
+type batchOperationRequest struct {
+	Op      news.BatchOp      `json:"op"`
+	ID      int64             `json:"id"`
+	Version int64             `json:"version"`
+	Status  domain.NewsStatus `json:"status"`
+	Data    json.RawMessage   `json:"data"`
+}
+
+// batchUpdateData holds the fields of an update operation, nil fields are
+// left unchanged
+type batchUpdateData struct {
+	Title    *string            `json:"title"`
+	Content  *string            `json:"content"`
+	AuthorID *int64             `json:"author_id"`
+	Status   *domain.NewsStatus `json:"status"`
+	TopicIDs *[]int64           `json:"topic_ids"`
+}
+
+// parseIDs reads a comma separated list of IDs, empty items are skipped
+func parseIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, idStr := range strings.Split(s, ",") {
+		if idStr = strings.TrimSpace(idStr); idStr == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid id %q", domain.ErrBadParamInput, idStr)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// decodeStrict decodes data into v, rejecting unknown fields
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+	return nil
+}
+
+// operation validates r and returns the operation it describes
+func (r batchOperationRequest) operation() (news.BatchOperation, error) {
+	op := news.BatchOperation{Op: r.Op, ID: r.ID, Version: r.Version}
+	if r.Op != news.BatchCreate && r.ID <= 0 {
+		return op, fmt.Errorf("%w: id is required", domain.ErrBadParamInput)
+	}
+
+	switch r.Op {
+	case news.BatchCreate:
+		op.Create = &news.CreateNewsReq{}
+		if err := decodeStrict(r.Data, op.Create); err != nil {
+			return op, err
+		}
+		if _, err := isRequestValid(op.Create); err != nil {
+			return op, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+		if err := op.Create.Status.Validate(); err != nil {
+			return op, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+	case news.BatchUpdate:
+		var data batchUpdateData
+		if err := decodeStrict(r.Data, &data); err != nil {
+			return op, err
+		}
+		if data.Status != nil {
+			if err := data.Status.Validate(); err != nil {
+				return op, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+			}
+		}
+		op.Update = &news.UpdateNewsReq{
+			Title:    data.Title,
+			Content:  data.Content,
+			AuthorID: data.AuthorID,
+			Status:   data.Status,
+			TopicIDs: data.TopicIDs,
+		}
+	case news.BatchStatus:
+		if err := r.Status.Validate(); err != nil {
+			return op, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+		status := r.Status
+		op.Update = &news.UpdateNewsReq{Status: &status}
+	case news.BatchDelete:
+	default:
+		return op, fmt.Errorf("%w: unknown operation %q, use create, update, status or delete", domain.ErrBadParamInput, r.Op)
+	}
+	return op, nil
+}
+
+// Batch handles POST /news/batch, it applies a list of create, update, status
+// and delete operations and reports the outcome of each of them. In atomic
+// mode, the default, either every operation is applied or none is. The
+// response is 200 when every operation succeeded and 207 otherwise.
+func (a *NewsHandler) Batch(w http.ResponseWriter, r *http.Request) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "application/json" {
+			writeError(w, r, fmt.Errorf("%w: %s, use application/json", domain.ErrUnsupportedMediaType, mediaType))
+			return
+		}
+	}
+
+	var req batchRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize)).Decode(&req); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", domain.ErrUnprocessableEntity, err))
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = news.BatchAtomic
+	}
+	if req.Mode != news.BatchAtomic && req.Mode != news.BatchBestEffort {
+		writeError(w, r, fmt.Errorf("%w: unknown mode %q, use atomic or best_effort", domain.ErrBadParamInput, req.Mode))
+		return
+	}
+	if len(req.Operations) == 0 {
+		writeError(w, r, fmt.Errorf("%w: operations are required", domain.ErrBadParamInput))
+		return
+	}
+	if len(req.Operations) > maxBatchSize {
+		writeError(w, r, fmt.Errorf("%w: a batch has at most %d operations", domain.ErrBadParamInput, maxBatchSize))
+		return
+	}
+
+	// invalid operations are reported without being sent to the service, in
+	// atomic mode they abort the whole batch
+	errs := make([]error, len(req.Operations))
+	var valid []news.BatchOperation
+	var validIndexes []int
+	for i, opReq := range req.Operations {
+		op, err := opReq.operation()
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		valid = append(valid, op)
+		validIndexes = append(validIndexes, i)
+	}
+
+	ids := make([]int64, len(req.Operations))
+	for i, opReq := range req.Operations {
+		ids[i] = opReq.ID
+	}
+	if req.Mode == news.BatchAtomic && len(valid) < len(req.Operations) {
+		for _, i := range validIndexes {
+			errs[i] = domain.ErrBatchAborted
+		}
+	} else {
+		results, err := a.Service.Batch(r.Context(), valid, req.Mode)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		for j, result := range results {
+			ids[validIndexes[j]] = result.ID
+			errs[validIndexes[j]] = result.Err
+		}
+	}
+
+	res := news.BatchResponse{Mode: req.Mode, Results: make([]news.BatchItemResult, len(req.Operations))}
+	for i, opReq := range req.Operations {
+		item := news.BatchItemResult{Index: i, Op: opReq.Op, ID: ids[i], Status: batchStatus(opReq.Op)}
+		if errs[i] != nil {
+			item.Status = dto.GetStatusCode(errs[i])
+			item.Message = errs[i].Error()
+			if opReq.Op == news.BatchCreate {
+				item.ID = 0
+			}
+			res.Failed++
+		} else {
+			res.Succeeded++
+		}
+		res.Results[i] = item
+	}
+
+	status := http.StatusOK
+	if res.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	writeResponse(w, r, status, res)
+}
+
+// batchStatus is the status of a successful operation, the one of the
+// equivalent single request
+func batchStatus(op news.BatchOp) int {
+	switch op {
+	case news.BatchCreate:
+		return http.StatusCreated
+	case news.BatchDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
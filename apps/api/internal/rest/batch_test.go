@@ -0,0 +1,143 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+// batchNewsService fails the operations on news 404, and records the
+// operations it was given
+type batchNewsService struct {
+	stubNewsService
+	operations []news.BatchOperation
+	mode       news.BatchMode
+}
+
+func (s *batchNewsService) Batch(_ context.Context, operations []news.BatchOperation, mode news.BatchMode) ([]news.BatchResult, error) {
+	s.operations = operations
+	s.mode = mode
+	results := make([]news.BatchResult, len(operations))
+	for i, op := range operations {
+		results[i].ID = op.ID
+		switch {
+		case op.Op == news.BatchCreate:
+			results[i].ID = 10 + int64(i)
+		case op.ID == 404:
+			results[i].Err = domain.ErrNotFound
+		}
+	}
+	return results, nil
+}
+
+func decodeBatch(t *testing.T, body string) news.BatchResponse {
+	var res news.BatchResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &res))
+	return res
+}
+
+func TestBatch(t *testing.T) {
+	svc := &batchNewsService{}
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+
+	rr := serve(mux, http.MethodPost, "/news/batch", `{"operations": [
+		{"op": "create", "data": {"title": "New", "content": "Body", "author_id": 1, "status": "draft", "topic_ids": [1]}},
+		{"op": "update", "id": 2, "version": 3, "data": {"title": "Renamed"}},
+		{"op": "status", "id": 3, "status": "published"},
+		{"op": "delete", "id": 4}
+	]}`, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	res := decodeBatch(t, rr.Body.String())
+	assert.Equal(t, news.BatchAtomic, res.Mode)
+	assert.Equal(t, 4, res.Succeeded)
+	assert.Equal(t, []news.BatchItemResult{
+		{Index: 0, Op: news.BatchCreate, ID: 10, Status: http.StatusCreated},
+		{Index: 1, Op: news.BatchUpdate, ID: 2, Status: http.StatusOK},
+		{Index: 2, Op: news.BatchStatus, ID: 3, Status: http.StatusOK},
+		{Index: 3, Op: news.BatchDelete, ID: 4, Status: http.StatusNoContent},
+	}, res.Results)
+
+	require.Len(t, svc.operations, 4)
+	assert.Equal(t, "New", svc.operations[0].Create.Title)
+	assert.Equal(t, "Renamed", *svc.operations[1].Update.Title)
+	assert.Nil(t, svc.operations[1].Update.Content)
+	assert.Equal(t, int64(3), svc.operations[1].Version)
+	assert.Equal(t, domain.Published, *svc.operations[2].Update.Status)
+
+	// failures of the service are reported per operation
+	rr = serve(mux, http.MethodPost, "/news/batch", `{"mode": "best_effort", "operations": [
+		{"op": "delete", "id": 404},
+		{"op": "status", "id": 5, "status": "archived"},
+		{"op": "delete", "id": 6}
+	]}`, nil)
+	require.Equal(t, http.StatusMultiStatus, rr.Code, rr.Body.String())
+	res = decodeBatch(t, rr.Body.String())
+	assert.Equal(t, 1, res.Succeeded)
+	assert.Equal(t, 2, res.Failed)
+	assert.Equal(t, http.StatusNotFound, res.Results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, res.Results[1].Status)
+	assert.Equal(t, http.StatusNoContent, res.Results[2].Status)
+	assert.Equal(t, news.BatchBestEffort, svc.mode)
+	assert.Len(t, svc.operations, 2)
+
+	// in atomic mode an invalid operation aborts the others
+	svc.operations = nil
+	rr = serve(mux, http.MethodPost, "/news/batch", `{"mode": "atomic", "operations": [
+		{"op": "delete", "id": 1},
+		{"op": "update", "id": 2, "data": {"views": 3}}
+	]}`, nil)
+	require.Equal(t, http.StatusMultiStatus, rr.Code)
+	res = decodeBatch(t, rr.Body.String())
+	assert.Equal(t, http.StatusFailedDependency, res.Results[0].Status)
+	assert.Equal(t, http.StatusBadRequest, res.Results[1].Status)
+	assert.Nil(t, svc.operations)
+}
+
+func TestBatchRejected(t *testing.T) {
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, &batchNewsService{}, rest.ConcurrencyConfig{})
+
+	operations := make([]string, 101)
+	for i := range operations {
+		operations[i] = fmt.Sprintf(`{"op": "delete", "id": %d}`, i+1)
+	}
+	for body, status := range map[string]int{
+		`{"operations": [` + strings.Join(operations, ",") + `]}`: http.StatusBadRequest,
+		`{"operations": []}`: http.StatusBadRequest,
+		`{"mode": "sometimes", "operations": [{"op": "delete", "id": 1}]}`: http.StatusBadRequest,
+		`{"operations": `: http.StatusUnprocessableEntity,
+	} {
+		rr := serve(mux, http.MethodPost, "/news/batch", body, nil)
+		assert.Equal(t, status, rr.Code, body)
+	}
+
+	rr := serve(mux, http.MethodPost, "/news/batch", `<batch/>`, http.Header{"Content-Type": {"application/xml"}})
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	rr = serve(mux, http.MethodGet, "/news/batch", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
+
+func TestMultiGet(t *testing.T) {
+	svc := &batchNewsService{}
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+
+	rr := serve(mux, http.MethodGet, "/news?ids=1,%202,3", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []int64{1, 2, 3}, svc.filter.IDs)
+	assert.Equal(t, int64(3), svc.filter.Limit)
+
+	rr = serve(mux, http.MethodGet, "/news?ids=1,two", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+)
+
+// cachedRoutes are the routes whose responses ResponseCache stores
+var cachedRoutes = []string{
+	"news.list", "news.get", "topic.list", "topic.get", "author.list", "author.get", "author.news",
+}
+
+// maxCachedResponse bounds the size of the bodies stored by ResponseCache
+const maxCachedResponse = 1 << 20
+
+// cachedResponse is a response stored by ResponseCache
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// ResponseCache serves the anonymous GET and HEAD requests of the list and
+// detail routes from c, tagged with the entities read while building their
+// responses, so that c must be cascaded to by the cache of the repositories.
+// The requests with credentials, conditional headers or Cache-Control:
+// no-cache are always served by next. It must be wrapped by RouteNames.
+func ResponseCache(c *cache.Cache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cacheable(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := responseKey(r)
+			if v, ok := c.Get(r.Context(), key); ok {
+				res := v.(*cachedResponse)
+				for k, values := range res.header {
+					w.Header()[k] = slices.Clone(values)
+				}
+				w.Header().Set("X-Cache", "HIT")
+				w.WriteHeader(res.status)
+				if r.Method != http.MethodHead {
+					_, _ = w.Write(res.body)
+				}
+				return
+			}
+
+			generation := c.Generation()
+			ctx, tags := cache.WithCollector(r.Context())
+			rec := &cacheRecorder{ResponseWriter: w, header: http.Header{}}
+			w.Header().Set("X-Cache", "MISS")
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			if r.Method == http.MethodGet && rec.status == http.StatusOK && !rec.overflow &&
+				slices.Contains(cachedRoutes, RouteName(r.Context())) {
+				c.SetSince(generation, key, &cachedResponse{
+					status: rec.status,
+					header: rec.sent,
+					body:   rec.body.Bytes(),
+				}, tags()...)
+			}
+		})
+	}
+}
+
+// cacheable tells whether the response to r may be served from the cache
+func cacheable(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	for _, name := range []string{
+		"Authorization", "Cookie", "X-API-Key", "X-User-ID", "If-None-Match", "If-Modified-Since",
+	} {
+		if r.Header.Get(name) != "" {
+			return false
+		}
+	}
+	return !strings.Contains(r.Header.Get("Cache-Control"), "no-cache")
+}
+
+// responseKey identifies the representation of the resource requested by r,
+// with the base URL of its links
+func responseKey(r *http.Request) string {
+	base, ok := r.Context().Value(baseURLKey{}).(string)
+	if !ok {
+		base = requestBaseURL(r, false)
+	}
+	return base + r.URL.RequestURI() + "\n" + r.Header.Get("Accept")
+}
+
+// cacheRecorder copies the response written through it, up to
+// maxCachedResponse bytes
+type cacheRecorder struct {
+	http.ResponseWriter
+	header      http.Header
+	sent        http.Header
+	status      int
+	body        bytes.Buffer
+	overflow    bool
+	wroteHeader bool
+}
+
+func (cr *cacheRecorder) Header() http.Header {
+	return cr.header
+}
+
+func (cr *cacheRecorder) WriteHeader(status int) {
+	if cr.wroteHeader {
+		return
+	}
+	cr.wroteHeader = true
+	cr.status = status
+	cr.sent = cr.header.Clone()
+	for k, values := range cr.sent {
+		cr.ResponseWriter.Header()[k] = values
+	}
+	cr.ResponseWriter.WriteHeader(status)
+}
+
+func (cr *cacheRecorder) Write(b []byte) (int, error) {
+	if !cr.wroteHeader {
+		cr.WriteHeader(http.StatusOK)
+	}
+	if !cr.overflow {
+		if cr.body.Len()+len(b) > maxCachedResponse {
+			cr.overflow = true
+			cr.body = bytes.Buffer{}
+		} else {
+			cr.body.Write(b)
+		}
+	}
+	return cr.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cr *cacheRecorder) Unwrap() http.ResponseWriter {
+	return cr.ResponseWriter
+}
@@ -0,0 +1,180 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/cache"
+	"github.com/bxcodec/go-clean-arch/internal/repository/cached"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+	"github.com/bxcodec/go-clean-arch/news"
+	"github.com/bxcodec/go-clean-arch/topic"
+)
+
+// cachedNewsService counts the listings and tags them like the cached
+// repositories
+type cachedNewsService struct {
+	stubNewsService
+	fetches int
+}
+
+func (s *cachedNewsService) Fetch(ctx context.Context, filter domain.NewsFilter) ([]domain.News, int64, error) {
+	s.fetches++
+	cache.Collect(ctx, "news:1", "news:*")
+	return s.stubNewsService.Fetch(ctx, filter)
+}
+
+func newResponseCacheServer() (http.Handler, *cachedNewsService, *cache.Cache) {
+	svc := &cachedNewsService{stubNewsService: stubNewsService{items: []domain.News{{ID: 1, Title: "Health Benefits"}}}}
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+
+	entities := cache.New(10, time.Minute)
+	responses := cache.New(10, time.Minute)
+	entities.Cascade(responses)
+	return rest.RouteNames(rest.ResponseCache(responses)(mux)), svc, entities
+}
+
+func TestResponseCache(t *testing.T) {
+	handler, svc, entities := newResponseCacheServer()
+
+	rr := serve(handler, http.MethodGet, "/news?status=published", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+	body := rr.Body.String()
+
+	rr = serve(handler, http.MethodGet, "/news?status=published", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "HIT", rr.Header().Get("X-Cache"))
+	assert.Equal(t, body, rr.Body.String())
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rr.Header().Get("ETag"))
+	assert.Equal(t, 1, svc.fetches)
+
+	rr = serve(handler, http.MethodHead, "/news?status=published", "", nil)
+	assert.Equal(t, "HIT", rr.Header().Get("X-Cache"))
+	assert.Empty(t, rr.Body.String())
+
+	// other queries, representations and authenticated requests are not
+	// served from the cache
+	serve(handler, http.MethodGet, "/news?status=draft", "", nil)
+	serve(handler, http.MethodGet, "/news?status=published", "", http.Header{"Accept": {"application/xml"}})
+	rr = serve(handler, http.MethodGet, "/news?status=published", "", http.Header{"Authorization": {"Bearer secret"}})
+	assert.Empty(t, rr.Header().Get("X-Cache"))
+	assert.Equal(t, 4, svc.fetches)
+
+	// writing the entities of a response invalidates it
+	entities.Invalidate("news:2")
+	rr = serve(handler, http.MethodGet, "/news?status=published", "", nil)
+	assert.Equal(t, "HIT", rr.Header().Get("X-Cache"))
+	entities.Invalidate("news:1")
+	rr = serve(handler, http.MethodGet, "/news?status=published", "", nil)
+	assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+	assert.Equal(t, 5, svc.fetches)
+}
+
+func TestResponseCacheRoutes(t *testing.T) {
+	handler, svc, _ := newResponseCacheServer()
+
+	// the errors and the routes which are not listed are not cached
+	for i := 0; i < 2; i++ {
+		rr := serve(handler, http.MethodGet, "/news/export?format=ndjson", "", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+
+		rr = serve(handler, http.MethodGet, "/news?fields=views", "", nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+	}
+	assert.Equal(t, 0, svc.fetches)
+}
+
+// joinedNewsRepo serves a news whose author and topic are joined by the
+// news service
+type joinedNewsRepo struct {
+	news.NewsRepository
+}
+
+func (joinedNewsRepo) Fetch(_ context.Context, _ domain.NewsFilter) ([]domain.News, int64, error) {
+	return []domain.News{{ID: 1, Title: "Health Benefits", Author: domain.AuthorNews{ID: 1}}}, 1, nil
+}
+
+type joinedAuthorRepo struct {
+	news.AuthorRepository
+	name string
+}
+
+func (r *joinedAuthorRepo) GetByID(_ context.Context, id int64) (domain.Author, error) {
+	return domain.Author{ID: id, Name: r.name}, nil
+}
+
+type joinedTopicRepo struct {
+	topic.TopicRepository
+	name string
+}
+
+func (r *joinedTopicRepo) GetByID(_ context.Context, id int64) (domain.Topic, error) {
+	return domain.Topic{ID: id, Name: r.name}, nil
+}
+
+func (r *joinedTopicRepo) Update(_ context.Context, t *domain.Topic) error {
+	r.name = t.Name
+	return nil
+}
+
+type joinedNewsTopicRepo struct {
+	news.NewsTopicRepository
+}
+
+func (joinedNewsTopicRepo) GetByNewsID(_ context.Context, newsID int64) ([]domain.NewsTopic, error) {
+	return []domain.NewsTopic{{NewsID: newsID, TopicID: 1}}, nil
+}
+
+func TestResponseCacheJoinedEntities(t *testing.T) {
+	entities := cache.New(100, time.Minute)
+	responses := cache.New(10, time.Minute)
+	entities.Cascade(responses)
+
+	authors := &joinedAuthorRepo{name: "Deni"}
+	topics := cached.NewTopicRepository(&joinedTopicRepo{name: "Health"}, entities)
+	svc := news.NewService(
+		cached.NewNewsRepository(joinedNewsRepo{}, entities),
+		cached.NewAuthorRepository(authors, entities),
+		topics,
+		joinedNewsTopicRepo{},
+		nil, nil,
+	)
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+	handler := rest.RouteNames(rest.ResponseCache(responses)(mux))
+
+	for _, path := range []string{"/news/1", "/news"} {
+		serve(handler, http.MethodGet, path, "", nil)
+		rr := serve(handler, http.MethodGet, path, "", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "HIT", rr.Header().Get("X-Cache"), path)
+		assert.Contains(t, rr.Body.String(), `"name":"Health"`)
+	}
+
+	// renaming a topic invalidates the news carrying it
+	require.NoError(t, topics.Update(context.Background(), &domain.Topic{ID: 1, Name: "Wellness"}))
+	for _, path := range []string{"/news/1", "/news"} {
+		rr := serve(handler, http.MethodGet, path, "", nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "MISS", rr.Header().Get("X-Cache"), path)
+		assert.Contains(t, rr.Body.String(), `"name":"Wellness"`)
+	}
+
+	// and so does any change of their author
+	authors.name = "Deni Saputra"
+	entities.Invalidate("author:1")
+	rr := serve(handler, http.MethodGet, "/news/1", "", nil)
+	assert.Equal(t, "MISS", rr.Header().Get("X-Cache"))
+	assert.Contains(t, rr.Body.String(), `"name":"Deni Saputra"`)
+}
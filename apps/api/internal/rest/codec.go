@@ -0,0 +1,292 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+)
+
+// Codec encodes responses and decodes request bodies of one media type
+type Codec interface {
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+type codecEntry struct {
+	format     string
+	mediaTypes []string // the first one is sent as Content-Type
+	codec      Codec
+}
+
+// codecs lists the registered codecs, the first one is the default
+var codecs []codecEntry
+
+func init() {
+	RegisterCodec("json", jsonCodec{}, "application/json")
+	RegisterCodec("xml", xmlCodec{}, "application/xml", "text/xml")
+	RegisterCodec("msgpack", msgpackCodec{}, "application/msgpack", "application/x-msgpack", "application/vnd.msgpack")
+	RegisterCodec("cbor", cborCodec{}, "application/cbor")
+}
+
+// RegisterCodec makes a codec available under a format name, used by the
+// format query parameter, and media types, used by the Accept and
+// Content-Type headers
+func RegisterCodec(format string, c Codec, mediaTypes ...string) {
+	codecs = append(codecs, codecEntry{format: format, mediaTypes: mediaTypes, codec: c})
+}
+
+func codecByFormat(format string) (codecEntry, bool) {
+	for _, c := range codecs {
+		if c.format == format {
+			return c, true
+		}
+	}
+	return codecEntry{}, false
+}
+
+func codecByMediaType(mediaType string) (codecEntry, bool) {
+	for _, c := range codecs {
+		for _, t := range c.mediaTypes {
+			if t == mediaType {
+				return c, true
+			}
+		}
+	}
+	return codecEntry{}, false
+}
+
+type acceptedType struct {
+	mediaType string
+	q         float64
+}
+
+// acceptCodec picks the codec of the preferred media type of an Accept
+// header, wildcards select the default codec
+func acceptCodec(accept string) (codecEntry, error) {
+	if strings.TrimSpace(accept) == "" {
+		return codecs[0], nil
+	}
+
+	var accepted []acceptedType
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if qStr, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qStr, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			accepted = append(accepted, acceptedType{mediaType: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].q > accepted[j].q
+	})
+
+	for _, a := range accepted {
+		if a.mediaType == "*/*" {
+			return codecs[0], nil
+		}
+		if c, ok := codecByMediaType(a.mediaType); ok {
+			return c, nil
+		}
+		if prefix := strings.TrimSuffix(a.mediaType, "*"); strings.HasSuffix(prefix, "/") {
+			for _, c := range codecs {
+				if strings.HasPrefix(c.mediaTypes[0], prefix) {
+					return c, nil
+				}
+			}
+		}
+	}
+	return codecEntry{}, fmt.Errorf("%w: %s", domain.ErrNotAcceptable, accept)
+}
+
+type codecKey struct{}
+
+// negotiate selects the response codec from the format query parameter, or
+// the Accept header, and answers 406 Not Acceptable when none is supported
+func negotiate(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var c codecEntry
+		var err error
+		if format := r.URL.Query().Get("format"); format != "" {
+			var ok bool
+			if c, ok = codecByFormat(format); !ok {
+				err = fmt.Errorf("%w: format %s", domain.ErrNotAcceptable, format)
+			}
+		} else {
+			c, err = acceptCodec(r.Header.Get("Accept"))
+		}
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), codecKey{}, c)))
+	}
+}
+
+// negotiateAccept is negotiate for the endpoints where the format query
+// parameter names a file format
+func negotiateAccept(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, err := acceptCodec(r.Header.Get("Accept"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), codecKey{}, c)))
+	}
+}
+
+func responseCodec(r *http.Request) codecEntry {
+	if c, ok := r.Context().Value(codecKey{}).(codecEntry); ok {
+		return c
+	}
+	return codecs[0]
+}
+
+// writeResponse encodes v with the negotiated codec
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	c := responseCodec(r)
+	w.Header().Set("Content-Type", c.mediaTypes[0])
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	if err := c.codec.Encode(w, v); err != nil {
+		logrus.Error(err)
+	}
+}
+
+// writeError answers with the status code of err and a dto.ResponseError
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	writeResponse(w, r, dto.GetStatusCode(err), dto.ResponseError{Message: err.Error()})
+}
+
+// WriteError answers with the status code of err in the format negotiated
+// from the Accept header, JSON when none is supported. It is meant for the
+// middlewares answering before the routes, e.g. the rate limit.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	if c, acceptErr := acceptCodec(r.Header.Get("Accept")); acceptErr == nil {
+		r = r.WithContext(context.WithValue(r.Context(), codecKey{}, c))
+	}
+	writeError(w, r, err)
+}
+
+// decodeRequest decodes the request body according to its Content-Type,
+// JSON when it is not set
+func decodeRequest(r *http.Request, v interface{}) error {
+	c := codecs[0]
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrUnsupportedMediaType, err)
+		}
+		var ok bool
+		if c, ok = codecByMediaType(mediaType); !ok {
+			return fmt.Errorf("%w: %s", domain.ErrUnsupportedMediaType, mediaType)
+		}
+	}
+
+	if err := c.codec.Decode(r.Body, v); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrUnprocessableEntity, err)
+	}
+	return nil
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// xmlCodec names the root element after the type of the value, e.g. news or
+// import_report, and wraps slices in an items element
+type xmlCodec struct{}
+
+func (xmlCodec) Encode(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() == reflect.Slice {
+		v = struct {
+			Items interface{} `xml:"item"`
+		}{v}
+		value = reflect.ValueOf(v)
+	}
+	name := "items"
+	if value.Type().Name() != "" {
+		name = xmlName(value.Type().Name())
+	}
+	return xml.NewEncoder(w).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}})
+}
+
+func (xmlCodec) Decode(r io.Reader, v interface{}) error {
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// xmlName turns a Go type name into a snake case element name
+func xmlName(typeName string) string {
+	var b strings.Builder
+	for i, r := range typeName {
+		if unicode.IsUpper(r) {
+			if i > 0 && !unicode.IsUpper(rune(typeName[i-1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// msgpackCodec and cborCodec reuse the json struct tags
+type msgpackCodec struct{}
+
+func (msgpackCodec) Encode(w io.Writer, v interface{}) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	return enc.Encode(v)
+}
+
+func (msgpackCodec) Decode(r io.Reader, v interface{}) error {
+	dec := msgpack.NewDecoder(r)
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+var cborEncMode, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano, TimeTag: cbor.EncTagRequired}.EncMode()
+
+type cborCodec struct{}
+
+func (cborCodec) Encode(w io.Writer, v interface{}) error {
+	return cborEncMode.NewEncoder(w).Encode(v)
+}
+
+func (cborCodec) Decode(r io.Reader, v interface{}) error {
+	return cbor.NewDecoder(r).Decode(v)
+}
@@ -0,0 +1,162 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type storeNewsService struct {
+	stubNewsService
+	stored *news.CreateNewsReq
+}
+
+func (s *storeNewsService) Store(_ context.Context, req *news.CreateNewsReq) error {
+	s.stored = req
+	return nil
+}
+
+func newCodecServer() (*http.ServeMux, *storeNewsService) {
+	createdAt := time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)
+	svc := &storeNewsService{stubNewsService: stubNewsService{items: []domain.News{{
+		ID:        1,
+		Title:     "Health Benefits",
+		Author:    domain.AuthorNews{ID: 2, Name: "Deni"},
+		Status:    domain.Published,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Topics:    []domain.TopicNews{{ID: 1, Name: "Health"}},
+	}}}}
+
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+	return mux, svc
+}
+
+func serve(mux http.Handler, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestNegotiation(t *testing.T) {
+	mux, _ := newCodecServer()
+
+	rr := serve(mux, http.MethodGet, "/news", "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	rr = serve(mux, http.MethodGet, "/news", "", http.Header{"Accept": {"text/html, application/xml;q=0.9, */*;q=0.1"}})
+	assert.Equal(t, "application/xml", rr.Header().Get("Content-Type"))
+	var doc struct {
+		XMLName xml.Name `xml:"response"`
+		Titles  []string `xml:"data>item>title"`
+		Topics  []string `xml:"data>item>topics>topic>name"`
+		Total   int64    `xml:"meta>total_data"`
+	}
+	require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, []string{"Health Benefits"}, doc.Titles)
+	assert.Equal(t, []string{"Health"}, doc.Topics)
+	assert.Equal(t, int64(1), doc.Total)
+
+	rr = serve(mux, http.MethodGet, "/news/1", "", http.Header{"Accept": {"application/x-msgpack"}})
+	assert.Equal(t, "application/msgpack", rr.Header().Get("Content-Type"))
+	var item map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(rr.Body.Bytes(), &item))
+	assert.Equal(t, "Health Benefits", item["title"])
+
+	rr = serve(mux, http.MethodGet, "/news/1?format=cbor", "", http.Header{"Accept": {"application/json"}})
+	assert.Equal(t, "application/cbor", rr.Header().Get("Content-Type"))
+	var got domain.News
+	require.NoError(t, cbor.Unmarshal(rr.Body.Bytes(), &got))
+	assert.Equal(t, "Deni", got.Author.Name)
+	assert.True(t, got.CreatedAt.Equal(time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)))
+
+	rr = serve(mux, http.MethodGet, "/news", "", http.Header{"Accept": {"text/html"}})
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	rr = serve(mux, http.MethodGet, "/news?format=yaml", "", nil)
+	assert.Equal(t, http.StatusNotAcceptable, rr.Code)
+}
+
+func TestErrorEncoding(t *testing.T) {
+	mux, _ := newCodecServer()
+
+	rr := serve(mux, http.MethodGet, "/news/abc", "", http.Header{"Accept": {"application/xml"}})
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	var doc struct {
+		XMLName xml.Name `xml:"response_error"`
+		Message string   `xml:"message"`
+	}
+	require.NoError(t, xml.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, domain.ErrNotFound.Error(), doc.Message)
+
+	rr = serve(mux, http.MethodPatch, "/news", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, domain.ErrMethodNotAllowed.Error(), body["message"])
+}
+
+func TestWriteError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/news", nil)
+	req.Header.Set("Accept", "application/xml")
+	rr := httptest.NewRecorder()
+	rest.WriteError(rr, req, domain.ErrTooManyRequests)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "application/xml", rr.Header().Get("Content-Type"))
+
+	// Unsupported media types fall back to JSON
+	req.Header.Set("Accept", "text/csv")
+	rr = httptest.NewRecorder()
+	rest.WriteError(rr, req, domain.ErrTooManyRequests)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, domain.ErrTooManyRequests.Error(), body["message"])
+}
+
+func TestRequestDecoding(t *testing.T) {
+	mux, svc := newCodecServer()
+
+	body := `<news><title>Mars</title><content>Landing</content><author_id>2</author_id>` +
+		`<status>draft</status><topic_ids><topic_id>1</topic_id><topic_id>3</topic_id></topic_ids></news>`
+	rr := serve(mux, http.MethodPost, "/news", body, http.Header{"Content-Type": {"application/xml; charset=utf-8"}})
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	require.NotNil(t, svc.stored)
+	assert.Equal(t, "Mars", svc.stored.Title)
+	assert.Equal(t, []int64{1, 3}, svc.stored.TopicIDs)
+
+	payload, err := cbor.Marshal(map[string]interface{}{
+		"title": "Venus", "content": "Orbit", "author_id": 2, "status": "draft", "topic_ids": []int64{2},
+	})
+	require.NoError(t, err)
+	rr = serve(mux, http.MethodPost, "/news", string(payload), http.Header{"Content-Type": {"application/cbor"}})
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "Venus", svc.stored.Title)
+
+	rr = serve(mux, http.MethodPost, "/news", "title=Mars", http.Header{"Content-Type": {"text/plain"}})
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+
+	rr = serve(mux, http.MethodPost, "/news", "{", http.Header{"Content-Type": {"application/json"}})
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+}
@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// strongETag returns a strong entity tag for the given representation
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified sets the ETag and Last-Modified headers and reports whether the
+// client copy is still fresh according to If-None-Match or If-Modified-Since,
+// in which case a 304 has been written.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	fresh := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		// If-Modified-Since is ignored when If-None-Match is present (RFC 9110)
+		fresh = etagMatch(inm, etag, true)
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			fresh = !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+
+	if fresh {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return fresh
+}
+
+// etagMatch reports whether etag is listed in the header value, weak
+// comparison ignores the W/ prefix
+func etagMatch(header, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+			etag = strings.TrimPrefix(etag, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ConcurrencyConfig tells how the update and delete endpoints of news and
+// topics use the If-Match header
+type ConcurrencyConfig struct {
+	// RequireIfMatch answers 428 Precondition Required to the changes sent
+	// without If-Match, instead of applying them whatever the version
+	RequireIfMatch bool
+}
+
+// versionETag returns the strong entity tag of a representation of an item
+// version. The hash of the body tells apart the media types and the names of
+// the joined authors and topics, which change without the item version.
+func versionETag(version int64, body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + strconv.FormatInt(version, 10) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// expectedVersion reads the version the change of r applies to from its
+// If-Match header, 0 meaning any version. It takes the ETag of any
+// representation of the item, or "<version>". Tags that can not match a
+// version, like weak ones, fail the precondition.
+func (c ConcurrencyConfig) expectedVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch header {
+	case "":
+		if c.RequireIfMatch {
+			return 0, domain.ErrPreconditionRequired
+		}
+		return 0, nil
+	case "*":
+		return 0, nil
+	}
+
+	tag, ok := strings.CutPrefix(header, `"`)
+	if tag, ok = strings.CutSuffix(tag, `"`); !ok {
+		return 0, fmt.Errorf("%w: If-Match %s", domain.ErrPreconditionFailed, header)
+	}
+	versionStr, _, _ := strings.Cut(tag, "-")
+	version, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil || version < 1 || strconv.FormatInt(version, 10) != versionStr {
+		return 0, fmt.Errorf("%w: If-Match %s", domain.ErrPreconditionFailed, header)
+	}
+	return version, nil
+}
+
+// writeConditional answers 200 with v encoded by the negotiated codec, or 304
+// when the client copy is still fresh. The ETag is the versionETag of the body
+// when version is set, a hash of the body otherwise.
+func writeConditional(w http.ResponseWriter, r *http.Request, v interface{}, version int64, lastModified time.Time) {
+	c := responseCodec(r)
+	var body bytes.Buffer
+	if err := c.codec.Encode(&body, v); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	etag := strongETag(body.Bytes())
+	if version != 0 {
+		etag = versionETag(version, body.Bytes())
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if notModified(w, r, etag, lastModified) {
+		return
+	}
+	w.Header().Set("Content-Type", c.mediaTypes[0])
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		logrus.Error(err)
+	}
+}
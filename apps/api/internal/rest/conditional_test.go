@@ -0,0 +1,148 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+// versionedNewsService applies changes like the repository, only at the
+// current version when one is given
+type versionedNewsService struct {
+	stubNewsService
+	updated bool
+	deleted []int64
+}
+
+func (s *versionedNewsService) Update(_ context.Context, req *news.UpdateNewsReq) error {
+	if req.Version != nil && *req.Version != s.items[0].Version {
+		return domain.ErrPreconditionFailed
+	}
+	s.items[0].Version++
+	s.updated = true
+	return nil
+}
+
+func (s *versionedNewsService) Delete(_ context.Context, _ int64, version int64) error {
+	if version != 0 && version != s.items[0].Version {
+		return domain.ErrPreconditionFailed
+	}
+	s.deleted = append(s.deleted, version)
+	return nil
+}
+
+func newVersionedServer(cfg rest.ConcurrencyConfig) (*http.ServeMux, *versionedNewsService) {
+	updatedAt := time.Date(2024, 10, 28, 9, 15, 0, 0, time.UTC)
+	svc := &versionedNewsService{stubNewsService: stubNewsService{items: []domain.News{
+		{ID: 1, Title: "Health Benefits", Status: domain.Published, Version: 3, CreatedAt: updatedAt, UpdatedAt: updatedAt},
+	}}}
+
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, cfg)
+	return mux, svc
+}
+
+func TestConditionalReads(t *testing.T) {
+	mux, svc := newVersionedServer(rest.ConcurrencyConfig{})
+
+	rr := serve(mux, http.MethodGet, "/news/1", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	etag := rr.Header().Get("ETag")
+	assert.Regexp(t, `^"3-[0-9a-f]{16}"$`, etag)
+	assert.Equal(t, "Mon, 28 Oct 2024 09:15:00 GMT", rr.Header().Get("Last-Modified"))
+
+	rr = serve(mux, http.MethodGet, "/news/1", "", http.Header{"If-None-Match": {`"2", ` + etag}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	rr = serve(mux, http.MethodGet, "/news/1", "", http.Header{"If-Modified-Since": {"Mon, 28 Oct 2024 10:00:00 GMT"}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	// every media type has its own tag
+	rr = serve(mux, http.MethodGet, "/news/1", "", http.Header{"If-None-Match": {etag}, "Accept": {"application/xml"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Regexp(t, `^"3-[0-9a-f]{16}"$`, rr.Header().Get("ETag"))
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+
+	// so has every name of the joined author
+	svc.items[0].Author = domain.AuthorNews{ID: 1, Name: "Deni"}
+	rr = serve(mux, http.MethodGet, "/news/1", "", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+
+	// a newer version is sent again
+	svc.items[0].Version = 4
+	rr = serve(mux, http.MethodGet, "/news/1", "", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Regexp(t, `^"4-[0-9a-f]{16}"$`, rr.Header().Get("ETag"))
+
+	// lists are tagged with a hash of their representation
+	rr = serve(mux, http.MethodGet, "/news", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	etag = rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rr = serve(mux, http.MethodGet, "/news", "", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	rr = serve(mux, http.MethodGet, "/news", "", http.Header{"If-None-Match": {etag}, "Accept": {"application/xml"}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+}
+
+// replaceBody is a full replacement of a news
+const replaceBody = `{"title": "Health", "content": "Body", "author_id": 1, "status": "draft", "topic_ids": [1]}`
+
+func TestConditionalWrites(t *testing.T) {
+	mux, svc := newVersionedServer(rest.ConcurrencyConfig{})
+
+	rr := serve(mux, http.MethodPut, "/news/1", replaceBody, http.Header{"If-Match": {`"2"`}})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	assert.False(t, svc.updated)
+
+	// weak tags never match for changes
+	rr = serve(mux, http.MethodPut, "/news/1", replaceBody, http.Header{"If-Match": {`W/"3"`}})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	assert.False(t, svc.updated)
+
+	// the ETag of any representation, or the bare version, applies changes
+	etag := serve(mux, http.MethodGet, "/news/1", "", http.Header{"Accept": {"application/xml"}}).Header().Get("ETag")
+	rr = serve(mux, http.MethodPut, "/news/1", replaceBody, http.Header{"If-Match": {etag}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.True(t, svc.updated)
+
+	rr = serve(mux, http.MethodPut, "/news/1", replaceBody, http.Header{"If-Match": {`"4"`}})
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = serve(mux, http.MethodDelete, "/news/1", "", http.Header{"If-Match": {etag}})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+	rr = serve(mux, http.MethodDelete, "/news/1", "", nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, []int64{0}, svc.deleted)
+}
+
+func TestRequiredIfMatch(t *testing.T) {
+	mux, svc := newVersionedServer(rest.ConcurrencyConfig{RequireIfMatch: true})
+
+	rr := serve(mux, http.MethodPut, "/news/1", replaceBody, nil)
+	assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+	rr = serve(mux, http.MethodDelete, "/news/1", "", nil)
+	assert.Equal(t, http.StatusPreconditionRequired, rr.Code)
+	assert.False(t, svc.updated)
+	assert.Empty(t, svc.deleted)
+
+	rr = serve(mux, http.MethodDelete, "/news/1", "", http.Header{"If-Match": {"*"}})
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	rr = serve(mux, http.MethodDelete, "/news/1", "", http.Header{"If-Match": {`"3"`}})
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, []int64{0, 3}, svc.deleted)
+}
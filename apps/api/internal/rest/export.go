@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/export"
+)
+
+var (
+	newsExportColumns = []string{
+		"id", "title", "content", "status", "author_id", "author", "topic_ids", "topics", "created_at", "updated_at",
+	}
+	topicExportColumns       = []string{"id", "name", "created_at", "updated_at"}
+	authorStatsExportColumns = []string{
+		"author_id", "name", "total", "draft", "published", "deleted", "average_words", "average_hours_to_publish",
+		"top_topic_ids", "top_topics",
+	}
+)
+
+func newsExportRow(n domain.News) []interface{} {
+	topicIDs := make([]int64, 0, len(n.Topics))
+	topics := make([]string, 0, len(n.Topics))
+	for _, t := range n.Topics {
+		topicIDs = append(topicIDs, t.ID)
+		topics = append(topics, t.Name)
+	}
+	return []interface{}{
+		n.ID, n.Title, n.Content, string(n.Status), n.Author.ID, n.Author.Name, topicIDs, topics, n.CreatedAt, n.UpdatedAt,
+	}
+}
+
+func topicExportRow(t domain.Topic) []interface{} {
+	return []interface{}{t.ID, t.Name, t.CreatedAt, t.UpdatedAt}
+}
+
+func authorStatsExportRow(s domain.AuthorStats) []interface{} {
+	topicIDs := make([]int64, 0, len(s.TopTopics))
+	topics := make([]string, 0, len(s.TopTopics))
+	for _, t := range s.TopTopics {
+		topicIDs = append(topicIDs, t.ID)
+		topics = append(topics, t.Name)
+	}
+	var hoursToPublish interface{}
+	if s.AverageHoursToPublish != nil {
+		hoursToPublish = *s.AverageHoursToPublish
+	}
+	return []interface{}{
+		s.AuthorID, s.Name, s.Total, s.Draft, s.Published, s.Deleted, s.AverageWords, hoursToPublish, topicIDs, topics,
+	}
+}
+
+// writeExport streams the rows produced by fill in the format of the format
+// query parameter, csv by default. Errors are reported with their status code
+// as long as nothing has been sent.
+func writeExport(w http.ResponseWriter, r *http.Request, name string, columns []string, fill func(write func(row []interface{}) error) error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	ew := &exportResponseWriter{ResponseWriter: w}
+	writer, err := export.NewWriter(ew, format, columns)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Dumps outlive the server write timeout
+	if err = http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		logrus.Debug(err)
+	}
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	if err = fill(writer.Write); err == nil {
+		err = writer.Close()
+	}
+	switch {
+	case err != nil && !ew.written:
+		w.Header().Del("Content-Disposition")
+		writeError(w, r, err)
+	case err != nil:
+		// the client gets a truncated file, the connection is closed without
+		// the final chunk
+		logrus.Error(err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportResponseWriter tracks whether the body has been started
+type exportResponseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (e *exportResponseWriter) Write(b []byte) (int, error) {
+	e.written = true
+	return e.ResponseWriter.Write(b)
+}
+
+// Export streams the news matching the same filters as Fetch, all of them
+// unless limit is given, as CSV, NDJSON or XLSX
+func (a *NewsHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filter := parseNewsFilter(r.URL.Query())
+	writeExport(w, r, "news", newsExportColumns, func(write func(row []interface{}) error) error {
+		return a.Service.Stream(r.Context(), filter, func(n domain.News) error {
+			return write(newsExportRow(n))
+		})
+	})
+}
+
+// Export streams the topics matching the same filters as Fetch, all of them
+// unless limit is given, as CSV, NDJSON or XLSX
+func (a *TopicHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filter := parseTopicFilter(r.URL.Query())
+	writeExport(w, r, "topics", topicExportColumns, func(write func(row []interface{}) error) error {
+		return a.Service.Stream(r.Context(), filter, func(t domain.Topic) error {
+			return write(topicExportRow(t))
+		})
+	})
+}
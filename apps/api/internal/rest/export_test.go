@@ -0,0 +1,85 @@
+package rest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+func (s *stubNewsService) Stream(_ context.Context, filter domain.NewsFilter, fn func(domain.News) error) error {
+	s.filter = filter
+	if filter.Status == "broken" {
+		return errors.New("connection lost")
+	}
+	for _, n := range s.items {
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubTopicService) Stream(_ context.Context, _ domain.TopicFilter, fn func(domain.Topic) error) error {
+	return fn(domain.Topic{ID: 1, Name: "Health"})
+}
+
+func TestExport(t *testing.T) {
+	createdAt := time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)
+	newsSvc := &stubNewsService{items: []domain.News{{
+		ID:        1,
+		Title:     "Health Benefits",
+		Content:   "Body",
+		Author:    domain.AuthorNews{ID: 2, Name: "Deni"},
+		Status:    domain.Published,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Topics:    []domain.TopicNews{{ID: 1, Name: "Health"}, {ID: 4, Name: "Environment"}},
+	}}}
+
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, newsSvc, rest.ConcurrencyConfig{})
+	rest.NewTopicHandler(mux, &stubTopicService{}, rest.ConcurrencyConfig{})
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news/export?status=published&topic_id=1", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="news.csv"`, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,title,content,status,author_id,author,topic_ids,topics,created_at,updated_at\n"+
+		"1,Health Benefits,Body,published,2,Deni,1|4,Health|Environment,2024-10-28T09:00:00Z,2024-10-28T09:00:00Z\n",
+		rr.Body.String())
+	assert.Equal(t, domain.NewsFilter{Status: "published", TopicID: 1}, newsSvc.filter)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news/export?format=ndjson", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"author":"Deni","topic_ids":[1,4],"topics":["Health","Environment"]`)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/topic/export?format=xlsx", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `attachment; filename="topics.xlsx"`, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, "PK", rr.Body.String()[:2])
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news/export?format=pdf", nil))
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), `"message":`)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/news/export?status=broken", nil))
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"message":"connection lost"}`, rr.Body.String())
+}
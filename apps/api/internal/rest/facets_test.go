@@ -0,0 +1,72 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type facetsNewsService struct {
+	stubNewsService
+	facetFilter domain.NewsFilter
+	facets      []string
+}
+
+func (s *facetsNewsService) Facets(_ context.Context, filter domain.NewsFilter, facets []string) ([]domain.Facet, error) {
+	s.facetFilter = filter
+	s.facets = facets
+	res := make([]domain.Facet, len(facets))
+	for i, name := range facets {
+		res[i] = domain.Facet{Name: name, Values: []domain.FacetValue{{Value: "1", Label: "Health", Count: 3}}}
+	}
+	return res, nil
+}
+
+func TestFacets(t *testing.T) {
+	svc := &facetsNewsService{stubNewsService: stubNewsService{items: []domain.News{{ID: 1}}}}
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+
+	rr := serve(mux, http.MethodGet, "/news", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "facets")
+	assert.Nil(t, svc.facets)
+
+	rr = serve(mux, http.MethodGet, "/news?status=published&topic_id=1&facets=topic,status,topic", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, []string{"topic", "status"}, svc.facets)
+	assert.Equal(t, "published", svc.facetFilter.Status)
+	assert.Equal(t, int64(1), svc.facetFilter.TopicID)
+	var res struct {
+		Meta dto.PaginationMeta `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, int64(1), res.Meta.TotalData)
+	assert.Equal(t, []domain.Facet{
+		{Name: "topic", Values: []domain.FacetValue{{Value: "1", Label: "Health", Count: 3}}},
+		{Name: "status", Values: []domain.FacetValue{{Value: "1", Label: "Health", Count: 3}}},
+	}, res.Meta.Facets)
+
+	rr = serve(mux, http.MethodGet, "/news?facets=topic", "", http.Header{"Accept": {"application/xml"}})
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "<facets><facet><name>topic</name><values><value><value>1</value>")
+
+	rr = serve(mux, http.MethodGet, "/news?facets=status,color", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestWithoutFacet(t *testing.T) {
+	filter := domain.NewsFilter{Status: "draft", TopicID: 2, AuthorID: 3, Title: "covid"}
+
+	assert.Equal(t, domain.NewsFilter{TopicID: 2, AuthorID: 3, Title: "covid"}, filter.WithoutFacet("status"))
+	assert.Equal(t, domain.NewsFilter{Status: "draft", AuthorID: 3, Title: "covid"}, filter.WithoutFacet("topic"))
+	assert.Equal(t, domain.NewsFilter{Status: "draft", TopicID: 2, Title: "covid"}, filter.WithoutFacet("author"))
+}
@@ -0,0 +1,155 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/feed"
+)
+
+const defaultFeedLimit = 50
+
+// FeedConfig describes the site the feeds are published for
+type FeedConfig struct {
+	// SiteURL is the public base URL of the news pages, e.g. https://news.example.com
+	SiteURL     string
+	Title       string
+	Description string
+	// Limit is the number of news per feed
+	Limit int64
+}
+
+// FeedHandler represents the HTTP handler for RSS and Atom feeds
+type FeedHandler struct {
+	NewsService   NewsService
+	TopicService  TopicService
+	AuthorService AuthorService
+	Config        FeedConfig
+}
+
+// NewFeedHandler initializes the feed endpoints
+func NewFeedHandler(mux *http.ServeMux, newsSvc NewsService, topicSvc TopicService, authorSvc AuthorService, cfg FeedConfig) {
+	if cfg.Limit <= 0 {
+		cfg.Limit = defaultFeedLimit
+	}
+	cfg.SiteURL = strings.TrimSuffix(cfg.SiteURL, "/")
+
+	handler := &FeedHandler{
+		NewsService:   newsSvc,
+		TopicService:  topicSvc,
+		AuthorService: authorSvc,
+		Config:        cfg,
+	}
+	handleRoutes(mux, map[string]http.HandlerFunc{
+		"feed.news":   handler.Feed(""),
+		"feed.topic":  handler.Feed("topic"),
+		"feed.author": handler.Feed("author"),
+	})
+}
+
+// Feed serves the feeds of a scope: /feeds/news.{rss,atom} without one,
+// /feeds/topic/{id}.{rss,atom} and /feeds/author/{id}.{rss,atom}
+func (a *FeedHandler) Feed(scope string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		a.feed(w, r, scope)
+	}
+}
+
+func (a *FeedHandler) feed(w http.ResponseWriter, r *http.Request, scope string) {
+	file := r.PathValue("file")
+	format := path.Ext(file)
+	if format != ".rss" && format != ".atom" {
+		writeError(w, r, domain.ErrNotFound)
+		return
+	}
+
+	f := feed.Feed{
+		Title:       a.Config.Title,
+		Description: a.Config.Description,
+		Link:        a.Config.SiteURL,
+		SelfLink:    a.Config.SiteURL + r.URL.Path,
+		ItemLink: func(n domain.News) string {
+			return fmt.Sprintf("%s/news/%d", a.Config.SiteURL, n.ID)
+		},
+	}
+	filter := domain.NewsFilter{
+		Status:    string(domain.Published),
+		SortBy:    "created_at",
+		SortOrder: "desc",
+		Limit:     a.Config.Limit,
+		Page:      1,
+	}
+
+	ctx := r.Context()
+	idStr := strings.TrimSuffix(file, format)
+	switch {
+	case scope == "" && idStr == "news":
+	case scope == "topic":
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			writeError(w, r, domain.ErrNotFound)
+			return
+		}
+		t, err := a.TopicService.GetByID(ctx, id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		filter.TopicID = t.ID
+		f.Title = fmt.Sprintf("%s - %s", a.Config.Title, t.Name)
+		f.Description = fmt.Sprintf("Latest published news about %s", t.Name)
+	case scope == "author":
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			writeError(w, r, domain.ErrNotFound)
+			return
+		}
+		authors, err := a.AuthorService.AuthorsByIDs(ctx, []int64{id})
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if len(authors) == 0 {
+			writeError(w, r, domain.ErrNotFound)
+			return
+		}
+		filter.AuthorID = authors[0].ID
+		f.Title = fmt.Sprintf("%s - %s", a.Config.Title, authors[0].Name)
+		f.Description = fmt.Sprintf("Latest published news by %s", authors[0].Name)
+	default:
+		writeError(w, r, domain.ErrNotFound)
+		return
+	}
+
+	items, _, err := a.NewsService.Fetch(ctx, filter)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		writeError(w, r, err)
+		return
+	}
+	f.Items = items
+
+	render, contentType := feed.RSS, feed.ContentTypeRSS
+	if format == ".atom" {
+		render, contentType = feed.Atom, feed.ContentTypeAtom
+	}
+	body, err := render(f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if notModified(w, r, strongETag(body), f.Updated()) {
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	_, err = w.Write(body)
+	if err != nil {
+		return
+	}
+}
@@ -0,0 +1,118 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type stubNewsService struct {
+	rest.NewsService
+	items  []domain.News
+	filter domain.NewsFilter
+}
+
+func (s *stubNewsService) Fetch(_ context.Context, filter domain.NewsFilter) ([]domain.News, int64, error) {
+	s.filter = filter
+	return s.items, int64(len(s.items)), nil
+}
+
+type stubTopicService struct {
+	rest.TopicService
+}
+
+func (s *stubTopicService) GetByID(_ context.Context, id int64) (domain.Topic, error) {
+	if id != 1 {
+		return domain.Topic{}, domain.ErrNotFound
+	}
+	return domain.Topic{ID: 1, Name: "Health"}, nil
+}
+
+func (s *stubAuthorService) AuthorsByIDs(_ context.Context, ids []int64) ([]domain.Author, error) {
+	var authors []domain.Author
+	for _, id := range ids {
+		if id == 2 {
+			authors = append(authors, domain.Author{ID: 2, Name: "Deni"})
+		}
+	}
+	return authors, nil
+}
+
+func newFeedServer() (*http.ServeMux, *stubNewsService) {
+	updatedAt := time.Date(2024, 10, 28, 9, 15, 0, 0, time.UTC)
+	newsSvc := &stubNewsService{items: []domain.News{
+		{ID: 1, Title: "Health Benefits", Status: domain.Published, CreatedAt: updatedAt, UpdatedAt: updatedAt},
+	}}
+
+	mux := http.NewServeMux()
+	rest.NewFeedHandler(mux, newsSvc, &stubTopicService{}, &stubAuthorService{}, rest.FeedConfig{
+		SiteURL: "https://news.example.com/",
+		Title:   "News",
+	})
+	return mux, newsSvc
+}
+
+func TestFeed(t *testing.T) {
+	mux, newsSvc := newFeedServer()
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/news.rss", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/rss+xml; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Mon, 28 Oct 2024 09:15:00 GMT", rr.Header().Get("Last-Modified"))
+	assert.Contains(t, rr.Body.String(), "<guid isPermaLink=\"true\">https://news.example.com/news/1</guid>")
+	assert.Equal(t, string(domain.Published), newsSvc.filter.Status)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/topic/1.atom", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal(t, int64(1), newsSvc.filter.TopicID)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/author/2.rss", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(2), newsSvc.filter.AuthorID)
+	assert.Contains(t, rr.Body.String(), "<title>News - Deni</title>")
+
+	for _, target := range []string{"/feeds/topic/2.rss", "/feeds/author/3.rss", "/feeds/topic/1/news.rss", "/feeds/topic/abc.rss", "/feeds/news.json", "/feeds/other.rss"} {
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code, target)
+	}
+}
+
+func TestFeedConditionalRequests(t *testing.T) {
+	mux, _ := newFeedServer()
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/feeds/news.atom", nil))
+	etag := rr.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/news.atom", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/news.atom", nil)
+	req.Header.Set("If-Modified-Since", "Mon, 28 Oct 2024 09:15:00 GMT")
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/feeds/news.atom", nil)
+	req.Header.Set("If-Modified-Since", "Mon, 28 Oct 2024 09:00:00 GMT")
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,121 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+func newFieldsServer() (*http.ServeMux, *stubNewsService) {
+	updatedAt := time.Date(2024, 10, 28, 9, 15, 0, 0, time.UTC)
+	svc := &stubNewsService{items: []domain.News{{
+		ID:        1,
+		Title:     "Health Benefits",
+		Content:   "Body",
+		Author:    domain.AuthorNews{ID: 2, Name: "Deni"},
+		Status:    domain.Published,
+		Version:   3,
+		CreatedAt: updatedAt,
+		UpdatedAt: updatedAt,
+	}}}
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+	return mux, svc
+}
+
+func TestSparseFieldsets(t *testing.T) {
+	mux, svc := newFieldsServer()
+
+	rr := serve(mux, http.MethodGet, "/news?fields=id,title,%20topics", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, []string{"id", "title", "topics"}, svc.filter.Fields)
+	assert.Nil(t, svc.filter.Include)
+
+	var res struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	assert.Equal(t, []map[string]interface{}{
+		{"id": float64(1), "title": "Health Benefits", "topics": []interface{}{}},
+	}, res.Data)
+
+	// every format encodes the partial representation
+	rr = serve(mux, http.MethodGet, "/news?fields=title", "", http.Header{"Accept": {"application/xml"}})
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "<item><title>Health Benefits</title></item>")
+
+	rr = serve(mux, http.MethodGet, "/news?fields=id,views", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `unknown field \"views\"`)
+	rr = serve(mux, http.MethodGet, "/news?include=comments", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestInclude(t *testing.T) {
+	mux, svc := newFieldsServer()
+
+	rr := serve(mux, http.MethodGet, "/news", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Nil(t, svc.filter.Fields)
+	assert.Nil(t, svc.filter.Include)
+	assert.True(t, svc.filter.Expands("author"))
+
+	// an empty include expands nothing
+	rr = serve(mux, http.MethodGet, "/news?include=", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, []string{}, svc.filter.Include)
+	assert.False(t, svc.filter.Expands("author"))
+	assert.True(t, svc.filter.Selects("author"))
+
+	svc.items[0].Topics = []domain.TopicNews{{ID: 1, Name: "Health"}}
+	rr = serve(mux, http.MethodGet, "/news?include=topics", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, svc.filter.Expands("author"))
+	assert.True(t, svc.filter.Expands("topics"))
+
+	// the names of the relations which are not expanded are left out
+	var res struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &res))
+	require.Len(t, res.Data, 1)
+	assert.Equal(t, map[string]interface{}{"id": float64(2)}, res.Data[0]["author"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"id": float64(1), "name": "Health"}}, res.Data[0]["topics"])
+	assert.Equal(t, "Health Benefits", res.Data[0]["title"])
+	assert.NotNil(t, res.Data[0]["links"])
+
+	// and the expanded ones are kept
+	rr = serve(mux, http.MethodGet, "/news/1?fields=author", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"author": {"id": 2, "name": "Deni"}}`, rr.Body.String())
+
+	// topics are neither loaded nor expanded when not selected
+	rr = serve(mux, http.MethodGet, "/news?fields=id&include=topics", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, svc.filter.Expands("topics"))
+}
+
+func TestSparseDetail(t *testing.T) {
+	mux, svc := newFieldsServer()
+
+	rr := serve(mux, http.MethodGet, "/news/1?fields=title,status", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(1), svc.filter.ID)
+	assert.JSONEq(t, `{"title": "Health Benefits", "status": "published"}`, rr.Body.String())
+
+	// the version only tags the full representation
+	etag := rr.Header().Get("ETag")
+	assert.NotEqual(t, `"3"`, etag)
+	rr = serve(mux, http.MethodGet, "/news/1?fields=title,status", "", http.Header{"If-None-Match": {etag}})
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	rr = serve(mux, http.MethodGet, "/news/1?fields=body", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
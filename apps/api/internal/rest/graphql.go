@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/gql"
+)
+
+// GraphQLService executes GraphQL requests
+type GraphQLService interface {
+	Execute(ctx context.Context, req gql.Request, allowMutations bool) (*graphql.Result, error)
+}
+
+// GraphQLHandler represents the HTTP handler of the GraphQL endpoint
+type GraphQLHandler struct {
+	Service GraphQLService
+}
+
+// maxGraphQLRequestSize bounds the body of a GraphQL request
+const maxGraphQLRequestSize = 1 << 20
+
+// NewGraphQLHandler initializes the GraphQL endpoint
+func NewGraphQLHandler(mux *http.ServeMux, svc GraphQLService) {
+	handler := &GraphQLHandler{
+		Service: svc,
+	}
+	mux.HandleFunc("/graphql", handler.Serve)
+}
+
+// Serve handles queries sent with GET, as the query, operationName and
+// variables query parameters, and queries or mutations sent with POST as a
+// JSON body
+func (h *GraphQLHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	var req gql.Request
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				writeGraphQLError(w, http.StatusBadRequest, fmt.Errorf("invalid variables: %v", err))
+				return
+			}
+		}
+	case http.MethodPost:
+		if contentType := r.Header.Get("Content-Type"); contentType != "" {
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || (mediaType != "application/json" && mediaType != "application/graphql+json") {
+				writeGraphQLError(w, http.StatusUnsupportedMediaType, domain.ErrUnsupportedMediaType)
+				return
+			}
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLRequestSize)).Decode(&req); err != nil {
+			writeGraphQLError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeGraphQLError(w, http.StatusMethodNotAllowed, domain.ErrMethodNotAllowed)
+		return
+	}
+
+	if req.Query == "" {
+		writeGraphQLError(w, http.StatusBadRequest, errors.New("missing query"))
+		return
+	}
+
+	result, err := h.Service.Execute(r.Context(), req, r.Method == http.MethodPost)
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusBadRequest
+	}
+	writeGraphQLResult(w, status, result)
+}
+
+func writeGraphQLError(w http.ResponseWriter, status int, err error) {
+	writeGraphQLResult(w, status, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+}
+
+func writeGraphQLResult(w http.ResponseWriter, status int, result *graphql.Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logrus.Error(err)
+	}
+}
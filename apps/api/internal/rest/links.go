@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+)
+
+// LinkConfig configures the base URL of the links in the responses
+type LinkConfig struct {
+	// BaseURL is the public URL of the API, e.g. https://api.example.com,
+	// derived from the requests when empty
+	BaseURL string
+	// TrustForwarded derives the base URL from the Forwarded or
+	// X-Forwarded-Proto and X-Forwarded-Host headers, which must then be set
+	// by a reverse proxy
+	TrustForwarded bool
+}
+
+type baseURLKey struct{}
+
+type versionPrefixKey struct{}
+
+// Links resolves the base URL of the links in the responses of next
+func Links(cfg LinkConfig) func(http.Handler) http.Handler {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			base := baseURL
+			if base == "" {
+				base = requestBaseURL(r, cfg.TrustForwarded)
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), baseURLKey{}, base)))
+		})
+	}
+}
+
+// requestBaseURL returns the scheme and host the request was sent to
+func requestBaseURL(r *http.Request, trustForwarded bool) string {
+	scheme, host := "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if !trustForwarded {
+		return scheme + "://" + host
+	}
+
+	if forwarded := r.Header.Get("Forwarded"); forwarded != "" {
+		// the first element was set by the proxy closest to the client
+		first, _, _ := strings.Cut(forwarded, ",")
+		for _, pair := range strings.Split(first, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			value = strings.Trim(value, `"`)
+			switch {
+			case strings.EqualFold(key, "proto") && value != "":
+				scheme = value
+			case strings.EqualFold(key, "host") && value != "":
+				host = value
+			}
+		}
+		return scheme + "://" + host
+	}
+	if proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ","); proto != "" {
+		scheme = strings.TrimSpace(proto)
+	}
+	if forwardedHost, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Host"), ","); forwardedHost != "" {
+		host = strings.TrimSpace(forwardedHost)
+	}
+	return scheme + "://" + host
+}
+
+// linkURL returns the absolute URL of path, unprefixed like the routes, under
+// the version the request was served at
+func linkURL(r *http.Request, path string, query url.Values) string {
+	base, ok := r.Context().Value(baseURLKey{}).(string)
+	if !ok {
+		base = requestBaseURL(r, false)
+	}
+	prefix, _ := r.Context().Value(versionPrefixKey{}).(string)
+
+	link := base + prefix + path
+	if len(query) > 0 {
+		link += "?" + query.Encode()
+	}
+	return link
+}
+
+// paginate returns the meta of a page of a listing with the links to its
+// pages, which are also sent in the Link header (RFC 8288)
+func paginate(w http.ResponseWriter, r *http.Request, page, limit, totalData int64) dto.PaginationMeta {
+	totalPages := (totalData + limit - 1) / limit
+	lastPage := max(totalPages, 1)
+	pageURL := func(page int64) string {
+		query := r.URL.Query()
+		query.Set("page", strconv.FormatInt(page, 10))
+		return linkURL(r, r.URL.Path, query)
+	}
+
+	links := dto.PaginationLinks{
+		Self:  linkURL(r, r.URL.Path, r.URL.Query()),
+		First: pageURL(1),
+		Last:  pageURL(lastPage),
+	}
+	if page > 1 {
+		links.Prev = pageURL(min(page-1, lastPage))
+	}
+	if page < totalPages {
+		links.Next = pageURL(page + 1)
+	}
+
+	header := make([]string, 0, 5)
+	for _, link := range []struct{ rel, url string }{
+		{"self", links.Self}, {"first", links.First}, {"prev", links.Prev}, {"next", links.Next}, {"last", links.Last},
+	} {
+		if link.url != "" {
+			header = append(header, fmt.Sprintf(`<%s>; rel="%s"`, link.url, link.rel))
+		}
+	}
+	w.Header().Add("Link", strings.Join(header, ", "))
+
+	return dto.PaginationMeta{
+		CurrentPage: page,
+		TotalPages:  totalPages,
+		TotalData:   totalData,
+		Links:       links,
+	}
+}
+
+// linkNews returns a copy of list with the self links of the news
+func linkNews(r *http.Request, list []domain.News) []domain.News {
+	res := make([]domain.News, len(list))
+	for i, n := range list {
+		n.Links = &domain.Links{Self: linkURL(r, routePath("news.get", n.ID), nil)}
+		res[i] = n
+	}
+	return res
+}
+
+// linkTopics returns a copy of list with the self links of the topics
+func linkTopics(r *http.Request, list []domain.Topic) []domain.Topic {
+	res := make([]domain.Topic, len(list))
+	for i, t := range list {
+		t.Links = &domain.Links{Self: linkURL(r, routePath("topic.get", t.ID), nil)}
+		res[i] = t
+	}
+	return res
+}
+
+// linkAuthors returns a copy of list with the self links of the authors
+func linkAuthors(r *http.Request, list []domain.Author) []domain.Author {
+	res := make([]domain.Author, len(list))
+	for i, a := range list {
+		a.Links = &domain.Links{Self: linkURL(r, routePath("author.get", a.ID), nil)}
+		res[i] = a
+	}
+	return res
+}
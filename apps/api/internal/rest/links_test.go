@@ -0,0 +1,94 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+func newLinksServer(cfg rest.LinkConfig) http.Handler {
+	svc := &stubNewsService{}
+	for i := int64(1); i <= 25; i++ {
+		svc.items = append(svc.items, domain.News{ID: i})
+	}
+
+	mux := http.NewServeMux()
+	rest.MountVersions(mux, rest.VersioningConfig{
+		Versions: []rest.APIVersion{{Name: "v1", Register: func(mux *http.ServeMux) {
+			rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+		}}},
+		Alias: "v1",
+	})
+	return rest.Links(cfg)(mux)
+}
+
+func decodeLinks(t *testing.T, body []byte) (dto.PaginationLinks, []domain.News) {
+	var res struct {
+		Data []domain.News      `json:"data"`
+		Meta dto.PaginationMeta `json:"meta"`
+	}
+	require.NoError(t, json.Unmarshal(body, &res))
+	return res.Meta.Links, res.Data
+}
+
+func TestPaginationLinks(t *testing.T) {
+	handler := newLinksServer(rest.LinkConfig{BaseURL: "https://api.example.com/"})
+
+	rr := serve(handler, http.MethodGet, "/v1/news?status=published&limit=10&page=2", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	links, data := decodeLinks(t, rr.Body.Bytes())
+	assert.Equal(t, dto.PaginationLinks{
+		Self:  "https://api.example.com/v1/news?limit=10&page=2&status=published",
+		First: "https://api.example.com/v1/news?limit=10&page=1&status=published",
+		Prev:  "https://api.example.com/v1/news?limit=10&page=1&status=published",
+		Next:  "https://api.example.com/v1/news?limit=10&page=3&status=published",
+		Last:  "https://api.example.com/v1/news?limit=10&page=3&status=published",
+	}, links)
+	assert.Equal(t, `<https://api.example.com/v1/news?limit=10&page=2&status=published>; rel="self", `+
+		`<https://api.example.com/v1/news?limit=10&page=1&status=published>; rel="first", `+
+		`<https://api.example.com/v1/news?limit=10&page=1&status=published>; rel="prev", `+
+		`<https://api.example.com/v1/news?limit=10&page=3&status=published>; rel="next", `+
+		`<https://api.example.com/v1/news?limit=10&page=3&status=published>; rel="last"`, rr.Header().Get("Link"))
+	require.NotEmpty(t, data)
+	assert.Equal(t, &domain.Links{Self: "https://api.example.com/v1/news/1"}, data[0].Links)
+
+	// the last page has no next page, the first no previous one
+	rr = serve(handler, http.MethodGet, "/news?page=3", "", nil)
+	links, _ = decodeLinks(t, rr.Body.Bytes())
+	assert.Equal(t, "https://api.example.com/news?page=2", links.Prev)
+	assert.Empty(t, links.Next)
+
+	rr = serve(handler, http.MethodGet, "/news", "", nil)
+	links, _ = decodeLinks(t, rr.Body.Bytes())
+	assert.Empty(t, links.Prev)
+	assert.Equal(t, "https://api.example.com/news?page=2", links.Next)
+	assert.Equal(t, "https://api.example.com/news?page=3", links.Last)
+}
+
+func TestLinksBaseURL(t *testing.T) {
+	forwarded := http.Header{
+		"X-Forwarded-Proto": {"https"},
+		"X-Forwarded-Host":  {"news.example.com"},
+	}
+
+	// the forwarded headers are ignored unless they are trusted
+	rr := serve(newLinksServer(rest.LinkConfig{}), http.MethodGet, "/v1/news/1", "", forwarded)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"links":{"self":"http://example.com/v1/news/1"}`)
+
+	handler := newLinksServer(rest.LinkConfig{TrustForwarded: true})
+	rr = serve(handler, http.MethodGet, "/v1/news/1", "", forwarded)
+	assert.Contains(t, rr.Body.String(), `"links":{"self":"https://news.example.com/v1/news/1"}`)
+
+	rr = serve(handler, http.MethodGet, "/news/1", "", http.Header{
+		"Forwarded": {`for=192.0.2.60;proto=https;host="api.example.com", for=198.51.100.17`},
+	})
+	assert.Contains(t, rr.Body.String(), `"links":{"self":"https://api.example.com/news/1"}`)
+}
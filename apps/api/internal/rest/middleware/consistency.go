@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/bxcodec/go-clean-arch/internal/repository"
+)
+
+// ReadYourWrites routes the reads of each request to the primary database
+// once the request has written, rather than to a replica which may lag
+func ReadYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(repository.WithSession(r.Context())))
+	})
+}
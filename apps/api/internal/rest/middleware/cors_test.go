@@ -24,8 +24,9 @@ func TestCORS(t *testing.T) {
 
 	assert.Equal(t, http.StatusNoContent, rr.Code)
 	assert.Equal(t, "*", rr.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "GET, POST, PUT, DELETE, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
-	assert.Equal(t, "Content-Type, Authorization", rr.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "GET, POST, PUT, PATCH, DELETE, OPTIONS", rr.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, Authorization, If-Match, If-None-Match, Idempotency-Key", rr.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "ETag, Location, Idempotent-Replayed, Deprecation, Sunset, Link", rr.Header().Get("Access-Control-Expose-Headers"))
 }
 
 func TestCORSWithGET(t *testing.T) {
@@ -0,0 +1,341 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+)
+
+const (
+	// HeaderIdempotencyKey is the header used by clients to make the retries
+	// of a request apply once
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on the responses replayed for a key
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	defaultIdempotencyTTL     = 24 * time.Hour
+	defaultIdempotencyWait    = 10 * time.Second
+	defaultIdempotencyPoll    = 50 * time.Millisecond
+	defaultIdempotencyMaxBody = 8 << 20
+	maxIdempotencyKeyLength   = 255
+)
+
+// IdempotentResponse is a response stored for an idempotency key
+type IdempotentResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// IdempotencyRecord is the state of an idempotency key, Response is nil while
+// the first request sent with the key is in flight
+type IdempotencyRecord struct {
+	Fingerprint string
+	Response    *IdempotentResponse
+}
+
+// IdempotencyStore keeps the idempotency records, in-memory or in a shared
+// backend. Records expire after the ttl given when they are written.
+type IdempotencyStore interface {
+	// Reserve creates the record of key for a request with the given
+	// fingerprint. When key already has a record, it is returned and
+	// reserved is false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration, now time.Time) (rec IdempotencyRecord, reserved bool, err error)
+	// Complete stores the response of the request which reserved key
+	Complete(ctx context.Context, key string, res IdempotentResponse, ttl time.Duration, now time.Time) error
+	// Release removes the record of key, so that the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig configures the Idempotency middleware
+type IdempotencyConfig struct {
+	// TTL is how long responses are replayed, defaults to 24 hours
+	TTL time.Duration
+	// Wait is how long a request waits for an in-flight request with the
+	// same key before being answered 409 Conflict, defaults to 10 seconds
+	Wait time.Duration
+	// MaxBodySize bounds the bodies of requests with a key, defaults to 8 MiB
+	MaxBodySize int64
+	// KeyFunc scopes the keys to a client, defaults to ClientKey. It must not
+	// trust what any client can send, or a client could replay the responses
+	// of another.
+	KeyFunc func(r *http.Request) string
+	// Store defaults to a new MemoryIdempotencyStore
+	Store IdempotencyStore
+	// Skip excludes requests, e.g. the uploads larger than MaxBodySize
+	Skip func(r *http.Request) bool
+	// Clock defaults to time.Now
+	Clock func() time.Time
+}
+
+// Idempotency makes the POST requests sent with an Idempotency-Key header
+// apply once. The response of the first request is stored and replayed for
+// the retries with the same key and body; a key reused with another request
+// is answered 409 Conflict. Retries arriving while the first request is in
+// flight wait for its response. Responses with a 5xx status are not stored,
+// so that the request can be retried.
+func Idempotency(cfg IdempotencyConfig) func(http.Handler) http.Handler {
+	if cfg.TTL <= 0 {
+		cfg.TTL = defaultIdempotencyTTL
+	}
+	if cfg.Wait <= 0 {
+		cfg.Wait = defaultIdempotencyWait
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultIdempotencyMaxBody
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = ClientKey
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryIdempotencyStore()
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = time.Now
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderIdempotencyKey)
+			if r.Method != http.MethodPost || key == "" || (cfg.Skip != nil && cfg.Skip(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: the %s header is longer than %d characters",
+					domain.ErrBadParamInput, HeaderIdempotencyKey, maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBodySize+1))
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err))
+				return
+			}
+			if int64(len(body)) > cfg.MaxBodySize {
+				writeJSONError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("requests with an %s are limited to %d bytes",
+					HeaderIdempotencyKey, cfg.MaxBodySize))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			storeKey := cfg.KeyFunc(r) + ":" + key
+			fingerprint := requestFingerprint(r, body)
+			wait := time.NewTimer(cfg.Wait)
+			defer wait.Stop()
+			for {
+				rec, reserved, err := cfg.Store.Reserve(r.Context(), storeKey, fingerprint, cfg.TTL, cfg.Clock())
+				if err != nil {
+					// Fail open like the rate limit, the request is served
+					// without deduplication
+					logrus.Error(fmt.Errorf("idempotency store: %w", err))
+					next.ServeHTTP(w, r)
+					return
+				}
+				if reserved {
+					serveAndStore(w, r, next, cfg, storeKey)
+					return
+				}
+
+				if rec.Fingerprint != fingerprint {
+					writeJSONError(w, http.StatusConflict, domain.ErrIdempotencyKeyReused)
+					return
+				}
+				if rec.Response != nil {
+					replay(w, *rec.Response)
+					return
+				}
+
+				// the first request is in flight, wait for it to complete or
+				// to be released
+				select {
+				case <-r.Context().Done():
+					return
+				case <-wait.C:
+					w.Header().Set("Retry-After", "1")
+					writeJSONError(w, http.StatusConflict, domain.ErrRequestInProgress)
+					return
+				case <-time.After(defaultIdempotencyPoll):
+				}
+			}
+		})
+	}
+}
+
+// requestFingerprint identifies a request by its method, URL and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s %s\n", r.Method, r.URL.RequestURI())
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// serveAndStore serves the request which reserved key and stores its
+// response, or releases key when it failed with a 5xx status
+func serveAndStore(w http.ResponseWriter, r *http.Request, next http.Handler, cfg IdempotencyConfig, key string) {
+	rec := &recordingWriter{ResponseWriter: w, before: w.Header().Clone()}
+	completed := false
+	defer func() {
+		// the key must not stay in flight when the handler panics
+		if !completed {
+			if err := cfg.Store.Release(context.WithoutCancel(r.Context()), key); err != nil {
+				logrus.Error(fmt.Errorf("idempotency store: %w", err))
+			}
+		}
+	}()
+
+	next.ServeHTTP(rec, r)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	if rec.status >= http.StatusInternalServerError {
+		return
+	}
+
+	res := IdempotentResponse{Status: rec.status, Header: rec.header, Body: rec.body.Bytes()}
+	if err := cfg.Store.Complete(context.WithoutCancel(r.Context()), key, res, cfg.TTL, cfg.Clock()); err != nil {
+		logrus.Error(fmt.Errorf("idempotency store: %w", err))
+		return
+	}
+	completed = true
+}
+
+// replay writes a stored response
+func replay(w http.ResponseWriter, res IdempotentResponse) {
+	for name, values := range res.Header {
+		w.Header()[name] = append([]string(nil), values...)
+	}
+	w.Header().Set(HeaderIdempotentReplayed, "true")
+	w.WriteHeader(res.Status)
+	_, _ = w.Write(res.Body)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(dto.ResponseError{Message: err.Error()})
+}
+
+// recordingWriter copies the response written by the handler, header holds
+// the headers set by the handler and not by the middlewares before it
+type recordingWriter struct {
+	http.ResponseWriter
+	before http.Header
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if rw.status != 0 {
+		return
+	}
+	rw.status = status
+	rw.header = http.Header{}
+	for name, values := range rw.Header() {
+		if before, ok := rw.before[name]; !ok || !slices.Equal(before, values) {
+			rw.header[name] = append([]string(nil), values...)
+		}
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+type idempotencyEntry struct {
+	record  IdempotencyRecord
+	expires time.Time
+}
+
+// MemoryIdempotencyStore keeps the records in process memory. It is only
+// suitable for single instance deployments.
+type MemoryIdempotencyStore struct {
+	mu            sync.Mutex
+	entries       map[string]*idempotencyEntry
+	lastSweep     time.Time
+	sweepInterval time.Duration
+}
+
+// NewMemoryIdempotencyStore will create an in-memory IdempotencyStore
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries:       map[string]*idempotencyEntry{},
+		sweepInterval: defaultSweepInterval,
+	}
+}
+
+// Reserve creates the record of key unless it has a live one
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, ttl time.Duration, now time.Time) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		return e.record, false, nil
+	}
+	rec := IdempotencyRecord{Fingerprint: fingerprint}
+	s.entries[key] = &idempotencyEntry{record: rec, expires: now.Add(ttl)}
+	return rec, true, nil
+}
+
+// Complete stores the response of key
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, res IdempotentResponse, ttl time.Duration, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return fmt.Errorf("idempotency key %q is not reserved", key)
+	}
+	e.record.Response = &res
+	e.expires = now.Add(ttl)
+	return nil
+}
+
+// Release removes the record of key
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// sweep drops the expired records
+func (s *MemoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
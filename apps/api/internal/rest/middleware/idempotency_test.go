@@ -0,0 +1,186 @@
+package middleware_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/rest/middleware"
+)
+
+// countingHandler creates a resource per call, release blocks the calls
+// until it is closed when it is set
+type countingHandler struct {
+	calls   atomic.Int64
+	status  int
+	release chan struct{}
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := h.calls.Add(1)
+	if h.release != nil {
+		<-h.release
+	}
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Location", fmt.Sprintf("/news/%d", n))
+	w.WriteHeader(h.status)
+	_, _ = fmt.Fprintf(w, `{"id": %d, "body": %q}`, n, body)
+}
+
+func newIdempotentHandler(h *countingHandler, now *time.Time, wait time.Duration) http.Handler {
+	handler := middleware.Idempotency(middleware.IdempotencyConfig{
+		TTL:   time.Hour,
+		Wait:  wait,
+		Clock: func() time.Time { return *now },
+	})(h)
+	// the request ID is set before the middleware and differs per request
+	var requests atomic.Int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(middleware.HeaderRequestID, fmt.Sprint(requests.Add(1)))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func post(handler http.Handler, key, ip, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/news", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	}
+	req.RemoteAddr = ip + ":1234"
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestIdempotency(t *testing.T) {
+	now := time.Now()
+	h := &countingHandler{status: http.StatusCreated}
+	handler := newIdempotentHandler(h, &now, time.Second)
+
+	first := post(handler, "abc", "10.0.0.1", `{"title": "First"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(middleware.HeaderIdempotentReplayed))
+
+	retry := post(handler, "abc", "10.0.0.1", `{"title": "First"}`)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, "/news/1", retry.Header().Get("Location"))
+	assert.Equal(t, "true", retry.Header().Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, "2", retry.Header().Get(middleware.HeaderRequestID))
+	assert.Equal(t, int64(1), h.calls.Load())
+
+	rr := post(handler, "abc", "10.0.0.1", `{"title": "Other"}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "different request")
+
+	// keys are scoped to the client IP address, and requests without a key always run
+	rr = post(handler, "abc", "10.0.0.2", `{"title": "First"}`)
+	assert.Equal(t, "/news/2", rr.Header().Get("Location"))
+	post(handler, "", "10.0.0.1", `{"title": "First"}`)
+	post(handler, "", "10.0.0.1", `{"title": "First"}`)
+	assert.Equal(t, int64(4), h.calls.Load())
+
+	// responses expire after the TTL
+	now = now.Add(2 * time.Hour)
+	rr = post(handler, "abc", "10.0.0.1", `{"title": "First"}`)
+	assert.Equal(t, "/news/5", rr.Header().Get("Location"))
+
+	rr = post(handler, strings.Repeat("k", 256), "10.0.0.1", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestIdempotencyServerErrors(t *testing.T) {
+	now := time.Now()
+	h := &countingHandler{status: http.StatusServiceUnavailable}
+	handler := newIdempotentHandler(h, &now, time.Second)
+
+	rr := post(handler, "abc", "10.0.0.1", `{}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+
+	// the failed request is not stored, its retry runs again
+	h.status = http.StatusCreated
+	rr = post(handler, "abc", "10.0.0.1", `{}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, int64(2), h.calls.Load())
+}
+
+func TestIdempotencyInFlight(t *testing.T) {
+	now := time.Now()
+	h := &countingHandler{status: http.StatusCreated, release: make(chan struct{})}
+	handler := newIdempotentHandler(h, &now, 5*time.Second)
+
+	var wg sync.WaitGroup
+	results := make([]*httptest.ResponseRecorder, 3)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = post(handler, "abc", "10.0.0.1", `{}`)
+		}()
+	}
+	require.Eventually(t, func() bool { return h.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(h.release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), h.calls.Load())
+	for _, rr := range results {
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, "/news/1", rr.Header().Get("Location"))
+	}
+
+	// duplicates give up after waiting
+	h = &countingHandler{status: http.StatusCreated, release: make(chan struct{})}
+	defer close(h.release)
+	handler = newIdempotentHandler(h, &now, 20*time.Millisecond)
+	go post(handler, "abc", "10.0.0.1", `{}`)
+	require.Eventually(t, func() bool { return h.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	rr := post(handler, "abc", "10.0.0.1", `{}`)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+}
+
+func TestIdempotencyResponseController(t *testing.T) {
+	handler := middleware.Idempotency(middleware.IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/news", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	req.Header.Set(middleware.HeaderIdempotencyKey, "abc")
+	res, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+}
+
+func TestIdempotencySkip(t *testing.T) {
+	h := &countingHandler{status: http.StatusOK}
+	handler := middleware.Idempotency(middleware.IdempotencyConfig{
+		MaxBodySize: 2,
+		Skip: func(r *http.Request) bool {
+			return r.URL.Path == "/news"
+		},
+	})(h)
+
+	// skipped requests are not limited nor replayed
+	assert.Equal(t, http.StatusOK, post(handler, "abc", "10.0.0.1", `{"title": "First"}`).Code)
+	assert.Equal(t, http.StatusOK, post(handler, "abc", "10.0.0.1", `{"title": "First"}`).Code)
+	assert.Equal(t, int64(2), h.calls.Load())
+}
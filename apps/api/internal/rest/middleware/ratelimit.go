@@ -0,0 +1,324 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+const (
+	// HeaderAPIKey is the header used by clients to send their API key
+	HeaderAPIKey = "X-API-Key"
+	// HeaderUserID is the header used by upstream gateways to identify the user
+	HeaderUserID = "X-User-ID"
+
+	defaultSweepInterval = time.Minute
+	defaultMaxKeysPerIP  = 8
+)
+
+// RateLimitPolicy describes a token bucket holding at most Limit tokens,
+// refilled completely over Window.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRateLimitPolicy parses a policy written as "<limit>/<window>", e.g. "120/1m"
+func ParseRateLimitPolicy(name, value string) (RateLimitPolicy, error) {
+	limitStr, windowStr, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit policy %q: expected <limit>/<window>", value)
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit %q: limit must be a positive integer", value)
+	}
+	window, err := time.ParseDuration(windowStr)
+	if err != nil || window <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("invalid rate limit %q: window must be a positive duration", value)
+	}
+
+	return RateLimitPolicy{Name: name, Limit: limit, Window: window}, nil
+}
+
+// String renders the policy in the RateLimit-Policy header format
+func (p RateLimitPolicy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Limit, int64(math.Ceil(p.Window.Seconds())))
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration // time until the bucket is full again
+	RetryAfter time.Duration // time until the next token is available when not allowed
+}
+
+// RateLimitStore keeps the buckets state, in-memory or in a shared backend
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitConfig configures the RateLimit middleware
+type RateLimitConfig struct {
+	// Read is applied to GET, HEAD and OPTIONS requests, Write to everything else
+	Read  RateLimitPolicy
+	Write RateLimitPolicy
+	// KeyFunc identifies the client, defaults to ClientKey
+	KeyFunc func(r *http.Request) string
+	// MaxKeysPerIP bounds the clients identified by KeyFunc other than by
+	// their IP address from a single IP address, defaults to 8. The requests
+	// of the clients over it share the bucket of the IP address.
+	MaxKeysPerIP int
+	// Store defaults to a new MemoryRateLimitStore
+	Store RateLimitStore
+	// Skip excludes requests from rate limiting, e.g. health checks
+	Skip func(r *http.Request) bool
+	// ErrorFunc writes the 429 Too Many Requests responses, defaults to JSON
+	ErrorFunc func(w http.ResponseWriter, r *http.Request, err error)
+	// Clock defaults to time.Now
+	Clock func() time.Time
+}
+
+// RateLimit limits the request rate per client using token buckets.
+func RateLimit(cfg RateLimitConfig) func(http.Handler) http.Handler {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = ClientKey
+	}
+	if cfg.MaxKeysPerIP <= 0 {
+		cfg.MaxKeysPerIP = defaultMaxKeysPerIP
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimitStore()
+	}
+	if cfg.ErrorFunc == nil {
+		cfg.ErrorFunc = func(w http.ResponseWriter, _ *http.Request, err error) {
+			writeJSONError(w, http.StatusTooManyRequests, err)
+		}
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = time.Now
+	}
+	keys := newIPKeys(max(cfg.Read.Window, cfg.Write.Window))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Skip != nil && cfg.Skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			policy := cfg.Write
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				policy = cfg.Read
+			}
+
+			now := cfg.Clock()
+			ip := "ip:" + KeyByIP(r)
+			client := cfg.KeyFunc(r)
+			if client != ip && !keys.add(ip, client, cfg.MaxKeysPerIP, now) {
+				client = ip
+			}
+
+			key := policy.Name + ":" + client
+			res, err := cfg.Store.Take(r.Context(), key, policy, now)
+			if err != nil {
+				// Fail open, an unavailable store must not take the API down
+				logrus.Error(fmt.Errorf("rate limit store: %w", err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Policy", policy.String())
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.FormatInt(ceilSeconds(res.Reset), 10))
+
+			if !res.Allowed {
+				w.Header().Set("Retry-After", strconv.FormatInt(ceilSeconds(res.RetryAfter), 10))
+				cfg.ErrorFunc(w, r, domain.ErrTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// KeyByAPIKey identifies clients by the X-API-Key header
+func KeyByAPIKey(r *http.Request) string {
+	return r.Header.Get(HeaderAPIKey)
+}
+
+// KeyByUser identifies clients by the X-User-ID header
+func KeyByUser(r *http.Request) string {
+	return r.Header.Get(HeaderUserID)
+}
+
+// KeyByIP identifies clients by the remote address of the connection
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ClientKey identifies clients by IP address, the X-API-Key and X-User-ID
+// headers are not trusted as anyone can send them
+func ClientKey(r *http.Request) string {
+	return "ip:" + KeyByIP(r)
+}
+
+// ClientKeyWithAPIKeys identifies the clients sending one of apiKeys by it,
+// and the others by IP address
+func ClientKeyWithAPIKeys(apiKeys ...string) func(r *http.Request) string {
+	valid := make(map[string]bool, len(apiKeys))
+	for _, key := range apiKeys {
+		if key != "" {
+			valid[key] = true
+		}
+	}
+	return func(r *http.Request) string {
+		if key := KeyByAPIKey(r); valid[key] {
+			return "key:" + key
+		}
+		return ClientKey(r)
+	}
+}
+
+// ipKeys tracks the client keys seen from each IP address over window
+type ipKeys struct {
+	mu        sync.Mutex
+	seen      map[string]map[string]time.Time // ip -> client key -> last request
+	window    time.Duration
+	lastSweep time.Time
+}
+
+func newIPKeys(window time.Duration) *ipKeys {
+	return &ipKeys{seen: map[string]map[string]time.Time{}, window: window}
+}
+
+// add records a request of client from ip, false when ip already has limit
+// other clients
+func (k *ipKeys) add(ip, client string, limit int, now time.Time) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if now.Sub(k.lastSweep) >= defaultSweepInterval {
+		k.lastSweep = now
+		for ip, clients := range k.seen {
+			for client, last := range clients {
+				if now.Sub(last) >= k.window {
+					delete(clients, client)
+				}
+			}
+			if len(clients) == 0 {
+				delete(k.seen, ip)
+			}
+		}
+	}
+
+	clients, ok := k.seen[ip]
+	if !ok {
+		clients = map[string]time.Time{}
+		k.seen[ip] = clients
+	}
+	if _, ok = clients[client]; !ok && len(clients) >= limit {
+		return false
+	}
+	clients[client] = now
+	return true
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	window time.Duration
+}
+
+// MemoryRateLimitStore keeps the buckets in process memory. It is only
+// suitable for single instance deployments.
+type MemoryRateLimitStore struct {
+	mu            sync.Mutex
+	buckets       map[string]*bucket
+	lastSweep     time.Time
+	sweepInterval time.Duration
+}
+
+// NewMemoryRateLimitStore will create an in-memory RateLimitStore
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:       map[string]*bucket{},
+		sweepInterval: defaultSweepInterval,
+	}
+}
+
+// Take removes a token from the bucket identified by key
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	capacity := float64(policy.Limit)
+	rate := capacity / policy.Window.Seconds() // tokens per second
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	b.window = policy.Window
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+		b.last = now
+	}
+
+	res := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = time.Duration((capacity - b.tokens) / rate * float64(time.Second))
+
+	return res, nil
+}
+
+// sweep drops the buckets which have been idle long enough to be full again
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.last) >= b.window {
+			delete(s.buckets, key)
+		}
+	}
+}
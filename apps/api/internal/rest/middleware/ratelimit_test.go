@@ -0,0 +1,159 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/dto"
+	"github.com/bxcodec/go-clean-arch/internal/rest/middleware"
+)
+
+func newRateLimitedHandler(now *time.Time) http.Handler {
+	return middleware.RateLimit(middleware.RateLimitConfig{
+		Read:    middleware.RateLimitPolicy{Name: "read", Limit: 2, Window: time.Minute},
+		Write:   middleware.RateLimitPolicy{Name: "write", Limit: 1, Window: time.Minute},
+		KeyFunc: middleware.ClientKeyWithAPIKeys("client-a", "client-b"),
+		Clock:   func() time.Time { return *now },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func doRequest(handler http.Handler, method, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/news", nil)
+	if apiKey != "" {
+		req.Header.Set(middleware.HeaderAPIKey, apiKey)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Now()
+	handler := newRateLimitedHandler(&now)
+
+	rr := doRequest(handler, http.MethodGet, "client-a")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "2", rr.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2;w=60", rr.Header().Get("RateLimit-Policy"))
+
+	rr = doRequest(handler, http.MethodGet, "client-a")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "0", rr.Header().Get("RateLimit-Remaining"))
+
+	rr = doRequest(handler, http.MethodGet, "client-a")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var body dto.ResponseError
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&body))
+	assert.NotEmpty(t, body.Message)
+
+	// Other clients have their own bucket
+	rr = doRequest(handler, http.MethodGet, "client-b")
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	// Unknown API keys share the bucket of the IP address
+	rr = doRequest(handler, http.MethodGet, "random-1")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = doRequest(handler, http.MethodGet, "random-2")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr = doRequest(handler, http.MethodGet, "random-3")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	// A token is refilled after Window / Limit
+	now = now.Add(30 * time.Second)
+	rr = doRequest(handler, http.MethodGet, "client-a")
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRateLimitSeparatesReadAndWrite(t *testing.T) {
+	now := time.Now()
+	handler := newRateLimitedHandler(&now)
+
+	rr := doRequest(handler, http.MethodPost, "client-a")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("RateLimit-Limit"))
+
+	rr = doRequest(handler, http.MethodPost, "client-a")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	rr = doRequest(handler, http.MethodGet, "client-a")
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestRateLimitCapsKeysPerIP(t *testing.T) {
+	handler := middleware.RateLimit(middleware.RateLimitConfig{
+		Read:         middleware.RateLimitPolicy{Name: "read", Limit: 1, Window: time.Minute},
+		Write:        middleware.RateLimitPolicy{Name: "write", Limit: 1, Window: time.Minute},
+		KeyFunc:      middleware.KeyByAPIKey,
+		MaxKeysPerIP: 2,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "a").Code)
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "b").Code)
+	// The keys over the cap share the bucket of the IP address
+	assert.Equal(t, http.StatusOK, doRequest(handler, http.MethodGet, "c").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, http.MethodGet, "d").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, http.MethodGet, "a").Code)
+}
+
+func TestRateLimitErrorFunc(t *testing.T) {
+	handler := middleware.RateLimit(middleware.RateLimitConfig{
+		Read:  middleware.RateLimitPolicy{Name: "read", Limit: 1, Window: time.Minute},
+		Write: middleware.RateLimitPolicy{Name: "write", Limit: 1, Window: time.Minute},
+		ErrorFunc: func(w http.ResponseWriter, r *http.Request, err error) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(err.Error()))
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	doRequest(handler, http.MethodGet, "")
+	rr := doRequest(handler, http.MethodGet, "")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "text/plain", rr.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+}
+
+func TestClientKey(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/news", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	assert.Equal(t, "ip:10.0.0.1", middleware.ClientKey(req))
+
+	// Headers are not trusted
+	req.Header.Set(middleware.HeaderUserID, "42")
+	req.Header.Set(middleware.HeaderAPIKey, "secret")
+	assert.Equal(t, "ip:10.0.0.1", middleware.ClientKey(req))
+
+	keyFunc := middleware.ClientKeyWithAPIKeys("secret")
+	assert.Equal(t, "key:secret", keyFunc(req))
+
+	req.Header.Set(middleware.HeaderAPIKey, "guess")
+	assert.Equal(t, "ip:10.0.0.1", keyFunc(req))
+}
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy, err := middleware.ParseRateLimitPolicy("read", "120/1m")
+	require.NoError(t, err)
+	assert.Equal(t, middleware.RateLimitPolicy{Name: "read", Limit: 120, Window: time.Minute}, policy)
+
+	for _, value := range []string{"", "120", "0/1m", "abc/1m", "10/abc", "10/-1s"} {
+		_, err = middleware.ParseRateLimitPolicy("read", value)
+		assert.Error(t, err, value)
+	}
+}
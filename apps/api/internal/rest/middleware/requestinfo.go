@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// HeaderRequestID is the header carrying the request ID, set by clients or
+// generated when missing
+const HeaderRequestID = "X-Request-ID"
+
+// RequestInfo identifies the actor, request ID and client IP of each request
+// and stores them in the request context.
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = domain.NewRequestID()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+
+		info := domain.RequestInfo{
+			Actor:     domain.ActorName(KeyByUser(r), KeyByAPIKey(r)),
+			RequestID: requestID,
+			ClientIP:  KeyByIP(r),
+		}
+		next.ServeHTTP(w, r.WithContext(domain.ContextWithRequestInfo(r.Context(), info)))
+	})
+}
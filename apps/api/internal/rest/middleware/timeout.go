@@ -7,9 +7,18 @@ import (
 )
 
 // SetRequestContextWithTimeout applies a timeout to the request context.
-func SetRequestContextWithTimeout(d time.Duration) func(http.Handler) http.Handler {
+// Requests matching one of skip, such as long running exports, keep the
+// context of the connection.
+func SetRequestContextWithTimeout(d time.Duration, skip ...func(r *http.Request) bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, s := range skip {
+				if s(r) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
 			// Create a new context with timeout
 			ctx, cancel := context.WithTimeout(r.Context(), d)
 			defer cancel()
@@ -2,173 +2,249 @@ package rest
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/bxcodec/go-clean-arch/internal/dto"
 	"github.com/bxcodec/go-clean-arch/internal/dto/news"
 	"gopkg.in/go-playground/validator.v9"
+	"mime"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/bxcodec/go-clean-arch/domain"
 )
 
 // NewsService represents the news's use cases
 type NewsService interface {
 	Fetch(ctx context.Context, filter domain.NewsFilter) ([]domain.News, int64, error)
+	Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) error
 	GetByID(ctx context.Context, id int64) (domain.News, error)
 	Update(ctx context.Context, ar *news.UpdateNewsReq) error
 	GetByTitle(ctx context.Context, title string) (domain.News, error)
 	Store(context.Context, *news.CreateNewsReq) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, version int64) error
+	Import(ctx context.Context, reader news.ImportReader, opts domain.ImportOptions) (domain.ImportReport, error)
+	Batch(ctx context.Context, operations []news.BatchOperation, mode news.BatchMode) ([]news.BatchResult, error)
+	Facets(ctx context.Context, filter domain.NewsFilter, facets []string) ([]domain.Facet, error)
 }
 
 // NewsHandler represents the HTTP handler for news
 type NewsHandler struct {
 	Service NewsService
+	Config  ConcurrencyConfig
 }
 
 const (
 	defaultLimit = 10
 	defaultPage  = 1
+
+	// maxImportSize bounds the body of an import request
+	maxImportSize = 256 << 20
 )
 
 // NewNewsHandler initializes the news resources endpoints
-func NewNewsHandler(mux *http.ServeMux, svc NewsService) {
+func NewNewsHandler(mux *http.ServeMux, svc NewsService, cfg ConcurrencyConfig) {
 	handler := &NewsHandler{
 		Service: svc,
+		Config:  cfg,
 	}
-	mux.HandleFunc("/news", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			handler.Fetch(w, r)
-		case http.MethodPost:
-			handler.Store(w, r)
-		default:
-			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-		}
+	handleRoutes(mux, map[string]http.HandlerFunc{
+		"news.list":    negotiate(handler.Fetch),
+		"news.create":  negotiate(handler.Store),
+		"news.import":  negotiateAccept(handler.Import),
+		"news.export":  handler.Export,
+		"news.batch":   negotiate(handler.Batch),
+		"news.get":     negotiate(withID(handler.GetByID)),
+		"news.replace": negotiate(withID(handler.Update)),
+		"news.patch":   negotiate(withID(handler.Patch)),
+		"news.delete":  negotiate(withID(handler.Delete)),
 	})
-	mux.HandleFunc("/news/", handler.NewsHandler) // Combines GetByID, Update, and Delete based on HTTP method
 }
 
-// Fetch handles GET requests to fetch news with optional filters
+// Fetch handles GET requests to fetch news with optional filters, ids=1,2
+// fetches the news with the given IDs in one page and facets=status,topic
+// adds the counts per value of the facets to the meta
 func (a *NewsHandler) Fetch(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	filter := parseNewsFilter(r.URL.Query())
+	if err := parseNewsFields(r.URL.Query(), &filter); err != nil {
+		writeError(w, r, err)
 		return
 	}
-
-	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
-	if err != nil || limit <= 0 {
-		limit = defaultLimit
-	}
-	page, err := strconv.Atoi(r.URL.Query().Get("page"))
-	if err != nil || page <= 0 {
-		page = defaultPage
-	}
-
-	// Parse optional filters from query parameters
-	filter := domain.NewsFilter{
-		Limit: int64(limit),
-		Page:  int64(page),
-	}
-
-	// Set optional filters
-	if idStr := r.URL.Query().Get("id"); idStr != "" {
-		if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
-			filter.ID = id
-		}
-	}
-	if title := r.URL.Query().Get("title"); title != "" {
-		filter.Title = title
-	}
-	if status := r.URL.Query().Get("status"); status != "" {
-		filter.Status = status
+	facets, err := parseNames(r.URL.Query().Get("facets"), "facet", domain.NewsFacets)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
-	if authorIDStr := r.URL.Query().Get("author_id"); authorIDStr != "" {
-		if authorID, err := strconv.ParseInt(authorIDStr, 10, 64); err == nil {
-			filter.AuthorID = authorID
-		}
+	ids, err := parseIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
-	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
-		if startDate, err := time.Parse(time.RFC3339, startDateStr); err == nil {
-			filter.StartDate = startDate
-		}
+	if len(ids) > maxBatchSize {
+		writeError(w, r, fmt.Errorf("%w: at most %d ids", domain.ErrBadParamInput, maxBatchSize))
+		return
 	}
-	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
-		if endDate, err := time.Parse(time.RFC3339, endDateStr); err == nil {
-			filter.EndDate = endDate
+	if len(ids) > 0 {
+		filter.IDs = ids
+		if filter.Limit <= 0 {
+			filter.Limit = int64(len(ids))
 		}
 	}
-	if sortBy := r.URL.Query().Get("sort_by"); sortBy != "" {
-		filter.SortBy = sortBy
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
 	}
-	if sortOrder := r.URL.Query().Get("sort_order"); sortOrder != "" {
-		filter.SortOrder = sortOrder
+	if filter.Page <= 0 {
+		filter.Page = defaultPage
 	}
 
 	// Fetch news using the service
 	ctx := r.Context()
 	listAr, totalData, err := a.Service.Fetch(ctx, filter)
 	if err != nil {
-		http.Error(w, err.Error(), dto.GetStatusCode(err))
+		writeError(w, r, err)
 		return
 	}
 
-	// Calculate total pages
-	totalPages := ((totalData) + (filter.Limit) - 1) / (filter.Limit)
+	// Count the facets of the same filter, without pagination
+	var facetCounts []domain.Facet
+	if len(facets) > 0 {
+		if facetCounts, err = a.Service.Facets(ctx, filter, facets); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
 
 	// Construct response
+	listAr = linkNews(r, listAr)
+	var data interface{} = listAr
+	if filter.Fields != nil || filter.Include != nil {
+		data = selectFields(listAr, filter)
+	}
+	meta := paginate(w, r, filter.Page, filter.Limit, totalData)
+	meta.Facets = facetCounts
 	response := dto.Response{
-		Data: listAr,
-		Meta: dto.PaginationMeta{
-			CurrentPage: filter.Page,
-			TotalPages:  totalPages,
-			TotalData:   totalData,
-		},
-	}
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(response)
-	if err != nil {
-		return
+		Data: data,
+		Meta: meta,
 	}
+	writeConditional(w, r, response, 0, time.Time{})
 }
 
-// NewsHandler routes based on HTTP method for ID-based operations
-func (a *NewsHandler) NewsHandler(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/news/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, "Invalid ID", http.StatusNotFound)
-		return
+// parseNewsFilter reads the optional news filters from query parameters,
+// invalid values are ignored
+func parseNewsFilter(query url.Values) domain.NewsFilter {
+	filter := domain.NewsFilter{
+		Title:     query.Get("title"),
+		Status:    query.Get("status"),
+		SortBy:    query.Get("sort_by"),
+		SortOrder: query.Get("sort_order"),
+	}
+	if limit, err := strconv.ParseInt(query.Get("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
 	}
+	if page, err := strconv.ParseInt(query.Get("page"), 10, 64); err == nil && page > 0 {
+		filter.Page = page
+	}
+	if id, err := strconv.ParseInt(query.Get("id"), 10, 64); err == nil {
+		filter.ID = id
+	}
+	if authorID, err := strconv.ParseInt(query.Get("author_id"), 10, 64); err == nil {
+		filter.AuthorID = authorID
+	}
+	if topicID, err := strconv.ParseInt(query.Get("topic_id"), 10, 64); err == nil {
+		filter.TopicID = topicID
+	}
+	if startDate, err := time.Parse(time.RFC3339, query.Get("start_date")); err == nil {
+		filter.StartDate = startDate
+	}
+	if endDate, err := time.Parse(time.RFC3339, query.Get("end_date")); err == nil {
+		filter.EndDate = endDate
+	}
+	return filter
+}
 
-	switch r.Method {
-	case http.MethodGet:
-		a.GetByID(w, r, id)
-	case http.MethodPut:
-		a.Update(w, r, id)
-	case http.MethodDelete:
-		a.Delete(w, r, id)
-	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// parseNewsFields reads the sparse fieldset of fields=id,title and the
+// relations to expand of include=author,topics, unknown names are rejected
+func parseNewsFields(query url.Values, filter *domain.NewsFilter) (err error) {
+	if query.Has("fields") {
+		if filter.Fields, err = parseNames(query.Get("fields"), "field", domain.NewsFields); err != nil {
+			return err
+		}
+	}
+	if query.Has("include") {
+		if filter.Include, err = parseNames(query.Get("include"), "relation", domain.NewsRelations); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// GetByID retrieves news by the given ID
+// parseNames reads a comma separated list of the valid names without
+// duplicates, it is never nil so that an empty list selects nothing
+func parseNames(s, kind string, valid []string) ([]string, error) {
+	names := []string{}
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !slices.Contains(valid, name) {
+			return nil, fmt.Errorf("%w: unknown %s %q, use %s", domain.ErrBadParamInput, kind, name, strings.Join(valid, ", "))
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// selectFields restricts every news to the fields and relations of filter
+func selectFields(list []domain.News, filter domain.NewsFilter) []news.News {
+	res := make([]news.News, len(list))
+	for i, n := range list {
+		res[i] = news.SelectFields(n, filter)
+	}
+	return res
+}
+
+// withID passes the id path parameter to fn, invalid IDs are not found
+func withID(fn func(w http.ResponseWriter, r *http.Request, id int64)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+		if err != nil {
+			writeError(w, r, domain.ErrNotFound)
+			return
+		}
+		fn(w, r, id)
+	}
+}
+
+// GetByID retrieves news by the given ID, its ETag starts with its version
+// unless fields or include select a partial representation
 func (a *NewsHandler) GetByID(w http.ResponseWriter, r *http.Request, id int64) {
+	filter := domain.NewsFilter{ID: id, Page: 1, Limit: 1}
+	if err := parseNewsFields(r.URL.Query(), &filter); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	ctx := r.Context()
-	newsItem, _, err := a.Service.Fetch(ctx, domain.NewsFilter{ID: id, Page: 1, Limit: 1})
+	newsItem, _, err := a.Service.Fetch(ctx, filter)
 	if err != nil || len(newsItem) == 0 {
-		http.Error(w, "News not found", http.StatusNotFound)
+		writeError(w, r, domain.ErrNotFound)
 		return
 	}
+	newsItem = linkNews(r, newsItem)
 
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(newsItem[0])
-	if err != nil {
+	if filter.Fields == nil && filter.Include == nil {
+		writeConditional(w, r, newsItem[0], newsItem[0].Version, newsItem[0].UpdatedAt)
 		return
 	}
+	writeConditional(w, r, news.SelectFields(newsItem[0], filter), 0, newsItem[0].UpdatedAt)
 }
 
 func isRequestValid(m *news.CreateNewsReq) (bool, error) {
@@ -183,62 +259,191 @@ func isRequestValid(m *news.CreateNewsReq) (bool, error) {
 // Store will store the news by given request body
 func (a *NewsHandler) Store(w http.ResponseWriter, r *http.Request) {
 	var createNewsReq news.CreateNewsReq
-	err := json.NewDecoder(r.Body).Decode(&createNewsReq)
+	err := decodeRequest(r, &createNewsReq)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		writeError(w, r, err)
 		return
 	}
 
 	if _, err = isRequestValid(&createNewsReq); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err))
 		return
 	}
 
 	ctx := r.Context()
 	err = a.Service.Store(ctx, &createNewsReq)
 	if err != nil {
-		http.Error(w, err.Error(), dto.GetStatusCode(err))
+		writeError(w, r, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(map[string]string{"message": "success create news"})
-	if err != nil {
-		return
+	w.Header().Set("Location", routePath("news.get", createNewsReq.ID))
+	writeResponse(w, r, http.StatusCreated, dto.ResponseMessage{Message: "success create news"})
+}
+
+// isReplaceRequestValid validates the fields of a news replaced by PUT or
+// PATCH
+func isReplaceRequestValid(m *news.ReplaceNewsReq) error {
+	if err := validator.New().Struct(m); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+	if err := m.Status.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
 	}
+	return nil
 }
 
-// Update updates the news based on the given request
+// Update replaces every field of the news by the ones of the request body,
+// at the version given by If-Match
 func (a *NewsHandler) Update(w http.ResponseWriter, r *http.Request, id int64) {
-	var updateNewsReq news.UpdateNewsReq
-	updateNewsReq.ID = &id
+	version, err := a.Config.expectedVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
 
-	if err := json.NewDecoder(r.Body).Decode(&updateNewsReq); err != nil {
-		http.Error(w, "Unprocessable entity", http.StatusUnprocessableEntity)
+	var replaceNewsReq news.ReplaceNewsReq
+	if err := decodeRequest(r, &replaceNewsReq); err != nil {
+		writeError(w, r, err)
 		return
 	}
+	if err := isReplaceRequestValid(&replaceNewsReq); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	updateNewsReq := replaceNewsReq.UpdateRequest(id)
+	if version != 0 {
+		updateNewsReq.Version = &version
+	}
 
 	ctx := r.Context()
-	if err := a.Service.Update(ctx, &updateNewsReq); err != nil {
-		http.Error(w, err.Error(), dto.GetStatusCode(err))
+	if err := a.Service.Update(ctx, updateNewsReq); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	err := json.NewEncoder(w).Encode(map[string]string{"message": "success update news"})
+	writeResponse(w, r, http.StatusOK, dto.ResponseMessage{Message: "success update news"})
+}
+
+// Patch applies the JSON Merge Patch or JSON Patch of the request body to the
+// news, the patched news is validated like the body of PUT. The change only
+// applies to the version the patch was computed on.
+func (a *NewsHandler) Patch(w http.ResponseWriter, r *http.Request, id int64) {
+	version, err := a.Config.expectedVersion(r)
 	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+
+	ctx := r.Context()
+	current, _, err := a.Service.Fetch(ctx, domain.NewsFilter{ID: id, Page: 1, Limit: 1})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if len(current) == 0 {
+		writeError(w, r, domain.ErrNotFound)
+		return
+	}
+	if version != 0 && version != current[0].Version {
+		writeError(w, r, domain.ErrPreconditionFailed)
+		return
+	}
+
+	var replaceNewsReq news.ReplaceNewsReq
+	if err = patchDocument(w, r, news.NewReplaceNewsReq(current[0]), &replaceNewsReq); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err = isReplaceRequestValid(&replaceNewsReq); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	updateNewsReq := replaceNewsReq.UpdateRequest(id)
+	updateNewsReq.Version = &current[0].Version
+	if err = a.Service.Update(ctx, updateNewsReq); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeResponse(w, r, http.StatusOK, dto.ResponseMessage{Message: "success update news"})
 }
 
-// Delete removes the news by the given ID
+// Delete removes the news by the given ID, at the version given by If-Match
 func (a *NewsHandler) Delete(w http.ResponseWriter, r *http.Request, id int64) {
+	version, err := a.Config.expectedVersion(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
 	ctx := r.Context()
-	if err := a.Service.Delete(ctx, id); err != nil {
-		http.Error(w, err.Error(), dto.GetStatusCode(err))
+	if err := a.Service.Delete(ctx, id, version); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Import stores the news of a CSV or NDJSON body and reports the outcome of
+// every row. The format is taken from the format query parameter or the
+// Content-Type, dry_run=true validates the rows without storing them.
+func (a *NewsHandler) Import(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = importFormat(r.Header.Get("Content-Type"))
+	}
+	opts := domain.ImportOptions{}
+	opts.DryRun, _ = strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	if batchSize, err := strconv.Atoi(r.URL.Query().Get("batch_size")); err == nil && batchSize > 0 {
+		opts.BatchSize = batchSize
+	}
+
+	reader, err := news.NewImportReader(http.MaxBytesReader(w, r.Body, maxImportSize), format)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	// Large uploads outlive the server read timeout, and their import the
+	// write timeout the report would be dropped after
+	rc := http.NewResponseController(w)
+	if err = rc.SetReadDeadline(time.Time{}); err != nil {
+		logrus.Debug(err)
+	}
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil {
+		logrus.Debug(err)
+	}
+
+	report, err := a.Service.Import(r.Context(), reader, opts)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeResponse(w, r, http.StatusRequestEntityTooLarge, dto.ResponseError{Message: err.Error()})
+			return
+		}
+		writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusCreated
+	if opts.DryRun || report.Created == 0 {
+		status = http.StatusOK
+	}
+	writeResponse(w, r, status, report)
+}
+
+func importFormat(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return news.ImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return news.ImportFormatNDJSON
+	default:
+		return mediaType
+	}
+}
@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+const (
+	// mediaTypeMergePatch is a JSON Merge Patch (RFC 7396)
+	mediaTypeMergePatch = "application/merge-patch+json"
+	// mediaTypeJSONPatch is a JSON Patch (RFC 6902)
+	mediaTypeJSONPatch = "application/json-patch+json"
+
+	// maxPatchSize bounds the body of a PATCH request
+	maxPatchSize = 1 << 20
+)
+
+// patchDocument applies the patch in the body of r to the JSON encoding of
+// doc and decodes the result into out. Patches that can not be parsed are
+// bad params, and patches that can not be applied, or whose result has fields
+// unknown to out, are unprocessable.
+func patchDocument(w http.ResponseWriter, r *http.Request, doc, out interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mediaTypeMergePatch && mediaType != mediaTypeJSONPatch {
+		w.Header().Set("Accept-Patch", mediaTypeMergePatch+", "+mediaTypeJSONPatch)
+		return fmt.Errorf("%w: %s, use %s or %s", domain.ErrUnsupportedMediaType, mediaType, mediaTypeMergePatch, mediaTypeJSONPatch)
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPatchSize))
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+	}
+	original, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	if mediaType == mediaTypeMergePatch {
+		if !json.Valid(patch) {
+			return fmt.Errorf("%w: invalid merge patch", domain.ErrBadParamInput)
+		}
+		if patched, err = jsonpatch.MergePatch(original, patch); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+	} else {
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+		}
+		if patched, err = operations.Apply(original); err != nil {
+			if errors.Is(err, jsonpatch.ErrUnknownType) {
+				return fmt.Errorf("%w: %v", domain.ErrBadParamInput, err)
+			}
+			return fmt.Errorf("%w: %v", domain.ErrUnprocessableEntity, err)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(out); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrUnprocessableEntity, err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type patchNewsService struct {
+	stubNewsService
+	updated *news.UpdateNewsReq
+}
+
+func (s *patchNewsService) Update(_ context.Context, req *news.UpdateNewsReq) error {
+	s.updated = req
+	return nil
+}
+
+type patchTopicService struct {
+	stubTopicService
+	updated *domain.Topic
+}
+
+func (s *patchTopicService) Update(_ context.Context, t *domain.Topic) error {
+	s.updated = t
+	return nil
+}
+
+func newPatchServer() (*http.ServeMux, *patchNewsService, *patchTopicService) {
+	createdAt := time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)
+	newsSvc := &patchNewsService{stubNewsService: stubNewsService{items: []domain.News{{
+		ID:        1,
+		Title:     "Health Benefits",
+		Content:   "Body",
+		Author:    domain.AuthorNews{ID: 2, Name: "Deni"},
+		Status:    domain.Draft,
+		Version:   5,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Topics:    []domain.TopicNews{{ID: 1, Name: "Health"}, {ID: 4, Name: "Environment"}},
+	}}}}
+	topicSvc := &patchTopicService{}
+
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, newsSvc, rest.ConcurrencyConfig{})
+	rest.NewTopicHandler(mux, topicSvc, rest.ConcurrencyConfig{})
+	return mux, newsSvc, topicSvc
+}
+
+var (
+	mergePatch = http.Header{"Content-Type": {"application/merge-patch+json"}}
+	jsonPatch  = http.Header{"Content-Type": {"application/json-patch+json"}}
+)
+
+func TestMergePatch(t *testing.T) {
+	mux, svc, _ := newPatchServer()
+
+	rr := serve(mux, http.MethodPatch, "/news/1", `{"title": "Patched", "status": "published", "topic_ids": [2]}`, mergePatch)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	require.NotNil(t, svc.updated)
+	assert.Equal(t, "Patched", *svc.updated.Title)
+	assert.Equal(t, "Body", *svc.updated.Content)
+	assert.Equal(t, int64(2), *svc.updated.AuthorID)
+	assert.Equal(t, domain.Published, *svc.updated.Status)
+	assert.Equal(t, []int64{2}, *svc.updated.TopicIDs)
+	// the patch only applies to the version it was computed on
+	assert.Equal(t, int64(5), *svc.updated.Version)
+
+	// the patched news is validated
+	svc.updated = nil
+	rr = serve(mux, http.MethodPatch, "/news/1", `{"content": null}`, mergePatch)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = serve(mux, http.MethodPatch, "/news/1", `{"status": "archived"}`, mergePatch)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = serve(mux, http.MethodPatch, "/news/1", `{"views": 3}`, mergePatch)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	rr = serve(mux, http.MethodPatch, "/news/1", `{"title": `, mergePatch)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Nil(t, svc.updated)
+
+	rr = serve(mux, http.MethodPatch, "/news/1", `{"title": "Stale"}`, http.Header{
+		"Content-Type": {"application/merge-patch+json"},
+		"If-Match":     {`"4"`},
+	})
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+
+	rr = serve(mux, http.MethodPatch, "/news/1", `{"title": "Plain"}`, http.Header{"Content-Type": {"application/json"}})
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", rr.Header().Get("Accept-Patch"))
+	assert.Nil(t, svc.updated)
+}
+
+func TestJSONPatch(t *testing.T) {
+	mux, svc, _ := newPatchServer()
+
+	rr := serve(mux, http.MethodPatch, "/news/1", `[
+		{"op": "test", "path": "/title", "value": "Health Benefits"},
+		{"op": "remove", "path": "/topic_ids/0"},
+		{"op": "add", "path": "/topic_ids/-", "value": 5},
+		{"op": "replace", "path": "/content", "value": "New body"}
+	]`, jsonPatch)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, []int64{4, 5}, *svc.updated.TopicIDs)
+	assert.Equal(t, "New body", *svc.updated.Content)
+	assert.Equal(t, "Health Benefits", *svc.updated.Title)
+
+	svc.updated = nil
+	rr = serve(mux, http.MethodPatch, "/news/1", `[{"op": "test", "path": "/title", "value": "Other"}]`, jsonPatch)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	rr = serve(mux, http.MethodPatch, "/news/1", `[{"op": "remove", "path": "/topic_ids/7"}]`, jsonPatch)
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	rr = serve(mux, http.MethodPatch, "/news/1", `[{"op": "remove", "path": "/title"}]`, jsonPatch)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = serve(mux, http.MethodPatch, "/news/1", `{"op": "remove"}`, jsonPatch)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Nil(t, svc.updated)
+}
+
+func TestPutReplaces(t *testing.T) {
+	mux, svc, topicSvc := newPatchServer()
+
+	rr := serve(mux, http.MethodPut, "/news/1", `{"title": "Only the title"}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Nil(t, svc.updated)
+
+	rr = serve(mux, http.MethodPut, "/news/1", `{"title": "New", "content": "Body", "author_id": 3, "status": "draft", "topic_ids": []}`, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(3), *svc.updated.AuthorID)
+	assert.Empty(t, *svc.updated.TopicIDs)
+	assert.Nil(t, svc.updated.Version)
+
+	rr = serve(mux, http.MethodPut, "/topic/1", `{}`, nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serve(mux, http.MethodPatch, "/topic/1", `{"name": "Wellness"}`, mergePatch)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.Equal(t, domain.Topic{ID: 1, Name: "Wellness"}, *topicSvc.updated)
+
+	rr = serve(mux, http.MethodPatch, "/topic/2", `{"name": "Wellness"}`, mergePatch)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
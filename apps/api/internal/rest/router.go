@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// routes maps the names of the routes to their ServeMux patterns. The names
+// identify the routes in metrics and build their paths with routePath.
+var routes = map[string]string{
+	"news.list":            "GET /news",
+	"news.create":          "POST /news",
+	"news.import":          "POST /news/import",
+	"news.export":          "GET /news/export",
+	"news.batch":           "POST /news/batch",
+	"news.get":             "GET /news/{id}",
+	"news.replace":         "PUT /news/{id}",
+	"news.patch":           "PATCH /news/{id}",
+	"news.delete":          "DELETE /news/{id}",
+	"topic.list":           "GET /topic",
+	"topic.create":         "POST /topic",
+	"topic.export":         "GET /topic/export",
+	"topic.get":            "GET /topic/{id}",
+	"topic.replace":        "PUT /topic/{id}",
+	"topic.patch":          "PATCH /topic/{id}",
+	"topic.delete":         "DELETE /topic/{id}",
+	"topic.stats":          "GET /topic/{id}/stats",
+	"author.list":          "GET /author",
+	"author.get":           "GET /author/{id}",
+	"author.news":          "GET /author/{id}/news",
+	"author.stats":         "GET /author/{id}/stats",
+	"stats.authors":        "GET /stats/authors",
+	"stats.authors.export": "GET /stats/authors/export",
+	"audit.list":           "GET /admin/audit",
+	"audit.export":         "GET /admin/audit/export",
+	"feed.news":            "GET /feeds/{file}",
+	"feed.topic":           "GET /feeds/topic/{file}",
+	"feed.author":          "GET /feeds/author/{file}",
+	"sitemap.index":        "GET /sitemap.xml",
+	"sitemap.page":         "GET /sitemaps/{file}",
+}
+
+// routeMethods are the methods answered 405 Method Not Allowed on the paths
+// which do not handle them
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// handleRoutes registers the named routes on mux. Every path of the routes
+// also answers:
+//   - HEAD like GET, which ServeMux does for GET patterns
+//   - OPTIONS with 204 No Content and the Allow header
+//   - the other methods with 405 Method Not Allowed and the Allow header
+//   - its path with a trailing slash with a 308 redirect to the path without
+func handleRoutes(mux *http.ServeMux, handlers map[string]http.HandlerFunc) {
+	methods := map[string][]string{}
+	for name, handler := range handlers {
+		pattern, ok := routes[name]
+		if !ok {
+			panic(fmt.Sprintf("rest: unknown route %q", name))
+		}
+		method, path, _ := strings.Cut(pattern, " ")
+		methods[path] = append(methods[path], method)
+		mux.Handle(pattern, withRouteName(name, handler))
+	}
+
+	for path, allowed := range methods {
+		if slices.Contains(allowed, http.MethodGet) {
+			allowed = append(allowed, http.MethodHead)
+		}
+		allowed = append(allowed, http.MethodOptions)
+		slices.Sort(allowed)
+		allow := strings.Join(allowed, ", ")
+
+		for _, method := range routeMethods {
+			if !slices.Contains(allowed, method) {
+				mux.HandleFunc(method+" "+path, func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Allow", allow)
+					writeError(w, r, domain.ErrMethodNotAllowed)
+				})
+			}
+		}
+		mux.HandleFunc(http.MethodOptions+" "+path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", allow)
+			w.WriteHeader(http.StatusNoContent)
+		})
+		mux.HandleFunc(path+"/{$}", func(w http.ResponseWriter, r *http.Request) {
+			target := strings.TrimSuffix(r.URL.Path, "/")
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+			http.Redirect(w, r, target, http.StatusPermanentRedirect)
+		})
+	}
+}
+
+// routePath builds the path of the named route, values replacing its
+// wildcards in order
+func routePath(name string, values ...interface{}) string {
+	_, path, _ := strings.Cut(routes[name], " ")
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && len(values) > 0 {
+			segments[i] = fmt.Sprint(values[0])
+			values = values[1:]
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type routeKey struct{}
+
+// routeName holds the name of the route serving a request
+type routeName struct {
+	name string
+}
+
+// withRouteName sets the name of the route serving the request
+func withRouteName(name string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if holder, ok := r.Context().Value(routeKey{}).(*routeName); ok {
+			holder.name = name
+			next(w, r)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, &routeName{name: name})))
+	}
+}
+
+// RouteNames makes the name of the route serving a request readable with
+// RouteName by the middlewares wrapping next once it has been routed, e.g. to
+// label metrics
+func RouteNames(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeKey{}, &routeName{})))
+	})
+}
+
+// RouteName returns the name of the route serving the request of ctx, empty
+// when it was not routed
+func RouteName(ctx context.Context) string {
+	if holder, ok := ctx.Value(routeKey{}).(*routeName); ok {
+		return holder.name
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package rest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+func TestMethodNotAllowed(t *testing.T) {
+	mux, _ := newCodecServer()
+
+	rr := serve(mux, http.MethodDelete, "/news", "", nil)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS, POST", rr.Header().Get("Allow"))
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"message": "method not allowed"}`, rr.Body.String())
+
+	rr = serve(mux, http.MethodPost, "/news/1", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+	assert.Equal(t, "DELETE, GET, HEAD, OPTIONS, PATCH, PUT", rr.Header().Get("Allow"))
+
+	rr = serve(mux, http.MethodOptions, "/news/export", "", nil)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, "GET, HEAD, OPTIONS", rr.Header().Get("Allow"))
+
+	rr = serve(mux, http.MethodHead, "/news", "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestTrailingSlash(t *testing.T) {
+	mux, _ := newCodecServer()
+
+	rr := serve(mux, http.MethodGet, "/news/?page=2", "", nil)
+	assert.Equal(t, http.StatusPermanentRedirect, rr.Code)
+	assert.Equal(t, "/news?page=2", rr.Header().Get("Location"))
+
+	rr = serve(mux, http.MethodPost, "/news/", "", nil)
+	assert.Equal(t, http.StatusPermanentRedirect, rr.Code)
+	assert.Equal(t, "/news", rr.Header().Get("Location"))
+}
+
+func TestUnknownRoute(t *testing.T) {
+	mux := http.NewServeMux()
+	rest.MountVersions(mux, rest.VersioningConfig{
+		Versions: []rest.APIVersion{{Name: "v1", Register: func(mux *http.ServeMux) {
+			rest.NewNewsHandler(mux, &stubNewsService{}, rest.ConcurrencyConfig{})
+		}}},
+		Alias: "v1",
+	})
+
+	for _, target := range []string{"/news/1/extra", "/v1/news/1/extra", "/news/abc"} {
+		rr := serve(mux, http.MethodGet, target, "", nil)
+		assert.Equal(t, http.StatusNotFound, rr.Code, target)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"), target)
+	}
+}
+
+func TestRouteName(t *testing.T) {
+	mux, _ := newCodecServer()
+
+	var name string
+	handler := rest.RouteNames(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		name = rest.RouteName(r.Context())
+	}))
+
+	serve(handler, http.MethodGet, "/news", "", nil)
+	assert.Equal(t, "news.list", name)
+	serve(handler, http.MethodPost, "/news", `{"title": "Health Benefits"}`, nil)
+	assert.Equal(t, "news.create", name)
+	serve(handler, http.MethodGet, "/unknown", "", nil)
+	assert.Empty(t, name)
+}
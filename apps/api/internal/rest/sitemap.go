@@ -0,0 +1,254 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/sitemap"
+)
+
+const defaultNewsSitemapWindow = 48 * time.Hour
+
+// SitemapNewsService represents the news use cases needed by sitemaps
+//
+//go:generate mockery --name SitemapNewsService
+type SitemapNewsService interface {
+	Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) error
+	Archive(ctx context.Context, filter domain.NewsFilter) ([]domain.NewsArchive, error)
+}
+
+// SitemapTopicService represents the topic use cases needed by sitemaps
+//
+//go:generate mockery --name SitemapTopicService
+type SitemapTopicService interface {
+	Fetch(ctx context.Context, filter domain.TopicFilter) ([]domain.Topic, int64, error)
+	Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) error
+}
+
+// SitemapConfig describes the site the sitemaps are published for
+type SitemapConfig struct {
+	// SiteURL is the public base URL of the site, e.g. https://news.example.com
+	SiteURL string
+	// PublicationName and PublicationLanguage identify the site in Google News
+	PublicationName     string
+	PublicationLanguage string
+	// NewsWindow is how far back the Google News sitemap goes, 48 hours by default
+	NewsWindow time.Duration
+	// MaxURLs is the number of URLs per sitemap file, sitemap.MaxURLs by default
+	MaxURLs int
+	// Clock defaults to time.Now
+	Clock func() time.Time
+}
+
+// SitemapHandler represents the HTTP handler for XML sitemaps
+type SitemapHandler struct {
+	NewsService  SitemapNewsService
+	TopicService SitemapTopicService
+	Config       SitemapConfig
+}
+
+var (
+	newsSitemapPattern  = regexp.MustCompile(`^news-(\d{4})-(\d{2})(?:-(\d+))?\.xml$`)
+	topicSitemapPattern = regexp.MustCompile(`^topics(?:-(\d+))?\.xml$`)
+)
+
+// NewSitemapHandler initializes the sitemap endpoints
+func NewSitemapHandler(mux *http.ServeMux, newsSvc SitemapNewsService, topicSvc SitemapTopicService, cfg SitemapConfig) {
+	cfg.SiteURL = strings.TrimSuffix(cfg.SiteURL, "/")
+	if cfg.NewsWindow <= 0 {
+		cfg.NewsWindow = defaultNewsSitemapWindow
+	}
+	if cfg.MaxURLs <= 0 || cfg.MaxURLs > sitemap.MaxURLs {
+		cfg.MaxURLs = sitemap.MaxURLs
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = time.Now
+	}
+
+	handler := &SitemapHandler{
+		NewsService:  newsSvc,
+		TopicService: topicSvc,
+		Config:       cfg,
+	}
+	handleRoutes(mux, map[string]http.HandlerFunc{
+		"sitemap.index": handler.Index,
+		"sitemap.page":  handler.Sitemap,
+	})
+}
+
+func (a *SitemapHandler) pages(total int64) int64 {
+	return (total + int64(a.Config.MaxURLs) - 1) / int64(a.Config.MaxURLs)
+}
+
+func (a *SitemapHandler) pageLoc(name string, page int64) string {
+	if page == 1 {
+		return fmt.Sprintf("%s/sitemaps/%s.xml", a.Config.SiteURL, name)
+	}
+	return fmt.Sprintf("%s/sitemaps/%s-%d.xml", a.Config.SiteURL, name, page)
+}
+
+// Index serves the sitemap index listing the monthly news, topic and Google
+// News sitemaps
+func (a *SitemapHandler) Index(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	archive, err := a.NewsService.Archive(ctx, domain.NewsFilter{Status: string(domain.Published)})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	_, totalTopics, err := a.TopicService.Fetch(ctx, domain.TopicFilter{Limit: 1, Page: 1})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	entries := []sitemap.IndexEntry{{Loc: a.Config.SiteURL + "/sitemaps/google-news.xml"}}
+	for _, month := range archive {
+		name := "news-" + month.Month.Format("2006-01")
+		for page := int64(1); page <= a.pages(month.Total); page++ {
+			entries = append(entries, sitemap.IndexEntry{
+				Loc:     a.pageLoc(name, page),
+				LastMod: month.LastModified,
+			})
+		}
+	}
+	for page := int64(1); page <= a.pages(totalTopics); page++ {
+		entries = append(entries, sitemap.IndexEntry{Loc: a.pageLoc("topics", page)})
+	}
+
+	w.Header().Set("Content-Type", sitemap.ContentType)
+	if err = sitemap.WriteIndex(w, entries); err != nil {
+		logrus.Error(err)
+	}
+}
+
+// Sitemap serves /sitemaps/news-{yyyy}-{mm}[-{page}].xml,
+// /sitemaps/topics[-{page}].xml and /sitemaps/google-news.xml
+func (a *SitemapHandler) Sitemap(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("file")
+	if name == "google-news.xml" {
+		a.googleNews(w, r)
+		return
+	}
+	if m := newsSitemapPattern.FindStringSubmatch(name); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month >= 1 && month <= 12 {
+			a.news(w, r, year, time.Month(month), parsePage(m[3]))
+			return
+		}
+	}
+	if m := topicSitemapPattern.FindStringSubmatch(name); m != nil {
+		a.topics(w, r, parsePage(m[1]))
+		return
+	}
+	writeError(w, r, domain.ErrNotFound)
+}
+
+func parsePage(s string) int64 {
+	page, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || page < 1 {
+		return 1
+	}
+	return page
+}
+
+// stream writes a sitemap filled by fill, errors are reported with their
+// status code as long as no URL has been sent
+func (a *SitemapHandler) stream(w http.ResponseWriter, r *http.Request, news, allowEmpty bool, fill func(sw *sitemap.Writer) error) {
+	sw := sitemap.NewWriter(w, news)
+	w.Header().Set("Content-Type", sitemap.ContentType)
+
+	err := fill(sw)
+	switch {
+	case err != nil && sw.Count() == 0:
+		writeError(w, r, err)
+		return
+	case err != nil:
+		logrus.Error(err)
+	case sw.Count() == 0 && !allowEmpty:
+		writeError(w, r, domain.ErrNotFound)
+		return
+	}
+
+	if err = sw.Close(); err != nil {
+		logrus.Error(err)
+	}
+}
+
+func (a *SitemapHandler) newsLoc(n domain.News) string {
+	return fmt.Sprintf("%s/news/%d", a.Config.SiteURL, n.ID)
+}
+
+func (a *SitemapHandler) news(w http.ResponseWriter, r *http.Request, year int, month time.Month, page int64) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	filter := domain.NewsFilter{
+		Status:    string(domain.Published),
+		StartDate: start,
+		EndDate:   start.AddDate(0, 1, 0).Add(-time.Microsecond),
+		SortBy:    "created_at",
+		Limit:     int64(a.Config.MaxURLs),
+		Page:      page,
+	}
+
+	a.stream(w, r, false, false, func(sw *sitemap.Writer) error {
+		return a.NewsService.Stream(r.Context(), filter, func(n domain.News) error {
+			return sw.Write(sitemap.URL{Loc: a.newsLoc(n), LastMod: n.UpdatedAt})
+		})
+	})
+}
+
+func (a *SitemapHandler) topics(w http.ResponseWriter, r *http.Request, page int64) {
+	filter := domain.TopicFilter{
+		SortBy: "id",
+		Limit:  int64(a.Config.MaxURLs),
+		Page:   page,
+	}
+
+	a.stream(w, r, false, page == 1, func(sw *sitemap.Writer) error {
+		return a.TopicService.Stream(r.Context(), filter, func(t domain.Topic) error {
+			return sw.Write(sitemap.URL{
+				Loc:     fmt.Sprintf("%s/topic/%d", a.Config.SiteURL, t.ID),
+				LastMod: t.UpdatedAt,
+			})
+		})
+	})
+}
+
+func (a *SitemapHandler) googleNews(w http.ResponseWriter, r *http.Request) {
+	filter := domain.NewsFilter{
+		Status:    string(domain.Published),
+		StartDate: a.Config.Clock().Add(-a.Config.NewsWindow),
+		SortBy:    "created_at",
+		SortOrder: "desc",
+		Limit:     sitemap.MaxNewsURLs,
+		Page:      1,
+	}
+
+	a.stream(w, r, true, true, func(sw *sitemap.Writer) error {
+		return a.NewsService.Stream(r.Context(), filter, func(n domain.News) error {
+			keywords := make([]string, 0, len(n.Topics))
+			for _, t := range n.Topics {
+				keywords = append(keywords, t.Name)
+			}
+			return sw.Write(sitemap.URL{
+				Loc: a.newsLoc(n),
+				News: &sitemap.News{
+					PublicationName:     a.Config.PublicationName,
+					PublicationLanguage: a.Config.PublicationLanguage,
+					PublicationDate:     n.CreatedAt,
+					Title:               n.Title,
+					Keywords:            keywords,
+				},
+			})
+		})
+	})
+}
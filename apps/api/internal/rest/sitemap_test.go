@@ -0,0 +1,113 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type stubSitemapService struct {
+	news   []domain.News
+	filter domain.NewsFilter
+}
+
+func (s *stubSitemapService) Archive(context.Context, domain.NewsFilter) ([]domain.NewsArchive, error) {
+	return []domain.NewsArchive{
+		{Month: time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), Total: 2, LastModified: time.Date(2024, 9, 30, 8, 0, 0, 0, time.UTC)},
+		{Month: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), Total: 3, LastModified: time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)},
+	}, nil
+}
+
+func (s *stubSitemapService) Stream(_ context.Context, filter domain.NewsFilter, fn func(domain.News) error) error {
+	s.filter = filter
+	for _, n := range s.news {
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubSitemapService) Fetch(context.Context, domain.TopicFilter) ([]domain.Topic, int64, error) {
+	return []domain.Topic{{ID: 1}}, 1, nil
+}
+
+type stubSitemapTopicService struct {
+	*stubSitemapService
+}
+
+func (s stubSitemapTopicService) Stream(_ context.Context, _ domain.TopicFilter, fn func(domain.Topic) error) error {
+	return fn(domain.Topic{ID: 1, Name: "Health"})
+}
+
+func newSitemapServer(svc *stubSitemapService) *http.ServeMux {
+	mux := http.NewServeMux()
+	rest.NewSitemapHandler(mux, svc, stubSitemapTopicService{svc}, rest.SitemapConfig{
+		SiteURL:             "https://news.example.com",
+		PublicationName:     "News",
+		PublicationLanguage: "en",
+		MaxURLs:             2,
+		Clock: func() time.Time {
+			return time.Date(2024, 10, 28, 12, 0, 0, 0, time.UTC)
+		},
+	})
+	return mux
+}
+
+func TestSitemapIndex(t *testing.T) {
+	mux := newSitemapServer(&stubSitemapService{})
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemap.xml", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/xml; charset=utf-8", rr.Header().Get("Content-Type"))
+
+	body := rr.Body.String()
+	assert.Contains(t, body, "<loc>https://news.example.com/sitemaps/google-news.xml</loc>")
+	assert.Contains(t, body, "<loc>https://news.example.com/sitemaps/news-2024-09.xml</loc>")
+	assert.NotContains(t, body, "news-2024-09-2.xml")
+	assert.Contains(t, body, "<loc>https://news.example.com/sitemaps/news-2024-10.xml</loc>")
+	assert.Contains(t, body, "<loc>https://news.example.com/sitemaps/news-2024-10-2.xml</loc>")
+	assert.Contains(t, body, "<loc>https://news.example.com/sitemaps/topics.xml</loc>")
+}
+
+func TestSitemapNews(t *testing.T) {
+	svc := &stubSitemapService{news: []domain.News{
+		{ID: 7, Title: "Health", CreatedAt: time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC), Topics: []domain.TopicNews{{ID: 1, Name: "Health"}}},
+	}}
+	mux := newSitemapServer(svc)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemaps/news-2024-10-2.xml", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "<loc>https://news.example.com/news/7</loc>")
+	assert.Equal(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), svc.filter.StartDate)
+	assert.Equal(t, time.Date(2024, 10, 31, 23, 59, 59, 999999000, time.UTC), svc.filter.EndDate)
+	assert.Equal(t, int64(2), svc.filter.Page)
+	assert.Equal(t, int64(2), svc.filter.Limit)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemaps/google-news.xml", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "<news:keywords>Health</news:keywords>")
+	assert.Equal(t, time.Date(2024, 10, 26, 12, 0, 0, 0, time.UTC), svc.filter.StartDate)
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/sitemaps/topics.xml", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), "<loc>https://news.example.com/topic/1</loc>")
+
+	svc.news = nil
+	for _, target := range []string{"/sitemaps/news-2024-11.xml", "/sitemaps/news-2024-13.xml", "/sitemaps/other.xml"} {
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusNotFound, rr.Code, target)
+	}
+}
@@ -2,12 +2,21 @@ package rest
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"github.com/bxcodec/go-clean-arch/domain"
 	"github.com/bxcodec/go-clean-arch/internal/dto"
+	"github.com/bxcodec/go-clean-arch/internal/dto/topic"
 	"gopkg.in/go-playground/validator.v9"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"time"
+)
+
+const (
+	defaultStatsWeeks = 12
+	maxStatsWeeks     = 104
 )
 
 // TopicService represents the topic's use cases
@@ -15,131 +24,122 @@ import (
 //go:generate mockery --name TopicService
 type TopicService interface {
 	Fetch(ctx context.Context, filter domain.TopicFilter) ([]domain.Topic, int64, error)
+	Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) error
 	GetByID(ctx context.Context, id int64) (domain.Topic, error)
+	Stats(ctx context.Context, id int64, weeks int64) (domain.TopicActivity, error)
 	Update(ctx context.Context, ar *domain.Topic) error
 	GetByTitle(ctx context.Context, title string) (domain.Topic, error)
 	Store(context.Context, *domain.Topic) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, version int64) error
 }
 
 // TopicHandler represents the HTTP handler for topics
 type TopicHandler struct {
 	Service TopicService
+	Config  ConcurrencyConfig
 }
 
 // NewTopicHandler initializes the topic resources endpoints
-func NewTopicHandler(mux *http.ServeMux, svc TopicService) {
+func NewTopicHandler(mux *http.ServeMux, svc TopicService, cfg ConcurrencyConfig) {
 	handler := &TopicHandler{
 		Service: svc,
+		Config:  cfg,
 	}
-	mux.HandleFunc("/topic", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			handler.Fetch(w, r)
-		case http.MethodPost:
-			handler.Store(w, r)
-		default:
-			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-		}
+	handleRoutes(mux, map[string]http.HandlerFunc{
+		"topic.list":    negotiate(handler.Fetch),
+		"topic.create":  negotiate(handler.Store),
+		"topic.export":  handler.Export,
+		"topic.get":     negotiate(withID(handler.GetByID)),
+		"topic.replace": negotiate(withID(handler.Update)),
+		"topic.patch":   negotiate(withID(handler.Patch)),
+		"topic.delete":  negotiate(withID(handler.Delete)),
+		"topic.stats":   negotiate(withID(handler.Stats)),
 	})
-	mux.HandleFunc("/topic/", handler.HandleTopicByID)
 }
 
-// HandleTopicByID routes requests to the appropriate handler based on HTTP method
-func (a *TopicHandler) HandleTopicByID(w http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case http.MethodGet:
-		a.GetByID(w, r)
-	case http.MethodPut:
-		a.Update(w, r)
-	case http.MethodDelete:
-		a.Delete(w, r)
-	default:
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+// parseTopicFilter reads the optional topic filters from query parameters,
+// invalid values are ignored
+func parseTopicFilter(query url.Values) domain.TopicFilter {
+	filter := domain.TopicFilter{
+		Name:      query.Get("name"),
+		SortBy:    query.Get("sort_by"),
+		SortOrder: query.Get("sort_order"),
 	}
-}
-
-func (a *TopicHandler) Fetch(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query()
-	limit, err := strconv.Atoi(query.Get("limit"))
-	if err != nil || limit <= 0 {
-		limit = defaultLimit
+	if withStats, err := strconv.ParseBool(query.Get("with_stats")); err == nil {
+		filter.WithStats = withStats
 	}
-	page, err := strconv.Atoi(query.Get("page"))
-	if err != nil || page <= 0 {
-		page = defaultPage
+	if limit, err := strconv.ParseInt(query.Get("limit"), 10, 64); err == nil && limit > 0 {
+		filter.Limit = limit
 	}
-
-	idStr := query.Get("id")
-	name := query.Get("name")
-	sortBy := query.Get("sort_by")
-	sortOrder := query.Get("sort_order")
-
-	// Build TopicFilter
-	filter := domain.TopicFilter{
-		Limit: int64(limit),
-		Page:  int64(page),
+	if page, err := strconv.ParseInt(query.Get("page"), 10, 64); err == nil && page > 0 {
+		filter.Page = page
 	}
-
-	if idStr != "" {
-		if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
-			filter.ID = id
-		}
+	if id, err := strconv.ParseInt(query.Get("id"), 10, 64); err == nil {
+		filter.ID = id
 	}
-	if name != "" {
-		filter.Name = name
+	return filter
+}
+
+// Fetch handles GET /topic, with_stats=true adds the stats of every topic,
+// which can then be sorted by
+func (a *TopicHandler) Fetch(w http.ResponseWriter, r *http.Request) {
+	filter := parseTopicFilter(r.URL.Query())
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
 	}
-	if sortBy != "" {
-		filter.SortBy = sortBy
+	if filter.Page <= 0 {
+		filter.Page = defaultPage
 	}
-	if sortOrder != "" {
-		filter.SortOrder = sortOrder
+	if slices.Contains(domain.TopicStatsSorts, filter.SortBy) && !filter.WithStats {
+		writeError(w, r, fmt.Errorf("%w: sorting by %s requires with_stats=true", domain.ErrBadParamInput, filter.SortBy))
+		return
 	}
 
 	ctx := r.Context()
 	listAr, totalData, err := a.Service.Fetch(ctx, filter)
 	if err != nil {
-		http.Error(w, dto.ResponseError{Message: err.Error()}.Message, dto.GetStatusCode(err))
+		writeError(w, r, err)
 		return
 	}
 
-	totalPages := (int64(totalData) + int64(filter.Limit) - 1) / int64(filter.Limit)
-
 	response := dto.Response{
-		Data: listAr,
-		Meta: dto.PaginationMeta{
-			CurrentPage: filter.Page,
-			TotalPages:  totalPages,
-			TotalData:   totalData,
-		},
-	}
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(response)
-	if err != nil {
-		return
+		Data: linkTopics(r, listAr),
+		Meta: paginate(w, r, filter.Page, filter.Limit, totalData),
 	}
+	writeConditional(w, r, response, 0, time.Time{})
 }
 
-func (a *TopicHandler) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/topic/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		http.Error(w, domain.ErrNotFound.Error(), http.StatusNotFound)
-		return
-	}
-
+func (a *TopicHandler) GetByID(w http.ResponseWriter, r *http.Request, id int64) {
 	ctx := r.Context()
 	listAr, _, err := a.Service.Fetch(ctx, domain.TopicFilter{ID: id, Page: 1, Limit: 1})
 	if err != nil || len(listAr) == 0 {
-		http.Error(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		writeError(w, r, domain.ErrNotFound)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	err = json.NewEncoder(w).Encode(listAr[0])
+	topic := linkTopics(r, listAr)[0]
+	writeConditional(w, r, topic, topic.Version, topic.UpdatedAt)
+}
+
+// Stats handles GET /topic/{id}/stats, the stats of a topic with the counts
+// of its news per week over the last weeks (12 by default, at most 104)
+func (a *TopicHandler) Stats(w http.ResponseWriter, r *http.Request, id int64) {
+	weeks := int64(defaultStatsWeeks)
+	if s := r.URL.Query().Get("weeks"); s != "" {
+		var err error
+		weeks, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || weeks < 1 || weeks > maxStatsWeeks {
+			writeError(w, r, fmt.Errorf("%w: weeks must be between 1 and %d", domain.ErrBadParamInput, maxStatsWeeks))
+			return
+		}
+	}
+
+	stats, err := a.Service.Stats(r.Context(), id, weeks)
 	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+	writeConditional(w, r, stats, 0, time.Time{})
 }
 
 func isRequestTopicValid(m *domain.Topic) (bool, error) {
@@ -150,74 +150,106 @@ func isRequestTopicValid(m *domain.Topic) (bool, error) {
 
 func (a *TopicHandler) Store(w http.ResponseWriter, r *http.Request) {
 	var createTopicReq domain.Topic
-	err := json.NewDecoder(r.Body).Decode(&createTopicReq)
+	err := decodeRequest(r, &createTopicReq)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		writeError(w, r, err)
 		return
 	}
 
 	if _, err = isRequestTopicValid(&createTopicReq); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err))
 		return
 	}
 
 	ctx := r.Context()
 	err = a.Service.Store(ctx, &createTopicReq)
 	if err != nil {
-		http.Error(w, dto.ResponseError{Message: err.Error()}.Message, dto.GetStatusCode(err))
+		writeError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(map[string]string{"message": "success create topic"})
+	w.Header().Set("Location", routePath("topic.get", createTopicReq.ID))
+	writeResponse(w, r, http.StatusCreated, dto.ResponseMessage{Message: "success create topic"})
+}
+
+func (a *TopicHandler) Update(w http.ResponseWriter, r *http.Request, id int64) {
+	version, err := a.Config.expectedVersion(r)
 	if err != nil {
+		writeError(w, r, err)
 		return
 	}
-}
 
-func (a *TopicHandler) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/topic/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	var replaceTopicReq topic.ReplaceTopicReq
+	err = decodeRequest(r, &replaceTopicReq)
 	if err != nil {
-		http.Error(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		writeError(w, r, err)
+		return
+	}
+	if err = validator.New().Struct(&replaceTopicReq); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err))
 		return
 	}
 
-	updateTopicReq := domain.Topic{
-		ID: id,
+	ctx := r.Context()
+	err = a.Service.Update(ctx, &domain.Topic{ID: id, Name: replaceTopicReq.Name, Version: version})
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
-	err = json.NewDecoder(r.Body).Decode(&updateTopicReq)
+
+	writeResponse(w, r, http.StatusCreated, dto.ResponseMessage{Message: "success update topic"})
+}
+
+// Patch applies the JSON Merge Patch or JSON Patch of the request body to the
+// topic, at the version the patch was computed on
+func (a *TopicHandler) Patch(w http.ResponseWriter, r *http.Request, id int64) {
+	version, err := a.Config.expectedVersion(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		writeError(w, r, err)
 		return
 	}
 
 	ctx := r.Context()
-	err = a.Service.Update(ctx, &updateTopicReq)
+	current, err := a.Service.GetByID(ctx, id)
 	if err != nil {
-		http.Error(w, dto.ResponseError{Message: err.Error()}.Message, dto.GetStatusCode(err))
+		writeError(w, r, err)
+		return
+	}
+	if version != 0 && version != current.Version {
+		writeError(w, r, domain.ErrPreconditionFailed)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	err = json.NewEncoder(w).Encode(map[string]string{"message": "success update topic"})
+	var replaceTopicReq topic.ReplaceTopicReq
+	if err = patchDocument(w, r, topic.ReplaceTopicReq{Name: current.Name}, &replaceTopicReq); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err = validator.New().Struct(&replaceTopicReq); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %v", domain.ErrBadParamInput, err))
+		return
+	}
+
+	err = a.Service.Update(ctx, &domain.Topic{ID: id, Name: replaceTopicReq.Name, Version: current.Version})
 	if err != nil {
+		writeError(w, r, err)
 		return
 	}
+
+	writeResponse(w, r, http.StatusOK, dto.ResponseMessage{Message: "success update topic"})
 }
 
-func (a *TopicHandler) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Path[len("/topic/"):]
-	id, err := strconv.ParseInt(idStr, 10, 64)
+func (a *TopicHandler) Delete(w http.ResponseWriter, r *http.Request, id int64) {
+	version, err := a.Config.expectedVersion(r)
 	if err != nil {
-		http.Error(w, domain.ErrNotFound.Error(), http.StatusNotFound)
+		writeError(w, r, err)
 		return
 	}
 
 	ctx := r.Context()
-	err = a.Service.Delete(ctx, id)
+	err = a.Service.Delete(ctx, id, version)
 	if err != nil {
-		http.Error(w, dto.ResponseError{Message: err.Error()}.Message, dto.GetStatusCode(err))
+		writeError(w, r, err)
 		return
 	}
 
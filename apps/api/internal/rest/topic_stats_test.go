@@ -0,0 +1,89 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+type statsTopicService struct {
+	stubTopicService
+	filter domain.TopicFilter
+	weeks  int64
+}
+
+func (s *statsTopicService) Fetch(_ context.Context, filter domain.TopicFilter) ([]domain.Topic, int64, error) {
+	s.filter = filter
+	t := domain.Topic{ID: 1, Name: "Health"}
+	if filter.WithStats {
+		lastPublishedAt := time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)
+		t.Stats = &domain.TopicStats{
+			Published:       2,
+			Draft:           1,
+			LastPublishedAt: &lastPublishedAt,
+			TopAuthors:      []domain.TopicAuthor{{ID: 1, Name: "Deni", Count: 2}},
+		}
+	}
+	return []domain.Topic{t}, 1, nil
+}
+
+func (s *statsTopicService) Stats(_ context.Context, id int64, weeks int64) (domain.TopicActivity, error) {
+	s.weeks = weeks
+	if id != 1 {
+		return domain.TopicActivity{}, domain.ErrNotFound
+	}
+	return domain.TopicActivity{
+		TopicID:    1,
+		TopicStats: domain.TopicStats{Published: 2, TopAuthors: []domain.TopicAuthor{}},
+		Weeks:      []domain.TopicWeek{{Week: time.Date(2024, 10, 28, 0, 0, 0, 0, time.UTC), Published: 2}},
+	}, nil
+}
+
+func TestTopicStats(t *testing.T) {
+	svc := &statsTopicService{}
+	mux := http.NewServeMux()
+	rest.NewTopicHandler(mux, svc, rest.ConcurrencyConfig{})
+
+	rr := serve(mux, http.MethodGet, "/topic", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.False(t, svc.filter.WithStats)
+	assert.NotContains(t, rr.Body.String(), "stats")
+
+	rr = serve(mux, http.MethodGet, "/topic?with_stats=true&sort_by=published&sort_order=desc", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	assert.True(t, svc.filter.WithStats)
+	assert.Equal(t, "published", svc.filter.SortBy)
+	assert.Contains(t, rr.Body.String(), `"stats":{"published":2,"draft":1,"last_published_at":"2024-10-28T09:00:00Z",`+
+		`"top_authors":[{"id":1,"name":"Deni","count":2}]}`)
+
+	// the stats can only be sorted by when they are loaded
+	rr = serve(mux, http.MethodGet, "/topic?sort_by=draft", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = serve(mux, http.MethodGet, "/topic/1/stats", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(12), svc.weeks)
+	assert.JSONEq(t, `{"topic_id": 1, "published": 2, "draft": 0, "top_authors": [],
+		"weeks": [{"week": "2024-10-28T00:00:00Z", "published": 2, "draft": 0}]}`, rr.Body.String())
+
+	rr = serve(mux, http.MethodGet, "/topic/1/stats?weeks=52", "", http.Header{"Accept": {"application/xml"}})
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, int64(52), svc.weeks)
+	assert.Contains(t, rr.Body.String(), "<weeks><week><week>2024-10-28T00:00:00Z</week><published>2</published>")
+
+	rr = serve(mux, http.MethodGet, "/topic/1/stats?weeks=105", "", nil)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr = serve(mux, http.MethodGet, "/topic/2/stats", "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr = serve(mux, http.MethodGet, "/topic/1/authors", "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	rr = serve(mux, http.MethodDelete, "/topic/1/stats", "", nil)
+	assert.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+}
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// Deprecation describes the deprecation of a version or of a route, sent with
+// the Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers
+type Deprecation struct {
+	// At is when the routes are or will be deprecated, they are not when zero
+	At time.Time
+	// Sunset is when the routes will stop being served
+	Sunset time.Time
+	// Link is the URL of the documentation of the deprecation, e.g. a
+	// migration guide
+	Link string
+}
+
+// merge returns d with the fields set in o
+func (d Deprecation) merge(o Deprecation) Deprecation {
+	if !o.At.IsZero() {
+		d.At = o.At
+	}
+	if !o.Sunset.IsZero() {
+		d.Sunset = o.Sunset
+	}
+	if o.Link != "" {
+		d.Link = o.Link
+	}
+	return d
+}
+
+func (d Deprecation) setHeaders(h http.Header) {
+	if !d.At.IsZero() {
+		h.Set("Deprecation", "@"+strconv.FormatInt(d.At.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		h.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, d.Link))
+	}
+}
+
+// APIVersion is a set of routes served under a path prefix, so that a new
+// version can change the representations without breaking the clients of
+// the previous one
+type APIVersion struct {
+	// Name prefixes the paths of the version, v1 serves /v1/news
+	Name string
+	// Register registers the routes of the version on mux at their
+	// unprefixed paths, like NewNewsHandler
+	Register func(mux *http.ServeMux)
+	// Deprecation applies to every route of the version
+	Deprecation Deprecation
+	// Routes overrides the fields of Deprecation which are set for the
+	// routes under the given unprefixed paths, e.g. /news/import, the
+	// longest matching path applying
+	Routes map[string]Deprecation
+}
+
+// VersioningConfig lists the versions served by MountVersions
+type VersioningConfig struct {
+	Versions []APIVersion
+	// Alias is the name of the version also served at the unprefixed paths,
+	// for the clients predating versioning
+	Alias string
+	// AliasDeprecation overrides the deprecation of the Alias version at the
+	// unprefixed paths
+	AliasDeprecation Deprecation
+}
+
+// MountVersions serves every version under its prefix on mux, and the Alias
+// version at the paths which are not registered on mux. Relative Location
+// headers of the prefixed routes are prefixed too.
+func MountVersions(mux *http.ServeMux, cfg VersioningConfig) {
+	for _, v := range cfg.Versions {
+		prefix := "/" + v.Name
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, v.handler(prefix, Deprecation{})))
+		if v.Name == cfg.Alias {
+			mux.Handle("/", v.handler("", cfg.AliasDeprecation))
+		}
+	}
+}
+
+// handler serves the routes of v, prefix is the path prefix the requests
+// were served at
+func (v APIVersion) handler(prefix string, alias Deprecation) http.Handler {
+	mux := http.NewServeMux()
+	v.Register(mux)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, domain.ErrNotFound)
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v.deprecation(r.URL.Path).merge(alias).setHeaders(w.Header())
+		if prefix != "" {
+			w = &versionWriter{ResponseWriter: w, prefix: prefix}
+			r = r.WithContext(context.WithValue(r.Context(), versionPrefixKey{}, prefix))
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// deprecation returns the deprecation of the route serving path
+func (v APIVersion) deprecation(path string) Deprecation {
+	var route string
+	for p := range v.Routes {
+		if len(p) > len(route) && (path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/")) {
+			route = p
+		}
+	}
+	if route == "" {
+		return v.Deprecation
+	}
+	return v.Deprecation.merge(v.Routes[route])
+}
+
+// versionWriter prefixes the relative Location header written by the
+// handlers of a version
+type versionWriter struct {
+	http.ResponseWriter
+	prefix      string
+	wroteHeader bool
+}
+
+func (vw *versionWriter) WriteHeader(status int) {
+	if !vw.wroteHeader {
+		vw.wroteHeader = true
+		location := vw.Header().Get("Location")
+		if strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "//") {
+			vw.Header().Set("Location", vw.prefix+location)
+		}
+	}
+	vw.ResponseWriter.WriteHeader(status)
+}
+
+func (vw *versionWriter) Write(b []byte) (int, error) {
+	if !vw.wroteHeader {
+		vw.WriteHeader(http.StatusOK)
+	}
+	return vw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (vw *versionWriter) Unwrap() http.ResponseWriter {
+	return vw.ResponseWriter
+}
@@ -0,0 +1,104 @@
+package rest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+)
+
+// newsRoutes registers a /news resource answering with the given
+// representation of a news
+func newsRoutes(representation string) func(mux *http.ServeMux) {
+	return func(mux *http.ServeMux) {
+		mux.HandleFunc("/news", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.Header().Set("Location", "/news/1")
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			_, _ = fmt.Fprint(w, representation)
+		})
+		mux.HandleFunc("/news/import", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})
+	}
+}
+
+func newAPIVersionsServer() *http.ServeMux {
+	sunset := time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	rest.MountVersions(mux, rest.VersioningConfig{
+		Versions: []rest.APIVersion{
+			{
+				Name:     "v1",
+				Register: newsRoutes(`{"author": "Deni"}`),
+				Deprecation: rest.Deprecation{
+					At:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+					Link: "https://news.example.com/docs/v2",
+				},
+				Routes: map[string]rest.Deprecation{
+					"/news/import": {Sunset: sunset},
+				},
+			},
+			{Name: "v2", Register: newsRoutes(`{"author": {"id": 1, "name": "Deni"}}`)},
+		},
+		Alias:            "v1",
+		AliasDeprecation: rest.Deprecation{Link: "https://news.example.com/docs/versioning"},
+	})
+	return mux
+}
+
+func TestVersions(t *testing.T) {
+	mux := newAPIVersionsServer()
+
+	// the versions are served side by side
+	rr := serve(mux, http.MethodGet, "/v2/news", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"author": {"id": 1, "name": "Deni"}}`, rr.Body.String())
+	assert.Empty(t, rr.Header().Values("Deprecation"))
+
+	rr = serve(mux, http.MethodGet, "/v1/news", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"author": "Deni"}`, rr.Body.String())
+	assert.Equal(t, "@1767225600", rr.Header().Get("Deprecation"))
+	assert.Empty(t, rr.Header().Get("Sunset"))
+	assert.Equal(t, `<https://news.example.com/docs/v2>; rel="deprecation"; type="text/html"`, rr.Header().Get("Link"))
+
+	// the unprefixed paths are aliases of v1
+	rr = serve(mux, http.MethodGet, "/news", "", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `{"author": "Deni"}`, rr.Body.String())
+	assert.Equal(t, "@1767225600", rr.Header().Get("Deprecation"))
+	assert.Equal(t, `<https://news.example.com/docs/versioning>; rel="deprecation"; type="text/html"`, rr.Header().Get("Link"))
+
+	// routes override the deprecation of their version
+	rr = serve(mux, http.MethodPost, "/v1/news/import", "", nil)
+	assert.Equal(t, http.StatusAccepted, rr.Code)
+	assert.Equal(t, "@1767225600", rr.Header().Get("Deprecation"))
+	assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", rr.Header().Get("Sunset"))
+
+	rr = serve(mux, http.MethodGet, "/health", "", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+	rr = serve(mux, http.MethodGet, "/v3/news", "", nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestVersionedLocation(t *testing.T) {
+	mux := newAPIVersionsServer()
+
+	rr := serve(mux, http.MethodPost, "/v2/news", "", nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "/v2/news/1", rr.Header().Get("Location"))
+
+	rr = serve(mux, http.MethodPost, "/news", "", nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "/news/1", rr.Header().Get("Location"))
+}
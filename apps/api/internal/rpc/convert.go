@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	newsv1 "github.com/bxcodec/go-clean-arch/pkg/pb/news/v1"
+)
+
+var statusToProto = map[domain.NewsStatus]newsv1.NewsStatus{
+	domain.Draft:     newsv1.NewsStatus_NEWS_STATUS_DRAFT,
+	domain.Published: newsv1.NewsStatus_NEWS_STATUS_PUBLISHED,
+	domain.Deleted:   newsv1.NewsStatus_NEWS_STATUS_DELETED,
+}
+
+func newsStatus(s newsv1.NewsStatus) (domain.NewsStatus, error) {
+	for status, value := range statusToProto {
+		if value == s {
+			return status, nil
+		}
+	}
+	return "", fmt.Errorf("%w: invalid status %s", domain.ErrBadParamInput, s)
+}
+
+func toNews(n domain.News) *newsv1.News {
+	res := &newsv1.News{
+		Id:        n.ID,
+		Title:     n.Title,
+		Content:   n.Content,
+		Author:    &newsv1.Author{Id: n.Author.ID, Name: n.Author.Name},
+		Status:    statusToProto[n.Status],
+		CreatedAt: timestamppb.New(n.CreatedAt),
+		UpdatedAt: timestamppb.New(n.UpdatedAt),
+		Topics:    make([]*newsv1.TopicRef, 0, len(n.Topics)),
+		Version:   n.Version,
+	}
+	for _, t := range n.Topics {
+		res.Topics = append(res.Topics, &newsv1.TopicRef{Id: t.ID, Name: t.Name})
+	}
+	return res
+}
+
+func toTopic(t domain.Topic) *newsv1.Topic {
+	return &newsv1.Topic{
+		Id:        t.ID,
+		Name:      t.Name,
+		CreatedAt: timestamppb.New(t.CreatedAt),
+		UpdatedAt: timestamppb.New(t.UpdatedAt),
+		Version:   t.Version,
+	}
+}
+
+func toAuthor(a domain.Author) *newsv1.Author {
+	return &newsv1.Author{Id: a.ID, Name: a.Name, CreatedAt: a.CreatedAt, UpdatedAt: a.UpdatedAt}
+}
+
+func newsFilter(f *newsv1.NewsFilter) (domain.NewsFilter, error) {
+	if f == nil {
+		return domain.NewsFilter{}, nil
+	}
+	filter := domain.NewsFilter{
+		ID:        f.GetId(),
+		Title:     f.GetTitle(),
+		AuthorID:  f.GetAuthorId(),
+		TopicID:   f.GetTopicId(),
+		Limit:     f.GetLimit(),
+		Page:      f.GetPage(),
+		SortBy:    f.GetSortBy(),
+		SortOrder: f.GetSortOrder(),
+	}
+	if f.GetStatus() != newsv1.NewsStatus_NEWS_STATUS_UNSPECIFIED {
+		s, err := newsStatus(f.GetStatus())
+		if err != nil {
+			return filter, err
+		}
+		filter.Status = string(s)
+	}
+	if f.StartDate != nil {
+		filter.StartDate = f.StartDate.AsTime()
+	}
+	if f.EndDate != nil {
+		filter.EndDate = f.EndDate.AsTime()
+	}
+	return filter, nil
+}
+
+func topicFilter(f *newsv1.TopicFilter) domain.TopicFilter {
+	return domain.TopicFilter{
+		ID:        f.GetId(),
+		Name:      f.GetName(),
+		Limit:     f.GetLimit(),
+		Page:      f.GetPage(),
+		SortBy:    f.GetSortBy(),
+		SortOrder: f.GetSortOrder(),
+	}
+}
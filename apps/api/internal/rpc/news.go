@@ -0,0 +1,191 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	newsv1 "github.com/bxcodec/go-clean-arch/pkg/pb/news/v1"
+)
+
+const (
+	defaultAuthorNews = 10
+	maxAuthorNews     = 100
+)
+
+// NewsServer implements the news gRPC service
+type NewsServer struct {
+	newsv1.UnimplementedNewsServiceServer
+	Service NewsService
+}
+
+// ListNews streams the news matching the filter
+func (s *NewsServer) ListNews(req *newsv1.ListNewsRequest, stream newsv1.NewsService_ListNewsServer) error {
+	filter, err := newsFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+	return s.Service.Stream(stream.Context(), filter, func(n domain.News) error {
+		return stream.Send(toNews(n))
+	})
+}
+
+// CountNews counts the news matching the filter
+func (s *NewsServer) CountNews(ctx context.Context, req *newsv1.CountNewsRequest) (*newsv1.CountNewsResponse, error) {
+	filter, err := newsFilter(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+	total, err := s.Service.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &newsv1.CountNewsResponse{Total: total}, nil
+}
+
+// GetNews returns a news with its author and topics
+func (s *NewsServer) GetNews(ctx context.Context, req *newsv1.GetNewsRequest) (*newsv1.News, error) {
+	return s.load(ctx, req.GetId())
+}
+
+// CreateNews stores a news and returns it
+func (s *NewsServer) CreateNews(ctx context.Context, req *newsv1.CreateNewsRequest) (*newsv1.News, error) {
+	switch {
+	case strings.TrimSpace(req.GetTitle()) == "":
+		return nil, fmt.Errorf("%w: title is required", domain.ErrBadParamInput)
+	case strings.TrimSpace(req.GetContent()) == "":
+		return nil, fmt.Errorf("%w: content is required", domain.ErrBadParamInput)
+	case req.GetAuthorId() == 0:
+		return nil, fmt.Errorf("%w: author_id is required", domain.ErrBadParamInput)
+	}
+	status, err := newsStatus(req.GetStatus())
+	if err != nil {
+		return nil, err
+	}
+
+	cnr := &news.CreateNewsReq{
+		Title:    req.GetTitle(),
+		Content:  req.GetContent(),
+		AuthorID: req.GetAuthorId(),
+		Status:   status,
+		TopicIDs: append([]int64{}, req.GetTopicIds()...),
+	}
+	if err = s.Service.Store(ctx, cnr); err != nil {
+		return nil, err
+	}
+	return s.load(ctx, cnr.ID)
+}
+
+// UpdateNews changes the fields set in the request and returns the news,
+// only at the expected version unless it is 0
+func (s *NewsServer) UpdateNews(ctx context.Context, req *newsv1.UpdateNewsRequest) (*newsv1.News, error) {
+	id := req.GetId()
+	unr := &news.UpdateNewsReq{
+		ID:       &id,
+		Title:    req.Title,
+		Content:  req.Content,
+		AuthorID: req.AuthorId,
+	}
+	if req.GetStatus() != newsv1.NewsStatus_NEWS_STATUS_UNSPECIFIED {
+		status, err := newsStatus(req.GetStatus())
+		if err != nil {
+			return nil, err
+		}
+		unr.Status = &status
+	}
+	if req.TopicIds != nil {
+		topicIDs := append([]int64{}, req.TopicIds.GetIds()...)
+		unr.TopicIDs = &topicIDs
+	}
+	if version := req.GetExpectedVersion(); version != 0 {
+		unr.Version = &version
+	}
+
+	if err := s.Service.Update(ctx, unr); err != nil {
+		return nil, err
+	}
+	return s.load(ctx, id)
+}
+
+// DeleteNews deletes a news, only at the expected version unless it is 0
+func (s *NewsServer) DeleteNews(ctx context.Context, req *newsv1.DeleteNewsRequest) (*emptypb.Empty, error) {
+	if err := s.Service.Delete(ctx, req.GetId(), req.GetExpectedVersion()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *NewsServer) load(ctx context.Context, id int64) (*newsv1.News, error) {
+	var res *newsv1.News
+	err := s.Service.Stream(ctx, domain.NewsFilter{ID: id, Limit: 1}, func(n domain.News) error {
+		res = toNews(n)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, domain.ErrNotFound
+	}
+	return res, nil
+}
+
+// AuthorServer implements the author gRPC service
+type AuthorServer struct {
+	newsv1.UnimplementedAuthorServiceServer
+	Service NewsService
+}
+
+// GetAuthor returns an author
+func (s *AuthorServer) GetAuthor(ctx context.Context, req *newsv1.GetAuthorRequest) (*newsv1.Author, error) {
+	list, err := s.Service.AuthorsByIDs(ctx, []int64{req.GetId()})
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return toAuthor(list[0]), nil
+}
+
+// ListAuthors streams the authors with the given IDs
+func (s *AuthorServer) ListAuthors(req *newsv1.ListAuthorsRequest, stream newsv1.AuthorService_ListAuthorsServer) error {
+	if len(req.GetIds()) == 0 {
+		return nil
+	}
+	list, err := s.Service.AuthorsByIDs(stream.Context(), req.GetIds())
+	if err != nil {
+		return err
+	}
+	for _, a := range list {
+		if err = stream.Send(toAuthor(a)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListAuthorNews streams the latest news of an author
+func (s *AuthorServer) ListAuthorNews(req *newsv1.ListAuthorNewsRequest, stream newsv1.AuthorService_ListAuthorNewsServer) error {
+	limit := req.GetLimit()
+	switch {
+	case limit <= 0:
+		limit = defaultAuthorNews
+	case limit > maxAuthorNews:
+		limit = maxAuthorNews
+	}
+	list, err := s.Service.LatestByAuthors(stream.Context(), []int64{req.GetAuthorId()}, limit)
+	if err != nil {
+		return err
+	}
+	for _, n := range list {
+		if err = stream.Send(toNews(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	newsv1 "github.com/bxcodec/go-clean-arch/pkg/pb/news/v1"
+)
+
+// NewsService represents the news use cases served over gRPC
+type NewsService interface {
+	Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) error
+	Count(ctx context.Context, filter domain.NewsFilter) (int64, error)
+	LatestByAuthors(ctx context.Context, authorIDs []int64, limit int64) ([]domain.News, error)
+	AuthorsByIDs(ctx context.Context, ids []int64) ([]domain.Author, error)
+	Store(context.Context, *news.CreateNewsReq) error
+	Update(ctx context.Context, ar *news.UpdateNewsReq) error
+	Delete(ctx context.Context, id int64, version int64) error
+}
+
+// TopicService represents the topic use cases served over gRPC
+type TopicService interface {
+	Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) error
+	GetByID(ctx context.Context, id int64) (domain.Topic, error)
+	Store(context.Context, *domain.Topic) error
+	Update(ctx context.Context, ar *domain.Topic) error
+	Delete(ctx context.Context, id int64, version int64) error
+}
+
+// Metadata keys read from incoming calls, the gRPC counterparts of the
+// X-User-ID, X-API-Key and X-Request-ID headers
+const (
+	MetadataUserID    = "x-user-id"
+	MetadataAPIKey    = "x-api-key"
+	MetadataRequestID = "x-request-id"
+)
+
+// NewServer will create a gRPC server serving the news, topic and author
+// services. Calls carry the same request info as REST requests, for the
+// audit log, and domain errors are turned into gRPC status codes.
+func NewServer(ns NewsService, ts TopicService, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptor),
+		grpc.ChainStreamInterceptor(streamInterceptor),
+	}, opts...)
+
+	s := grpc.NewServer(opts...)
+	newsv1.RegisterNewsServiceServer(s, &NewsServer{Service: ns})
+	newsv1.RegisterTopicServiceServer(s, &TopicServer{Service: ts})
+	newsv1.RegisterAuthorServiceServer(s, &AuthorServer{Service: ns})
+	reflection.Register(s)
+	return s
+}
+
+func unaryInterceptor(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx = withRequestInfo(ctx)
+	res, err := handler(ctx, req)
+	return res, statusError(err)
+}
+
+func streamInterceptor(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return statusError(handler(srv, &serverStream{ServerStream: ss, ctx: withRequestInfo(ss.Context())}))
+}
+
+// serverStream overrides the context of a stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// withRequestInfo identifies the caller from the metadata of the call
+func withRequestInfo(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	info := domain.RequestInfo{
+		Actor:     domain.ActorName(first(MetadataUserID), first(MetadataAPIKey)),
+		RequestID: first(MetadataRequestID),
+	}
+	if info.RequestID == "" || len(info.RequestID) > 128 {
+		info.RequestID = domain.NewRequestID()
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		info.ClientIP = p.Addr.String()
+	}
+	return domain.ContextWithRequestInfo(ctx, info)
+}
+
+// statusError turns the domain errors into the matching gRPC status
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, domain.ErrConflict):
+		code = codes.AlreadyExists
+	case errors.Is(err, domain.ErrBadParamInput), errors.Is(err, domain.ErrUnprocessableEntity):
+		code = codes.InvalidArgument
+	case errors.Is(err, domain.ErrTooManyRequests):
+		code = codes.ResourceExhausted
+	case errors.Is(err, domain.ErrPreconditionFailed):
+		code = codes.FailedPrecondition
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,203 @@
+package rpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/rpc"
+	newsv1 "github.com/bxcodec/go-clean-arch/pkg/pb/news/v1"
+)
+
+type stubNewsService struct {
+	rpc.NewsService
+	list   []domain.News
+	stored *news.CreateNewsReq
+	actor  string
+}
+
+func (s *stubNewsService) Stream(_ context.Context, filter domain.NewsFilter, fn func(domain.News) error) error {
+	for _, n := range s.list {
+		if filter.ID != 0 && n.ID != filter.ID {
+			continue
+		}
+		if filter.Status != "" && string(n.Status) != filter.Status {
+			continue
+		}
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubNewsService) Store(ctx context.Context, req *news.CreateNewsReq) error {
+	for _, n := range s.list {
+		if n.Title == req.Title {
+			return domain.ErrConflict
+		}
+	}
+	s.actor = domain.RequestInfoFromContext(ctx).Actor
+	req.ID = int64(len(s.list) + 1)
+	s.stored = req
+	s.list = append(s.list, domain.News{ID: req.ID, Title: req.Title, Status: req.Status})
+	return nil
+}
+
+func (s *stubNewsService) Update(_ context.Context, req *news.UpdateNewsReq) error {
+	for i, n := range s.list {
+		if n.ID != *req.ID {
+			continue
+		}
+		if req.Version != nil && *req.Version != n.Version {
+			return domain.ErrPreconditionFailed
+		}
+		if req.Title != nil {
+			s.list[i].Title = *req.Title
+		}
+		s.list[i].Version++
+		return nil
+	}
+	return domain.ErrNotFound
+}
+
+func (s *stubNewsService) Delete(_ context.Context, id int64, version int64) error {
+	for i, n := range s.list {
+		if n.ID != id {
+			continue
+		}
+		if version != 0 && version != n.Version {
+			return domain.ErrPreconditionFailed
+		}
+		s.list = append(s.list[:i], s.list[i+1:]...)
+		return nil
+	}
+	return domain.ErrNotFound
+}
+
+type stubTopicService struct {
+	rpc.TopicService
+}
+
+func (stubTopicService) GetByID(_ context.Context, id int64) (domain.Topic, error) {
+	if id != 1 {
+		return domain.Topic{}, domain.ErrNotFound
+	}
+	return domain.Topic{ID: 1, Name: "Health"}, nil
+}
+
+func dial(t *testing.T, ns rpc.NewsService, ts rpc.TopicService) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := rpc.NewServer(ns, ts)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestListNewsStreams(t *testing.T) {
+	ns := &stubNewsService{list: []domain.News{
+		{ID: 1, Title: "First", Status: domain.Published, Topics: []domain.TopicNews{{ID: 1, Name: "Health"}}},
+		{ID: 2, Title: "Second", Status: domain.Draft},
+		{ID: 3, Title: "Third", Status: domain.Published},
+	}}
+	client := newsv1.NewNewsServiceClient(dial(t, ns, stubTopicService{}))
+
+	stream, err := client.ListNews(context.Background(), &newsv1.ListNewsRequest{
+		Filter: &newsv1.NewsFilter{Status: newsv1.NewsStatus_NEWS_STATUS_PUBLISHED},
+	})
+	require.NoError(t, err)
+
+	var titles []string
+	for {
+		n, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		titles = append(titles, n.GetTitle())
+		assert.Equal(t, newsv1.NewsStatus_NEWS_STATUS_PUBLISHED, n.GetStatus())
+	}
+	assert.Equal(t, []string{"First", "Third"}, titles)
+}
+
+func TestCreateNews(t *testing.T) {
+	ns := &stubNewsService{list: []domain.News{{ID: 1, Title: "Existing", Status: domain.Draft}}}
+	client := newsv1.NewNewsServiceClient(dial(t, ns, stubTopicService{}))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), rpc.MetadataUserID, "42")
+
+	res, err := client.CreateNews(ctx, &newsv1.CreateNewsRequest{
+		Title: "New", Content: "Body", AuthorId: 1, Status: newsv1.NewsStatus_NEWS_STATUS_DRAFT, TopicIds: []int64{1, 2},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), res.GetId())
+	assert.Equal(t, []int64{1, 2}, ns.stored.TopicIDs)
+	assert.Equal(t, domain.Draft, ns.stored.Status)
+	assert.Equal(t, "user:42", ns.actor)
+
+	_, err = client.CreateNews(ctx, &newsv1.CreateNewsRequest{
+		Title: "Existing", Content: "Body", AuthorId: 1, Status: newsv1.NewsStatus_NEWS_STATUS_DRAFT,
+	})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = client.CreateNews(ctx, &newsv1.CreateNewsRequest{Title: "No status", Content: "Body", AuthorId: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestErrorCodes(t *testing.T) {
+	conn := dial(t, &stubNewsService{}, stubTopicService{})
+
+	_, err := newsv1.NewNewsServiceClient(conn).GetNews(context.Background(), &newsv1.GetNewsRequest{Id: 7})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	topic, err := newsv1.NewTopicServiceClient(conn).GetTopic(context.Background(), &newsv1.GetTopicRequest{Id: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "Health", topic.GetName())
+
+	_, err = newsv1.NewTopicServiceClient(conn).GetTopic(context.Background(), &newsv1.GetTopicRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestExpectedVersion(t *testing.T) {
+	ns := &stubNewsService{list: []domain.News{{ID: 1, Title: "First", Status: domain.Draft, Version: 3}}}
+	client := newsv1.NewNewsServiceClient(dial(t, ns, stubTopicService{}))
+	ctx := context.Background()
+	title := "Renamed"
+
+	_, err := client.UpdateNews(ctx, &newsv1.UpdateNewsRequest{Id: 1, Title: &title, ExpectedVersion: 2})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	res, err := client.UpdateNews(ctx, &newsv1.UpdateNewsRequest{Id: 1, Title: &title, ExpectedVersion: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", res.GetTitle())
+	assert.Equal(t, int64(4), res.GetVersion())
+
+	_, err = client.DeleteNews(ctx, &newsv1.DeleteNewsRequest{Id: 1, ExpectedVersion: 3})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.DeleteNews(ctx, &newsv1.DeleteNewsRequest{Id: 1, ExpectedVersion: 4})
+	require.NoError(t, err)
+	assert.Empty(t, ns.list)
+}
@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	newsv1 "github.com/bxcodec/go-clean-arch/pkg/pb/news/v1"
+)
+
+// TopicServer implements the topic gRPC service
+type TopicServer struct {
+	newsv1.UnimplementedTopicServiceServer
+	Service TopicService
+}
+
+// ListTopics streams the topics matching the filter
+func (s *TopicServer) ListTopics(req *newsv1.ListTopicsRequest, stream newsv1.TopicService_ListTopicsServer) error {
+	return s.Service.Stream(stream.Context(), topicFilter(req.GetFilter()), func(t domain.Topic) error {
+		return stream.Send(toTopic(t))
+	})
+}
+
+// GetTopic returns a topic
+func (s *TopicServer) GetTopic(ctx context.Context, req *newsv1.GetTopicRequest) (*newsv1.Topic, error) {
+	t, err := s.Service.GetByID(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toTopic(t), nil
+}
+
+// CreateTopic stores a topic and returns it
+func (s *TopicServer) CreateTopic(ctx context.Context, req *newsv1.CreateTopicRequest) (*newsv1.Topic, error) {
+	if strings.TrimSpace(req.GetName()) == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrBadParamInput)
+	}
+	t := &domain.Topic{Name: req.GetName()}
+	if err := s.Service.Store(ctx, t); err != nil {
+		return nil, err
+	}
+	return s.GetTopic(ctx, &newsv1.GetTopicRequest{Id: t.ID})
+}
+
+// UpdateTopic renames a topic and returns it, only at the expected version
+// unless it is 0
+func (s *TopicServer) UpdateTopic(ctx context.Context, req *newsv1.UpdateTopicRequest) (*newsv1.Topic, error) {
+	if strings.TrimSpace(req.GetName()) == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrBadParamInput)
+	}
+	if err := s.Service.Update(ctx, &domain.Topic{ID: req.GetId(), Name: req.GetName(), Version: req.GetExpectedVersion()}); err != nil {
+		return nil, err
+	}
+	return s.GetTopic(ctx, &newsv1.GetTopicRequest{Id: req.GetId()})
+}
+
+// DeleteTopic deletes a topic, only at the expected version unless it is 0
+func (s *TopicServer) DeleteTopic(ctx context.Context, req *newsv1.DeleteTopicRequest) (*emptypb.Empty, error) {
+	if err := s.Service.Delete(ctx, req.GetId(), req.GetExpectedVersion()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
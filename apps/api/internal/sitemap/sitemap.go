@@ -0,0 +1,193 @@
+package sitemap
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// ContentType is the media type of sitemap documents
+	ContentType = "application/xml; charset=utf-8"
+	// MaxURLs is the maximum number of URLs in a sitemap or sitemap index file
+	MaxURLs = 50000
+	// MaxNewsURLs is the maximum number of URLs in a Google News sitemap
+	MaxNewsURLs = 1000
+
+	sitemapNS = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	newsNS    = "http://www.google.com/schemas/sitemap-news/0.9"
+)
+
+// ErrTooManyURLs is returned when a sitemap would exceed its URL limit
+var ErrTooManyURLs = errors.New("sitemap URL limit exceeded")
+
+// URL is one entry of a sitemap
+type URL struct {
+	Loc     string
+	LastMod time.Time
+	// News is only written in Google News sitemaps
+	News *News
+}
+
+// News describes an article in a Google News sitemap
+type News struct {
+	PublicationName     string
+	PublicationLanguage string
+	PublicationDate     time.Time
+	Title               string
+	Keywords            []string
+}
+
+type xmlURL struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+	News    *xmlNews `xml:"news:news,omitempty"`
+}
+
+type xmlNews struct {
+	Publication     xmlPublication `xml:"news:publication"`
+	PublicationDate string         `xml:"news:publication_date"`
+	Title           string         `xml:"news:title"`
+	Keywords        string         `xml:"news:keywords,omitempty"`
+}
+
+type xmlPublication struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+// Writer streams a <urlset> document, URLs are written as they come so
+// large archives never have to be held in memory
+type Writer struct {
+	w       *bufio.Writer
+	encoder *xml.Encoder
+	news    bool
+	limit   int
+	count   int
+}
+
+// NewWriter will create a sitemap Writer, news enables the Google News
+// extension and its lower URL limit
+func NewWriter(w io.Writer, news bool) *Writer {
+	buf := bufio.NewWriter(w)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("  ", "  ")
+
+	limit := MaxURLs
+	if news {
+		limit = MaxNewsURLs
+	}
+	return &Writer{w: buf, encoder: encoder, news: news, limit: limit}
+}
+
+// Count returns the number of URLs written so far
+func (w *Writer) Count() int {
+	return w.count
+}
+
+func (w *Writer) start() error {
+	_, err := io.WriteString(w.w, xml.Header)
+	if err != nil {
+		return err
+	}
+	if w.news {
+		_, err = io.WriteString(w.w, `<urlset xmlns="`+sitemapNS+`" xmlns:news="`+newsNS+`">`+"\n")
+	} else {
+		_, err = io.WriteString(w.w, `<urlset xmlns="`+sitemapNS+`">`+"\n")
+	}
+	return err
+}
+
+// Write adds a URL to the sitemap, the document header is only written with
+// the first URL
+func (w *Writer) Write(u URL) error {
+	if w.count >= w.limit {
+		return ErrTooManyURLs
+	}
+	if w.count == 0 {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	w.count++
+
+	entry := xmlURL{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	if w.news && u.News != nil {
+		entry.News = &xmlNews{
+			Publication: xmlPublication{
+				Name:     u.News.PublicationName,
+				Language: u.News.PublicationLanguage,
+			},
+			PublicationDate: u.News.PublicationDate.UTC().Format(time.RFC3339),
+			Title:           u.News.Title,
+			Keywords:        strings.Join(u.News.Keywords, ", "),
+		}
+	}
+	return w.encoder.Encode(entry)
+}
+
+// Close ends the document and flushes it
+func (w *Writer) Close() error {
+	end := "\n</urlset>\n"
+	if w.count == 0 {
+		if err := w.start(); err != nil {
+			return err
+		}
+		end = "</urlset>\n"
+	}
+	if _, err := io.WriteString(w.w, end); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// IndexEntry is one sitemap listed in a sitemap index
+type IndexEntry struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type xmlIndex struct {
+	XMLName  xml.Name      `xml:"sitemapindex"`
+	XMLNS    string        `xml:"xmlns,attr"`
+	Sitemaps []xmlIndexURL `xml:"sitemap"`
+}
+
+type xmlIndexURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// WriteIndex writes a <sitemapindex> document listing entries
+func WriteIndex(w io.Writer, entries []IndexEntry) error {
+	if len(entries) > MaxURLs {
+		return ErrTooManyURLs
+	}
+
+	doc := xmlIndex{XMLNS: sitemapNS, Sitemaps: make([]xmlIndexURL, 0, len(entries))}
+	for _, entry := range entries {
+		u := xmlIndexURL{Loc: entry.Loc}
+		if !entry.LastMod.IsZero() {
+			u.LastMod = entry.LastMod.UTC().Format(time.RFC3339)
+		}
+		doc.Sitemaps = append(doc.Sitemaps, u)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
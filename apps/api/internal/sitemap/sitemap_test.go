@@ -0,0 +1,105 @@
+package sitemap_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/internal/sitemap"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	sw := sitemap.NewWriter(&buf, false)
+	require.NoError(t, sw.Write(sitemap.URL{
+		Loc:     "https://news.example.com/news/1",
+		LastMod: time.Date(2024, 10, 28, 9, 15, 0, 0, time.UTC),
+	}))
+	require.NoError(t, sw.Write(sitemap.URL{Loc: "https://news.example.com/news/2?a=1&b=2"}))
+	require.NoError(t, sw.Close())
+	assert.Equal(t, 2, sw.Count())
+
+	var doc struct {
+		XMLName xml.Name
+		URLs    []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "http://www.sitemaps.org/schemas/sitemap/0.9", doc.XMLName.Space)
+	assert.Equal(t, "urlset", doc.XMLName.Local)
+	require.Len(t, doc.URLs, 2)
+	assert.Equal(t, "2024-10-28T09:15:00Z", doc.URLs[0].LastMod)
+	assert.Equal(t, "https://news.example.com/news/2?a=1&b=2", doc.URLs[1].Loc)
+}
+
+func TestWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	sw := sitemap.NewWriter(&buf, true)
+	require.NoError(t, sw.Close())
+
+	var doc struct {
+		XMLName xml.Name
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "urlset", doc.XMLName.Local)
+}
+
+func TestWriterGoogleNews(t *testing.T) {
+	var buf bytes.Buffer
+	sw := sitemap.NewWriter(&buf, true)
+	for i := 0; i < sitemap.MaxNewsURLs; i++ {
+		require.NoError(t, sw.Write(sitemap.URL{
+			Loc: "https://news.example.com/news/1",
+			News: &sitemap.News{
+				PublicationName:     "News",
+				PublicationLanguage: "en",
+				PublicationDate:     time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC),
+				Title:               "Health Benefits",
+				Keywords:            []string{"Health", "Environment"},
+			},
+		}))
+	}
+	assert.ErrorIs(t, sw.Write(sitemap.URL{Loc: "https://news.example.com/news/2"}), sitemap.ErrTooManyURLs)
+	require.NoError(t, sw.Close())
+
+	var doc struct {
+		URLs []struct {
+			News struct {
+				Name     string `xml:"publication>name"`
+				Date     string `xml:"publication_date"`
+				Keywords string `xml:"keywords"`
+			} `xml:"http://www.google.com/schemas/sitemap-news/0.9 news"`
+		} `xml:"url"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.URLs, sitemap.MaxNewsURLs)
+	assert.Equal(t, "News", doc.URLs[0].News.Name)
+	assert.Equal(t, "2024-10-28T09:00:00Z", doc.URLs[0].News.Date)
+	assert.Equal(t, "Health, Environment", doc.URLs[0].News.Keywords)
+}
+
+func TestWriteIndex(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, sitemap.WriteIndex(&buf, []sitemap.IndexEntry{
+		{Loc: "https://news.example.com/sitemaps/news-2024-10.xml", LastMod: time.Date(2024, 10, 28, 9, 0, 0, 0, time.UTC)},
+		{Loc: "https://news.example.com/sitemaps/topics.xml"},
+	}))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>https://news.example.com/sitemaps/news-2024-10.xml</loc>
+    <lastmod>2024-10-28T09:00:00Z</lastmod>
+  </sitemap>
+  <sitemap>
+    <loc>https://news.example.com/sitemaps/topics.xml</loc>
+  </sitemap>
+</sitemapindex>
+`, buf.String())
+}
@@ -0,0 +1,66 @@
+package news
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+)
+
+// Batch applies the operations in order and returns the outcome of each of
+// them. In atomic mode they run in one transaction which is rolled back when
+// an operation fails, the other operations then fail with
+// domain.ErrBatchAborted. The returned error is only set when the batch as a
+// whole failed, e.g. its transaction could not be committed.
+func (s *Service) Batch(ctx context.Context, operations []news.BatchOperation, mode news.BatchMode) ([]news.BatchResult, error) {
+	results := make([]news.BatchResult, len(operations))
+	if mode != news.BatchAtomic {
+		for i, op := range operations {
+			results[i] = s.apply(ctx, op)
+		}
+		return results, nil
+	}
+
+	failed := -1
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		for i, op := range operations {
+			results[i] = s.apply(ctx, op)
+			if results[i].Err != nil {
+				failed = i
+				return results[i].Err
+			}
+		}
+		return nil
+	})
+	if failed < 0 {
+		return results, err
+	}
+
+	for i, op := range operations {
+		if i != failed {
+			results[i] = news.BatchResult{ID: op.ID, Err: domain.ErrBatchAborted}
+		}
+	}
+	return results, nil
+}
+
+// apply runs one operation of a batch
+func (s *Service) apply(ctx context.Context, op news.BatchOperation) news.BatchResult {
+	switch op.Op {
+	case news.BatchCreate:
+		err := s.Store(ctx, op.Create)
+		return news.BatchResult{ID: op.Create.ID, Err: err}
+	case news.BatchUpdate, news.BatchStatus:
+		id := op.ID
+		op.Update.ID = &id
+		if op.Version != 0 {
+			op.Update.Version = &op.Version
+		}
+		return news.BatchResult{ID: op.ID, Err: s.Update(ctx, op.Update)}
+	case news.BatchDelete:
+		return news.BatchResult{ID: op.ID, Err: s.Delete(ctx, op.ID, op.Version)}
+	default:
+		return news.BatchResult{ID: op.ID, Err: fmt.Errorf("%w: unknown operation %q", domain.ErrBadParamInput, op.Op)}
+	}
+}
@@ -0,0 +1,166 @@
+package news_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	newsDto "github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/news"
+)
+
+// memoryNewsRepo keeps the news in memory, versioned like the database
+type memoryNewsRepo struct {
+	news.NewsRepository
+	list   []domain.News
+	nextID int64
+}
+
+func (m *memoryNewsRepo) GetByID(_ context.Context, id int64) (domain.News, error) {
+	for _, n := range m.list {
+		if n.ID == id {
+			return n, nil
+		}
+	}
+	return domain.News{}, domain.ErrNotFound
+}
+
+func (m *memoryNewsRepo) GetByTitle(_ context.Context, title string) (domain.News, error) {
+	for _, n := range m.list {
+		if n.Title == title {
+			return n, nil
+		}
+	}
+	return domain.News{}, domain.ErrNotFound
+}
+
+func (m *memoryNewsRepo) Store(_ context.Context, req *newsDto.CreateNewsReq) error {
+	m.nextID++
+	req.ID = m.nextID
+	m.list = append(m.list, domain.News{ID: req.ID, Title: req.Title, Status: req.Status, Author: domain.AuthorNews{ID: req.AuthorID}, Version: 1})
+	return nil
+}
+
+func (m *memoryNewsRepo) Update(_ context.Context, req *newsDto.UpdateNewsReq) error {
+	for i, n := range m.list {
+		if n.ID != *req.ID {
+			continue
+		}
+		if req.Version != nil && *req.Version != n.Version {
+			return domain.ErrPreconditionFailed
+		}
+		if req.Title != nil {
+			m.list[i].Title = *req.Title
+		}
+		if req.Status != nil {
+			m.list[i].Status = *req.Status
+		}
+		m.list[i].Version++
+		return nil
+	}
+	return domain.ErrNotFound
+}
+
+func (m *memoryNewsRepo) Delete(_ context.Context, id int64, version int64) error {
+	for i, n := range m.list {
+		if n.ID == id {
+			if version != 0 && version != n.Version {
+				return domain.ErrPreconditionFailed
+			}
+			m.list = append(m.list[:i], m.list[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+type memoryNewsTopicRepo struct {
+	news.NewsTopicRepository
+}
+
+func (memoryNewsTopicRepo) GetByNewsID(context.Context, int64) ([]domain.NewsTopic, error) {
+	return nil, nil
+}
+
+func (memoryNewsTopicRepo) Store(context.Context, *domain.NewsTopic) error {
+	return nil
+}
+
+func (memoryNewsTopicRepo) DeleteByNewsID(context.Context, int64) error {
+	return nil
+}
+
+// rollbackTransactor restores the news when fn fails, like a rolled back
+// transaction
+type rollbackTransactor struct {
+	repo *memoryNewsRepo
+}
+
+func (t rollbackTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	saved := append([]domain.News(nil), t.repo.list...)
+	if err := fn(ctx); err != nil {
+		t.repo.list = saved
+		return err
+	}
+	return nil
+}
+
+func newBatchService() (*news.Service, *memoryNewsRepo) {
+	repo := &memoryNewsRepo{nextID: 2, list: []domain.News{
+		{ID: 1, Title: "First", Status: domain.Draft, Author: domain.AuthorNews{ID: 1}, Version: 1},
+		{ID: 2, Title: "Second", Status: domain.Published, Author: domain.AuthorNews{ID: 1}, Version: 4},
+	}}
+	svc := news.NewService(repo, &fakeAuthorRepo{}, &fakeTopicRepo{}, memoryNewsTopicRepo{}, &fakeAuditRepo{}, rollbackTransactor{repo: repo})
+	return svc, repo
+}
+
+func batchOperations() []newsDto.BatchOperation {
+	published := domain.Published
+	return []newsDto.BatchOperation{
+		{Op: newsDto.BatchCreate, Create: &newsDto.CreateNewsReq{Title: "Third", Content: "Body", AuthorID: 1, Status: domain.Draft}},
+		{Op: newsDto.BatchStatus, ID: 1, Update: &newsDto.UpdateNewsReq{Status: &published}},
+		{Op: newsDto.BatchDelete, ID: 2, Version: 3},
+	}
+}
+
+func TestBatchBestEffort(t *testing.T) {
+	svc, repo := newBatchService()
+
+	results, err := svc.Batch(context.Background(), batchOperations(), newsDto.BatchBestEffort)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, newsDto.BatchResult{ID: 3}, results[0])
+	assert.Equal(t, newsDto.BatchResult{ID: 1}, results[1])
+	assert.ErrorIs(t, results[2].Err, domain.ErrPreconditionFailed)
+
+	require.Len(t, repo.list, 3)
+	assert.Equal(t, domain.Published, repo.list[0].Status)
+	assert.Equal(t, "Third", repo.list[2].Title)
+}
+
+func TestBatchAtomic(t *testing.T) {
+	svc, repo := newBatchService()
+
+	results, err := svc.Batch(context.Background(), batchOperations(), newsDto.BatchAtomic)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, domain.ErrBatchAborted)
+	assert.ErrorIs(t, results[1].Err, domain.ErrBatchAborted)
+	assert.ErrorIs(t, results[2].Err, domain.ErrPreconditionFailed)
+
+	// nothing was applied
+	require.Len(t, repo.list, 2)
+	assert.Equal(t, domain.Draft, repo.list[0].Status)
+
+	operations := batchOperations()
+	operations[2].Version = 4
+	results, err = svc.Batch(context.Background(), operations, newsDto.BatchAtomic)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+	}
+	require.Len(t, repo.list, 2)
+	assert.Equal(t, "Third", repo.list[1].Title)
+}
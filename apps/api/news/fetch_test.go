@@ -0,0 +1,85 @@
+package news_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/news"
+)
+
+// fieldsNewsRepo returns its news with the fields it was asked for
+type fieldsNewsRepo struct {
+	memoryNewsRepo
+	filter domain.NewsFilter
+}
+
+func (f *fieldsNewsRepo) Fetch(_ context.Context, filter domain.NewsFilter) ([]domain.News, int64, error) {
+	f.filter = filter
+	return append([]domain.News(nil), f.list...), int64(len(f.list)), nil
+}
+
+// countingAuthorRepo and countingTopicRepo count the lookups of names
+type countingAuthorRepo struct {
+	fakeAuthorRepo
+	calls int
+}
+
+func (c *countingAuthorRepo) GetByID(ctx context.Context, id int64) (domain.Author, error) {
+	c.calls++
+	return c.fakeAuthorRepo.GetByID(ctx, id)
+}
+
+type countingTopicRepo struct {
+	fakeTopicRepo
+	calls int
+}
+
+func (c *countingTopicRepo) GetByID(ctx context.Context, id int64) (domain.Topic, error) {
+	c.calls++
+	return c.fakeTopicRepo.GetByID(ctx, id)
+}
+
+type topicsOfNews struct {
+	memoryNewsTopicRepo
+}
+
+func (topicsOfNews) GetByNewsID(_ context.Context, newsID int64) ([]domain.NewsTopic, error) {
+	return []domain.NewsTopic{{NewsID: newsID, TopicID: 1}}, nil
+}
+
+func TestFetchExpandsIncludedRelations(t *testing.T) {
+	repo := &fieldsNewsRepo{memoryNewsRepo: memoryNewsRepo{list: []domain.News{
+		{ID: 1, Title: "First", Author: domain.AuthorNews{ID: 1}},
+	}}}
+	authors := &countingAuthorRepo{}
+	topics := &countingTopicRepo{}
+	svc := news.NewService(repo, authors, topics, topicsOfNews{}, &fakeAuditRepo{}, fakeTransactor{})
+	ctx := context.Background()
+
+	res, _, err := svc.Fetch(ctx, domain.NewsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.AuthorNews{ID: 1, Name: "Deni"}, res[0].Author)
+	assert.Equal(t, []domain.TopicNews{{ID: 1, Name: "Health"}}, res[0].Topics)
+	assert.Equal(t, 1, authors.calls)
+	assert.Equal(t, 1, topics.calls)
+
+	// relations which are not included keep their IDs only
+	res, _, err = svc.Fetch(ctx, domain.NewsFilter{Include: []string{}})
+	require.NoError(t, err)
+	assert.Equal(t, domain.AuthorNews{ID: 1}, res[0].Author)
+	assert.Equal(t, []domain.TopicNews{{ID: 1}}, res[0].Topics)
+	assert.Equal(t, 1, authors.calls)
+	assert.Equal(t, 1, topics.calls)
+
+	// the fields are pushed down, unselected relations are not loaded
+	res, _, err = svc.Fetch(ctx, domain.NewsFilter{Fields: []string{"id", "title"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "title"}, repo.filter.Fields)
+	assert.Nil(t, res[0].Topics)
+	assert.Equal(t, 1, authors.calls)
+	assert.Equal(t, 1, topics.calls)
+}
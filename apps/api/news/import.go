@@ -0,0 +1,402 @@
+package news
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+)
+
+const (
+	defaultImportBatchSize = 500
+	// maxImportBatchSize bounds the news held in memory and written by the
+	// transaction of a batch, the repositories split their statements to
+	// bind the parameters the database accepts
+	maxImportBatchSize = 10000
+)
+
+// importer keeps the state of one bulk import, authors and topics are looked
+// up once and cached for the following rows
+type importer struct {
+	s      *Service
+	opts   domain.ImportOptions
+	report *domain.ImportReport
+
+	authorIDs   map[int64]bool
+	authorNames map[string]int64
+	topicIDs    map[int64]bool
+	// topicNames maps lower-cased names to topic IDs, 0 for topics which would
+	// be created in a dry run
+	topicNames map[string]int64
+	titles     map[string]bool
+}
+
+// pendingNews is a valid row waiting to be inserted
+type pendingNews struct {
+	line   int
+	req    *news.CreateNewsReq
+	topics []string // topic names to resolve or create
+}
+
+// Import reads news from reader and stores them in batches. Rows are
+// validated one by one; invalid rows and rows whose title already exists
+// are reported without stopping the import. Missing topics given by name
+// are created. With opts.DryRun nothing is written.
+func (s *Service) Import(ctx context.Context, reader news.ImportReader, opts domain.ImportOptions) (domain.ImportReport, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	opts.BatchSize = min(opts.BatchSize, maxImportBatchSize)
+
+	report := domain.ImportReport{
+		DryRun:        opts.DryRun,
+		CreatedTopics: []string{},
+		Rows:          []domain.ImportRowResult{},
+	}
+	imp := &importer{
+		s:           s,
+		opts:        opts,
+		report:      &report,
+		authorIDs:   map[int64]bool{},
+		authorNames: map[string]int64{},
+		topicIDs:    map[int64]bool{},
+		topicNames:  map[string]int64{},
+		titles:      map[string]bool{},
+	}
+
+	err := imp.run(ctx, reader)
+
+	// rows are reported when their batch is flushed, put them back in the
+	// order of the file
+	sort.SliceStable(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Line < report.Rows[j].Line
+	})
+	return report, err
+}
+
+// run reads every row, only errors which stop the import are returned
+func (imp *importer) run(ctx context.Context, reader news.ImportReader) error {
+	batch := make([]pendingNews, 0, imp.opts.BatchSize)
+	for {
+		row, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		var rowErr *news.RowError
+		if errors.As(err, &rowErr) {
+			imp.fail(rowErr.Line, row.Title, rowErr.Err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		pending, err := imp.prepare(ctx, row)
+		if err != nil {
+			imp.fail(row.Line, row.Title, err)
+			continue
+		}
+		if pending == nil {
+			continue
+		}
+
+		batch = append(batch, *pending)
+		if len(batch) == imp.opts.BatchSize {
+			if err = imp.flush(ctx, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	return imp.flush(ctx, batch)
+}
+
+func (imp *importer) fail(line int, title string, err error) {
+	imp.report.Add(domain.ImportRowResult{Line: line, Title: title, Status: domain.ImportFailed, Reason: err.Error()})
+}
+
+func (imp *importer) skip(line int, title, reason string) {
+	imp.report.Add(domain.ImportRowResult{Line: line, Title: title, Status: domain.ImportSkipped, Reason: reason})
+}
+
+// prepare validates a row and resolves its author and topic IDs, it returns
+// nil when the row is skipped
+func (imp *importer) prepare(ctx context.Context, row news.ImportNewsReq) (*pendingNews, error) {
+	row.Title = strings.TrimSpace(row.Title)
+	switch {
+	case row.Title == "":
+		return nil, errors.New("title is required")
+	case strings.TrimSpace(row.Content) == "":
+		return nil, errors.New("content is required")
+	case row.AuthorID == 0 && row.Author == "":
+		return nil, errors.New("author is required")
+	}
+	if err := row.Status.Validate(); err != nil {
+		return nil, err
+	}
+
+	if imp.titles[row.Title] {
+		imp.skip(row.Line, row.Title, "duplicate title in the import")
+		return nil, nil
+	}
+
+	authorID, err := imp.author(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+
+	topicIDs := make([]int64, 0, len(row.TopicIDs)+len(row.Topics))
+	for _, id := range row.TopicIDs {
+		if err = imp.topicByID(ctx, id); err != nil {
+			return nil, err
+		}
+		topicIDs = appendTopicID(topicIDs, id)
+	}
+	var topics []string
+	for _, name := range row.Topics {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err = imp.topicByName(ctx, name); err != nil {
+			return nil, err
+		}
+		topics = append(topics, name)
+	}
+
+	imp.titles[row.Title] = true
+	return &pendingNews{
+		line: row.Line,
+		req: &news.CreateNewsReq{
+			Title:     row.Title,
+			Content:   row.Content,
+			AuthorID:  authorID,
+			Status:    row.Status,
+			TopicIDs:  topicIDs,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		},
+		topics: topics,
+	}, nil
+}
+
+func (imp *importer) author(ctx context.Context, row news.ImportNewsReq) (int64, error) {
+	if row.AuthorID != 0 {
+		known, ok := imp.authorIDs[row.AuthorID]
+		if !ok {
+			_, err := imp.s.authorRepo.GetByID(ctx, row.AuthorID)
+			if err != nil && !isNotFound(err) {
+				return 0, err
+			}
+			known = err == nil
+			imp.authorIDs[row.AuthorID] = known
+		}
+		if !known {
+			return 0, fmt.Errorf("author %d not found", row.AuthorID)
+		}
+		return row.AuthorID, nil
+	}
+
+	id, ok := imp.authorNames[row.Author]
+	if !ok {
+		a, err := imp.s.authorRepo.GetByName(ctx, row.Author)
+		if err != nil && !isNotFound(err) {
+			return 0, err
+		}
+		id = a.ID
+		imp.authorNames[row.Author] = id
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("author %q not found", row.Author)
+	}
+	return id, nil
+}
+
+func (imp *importer) topicByID(ctx context.Context, id int64) error {
+	known, ok := imp.topicIDs[id]
+	if !ok {
+		_, err := imp.s.topicRepo.GetByID(ctx, id)
+		if err != nil && !isNotFound(err) {
+			return err
+		}
+		known = err == nil
+		imp.topicIDs[id] = known
+	}
+	if !known {
+		return fmt.Errorf("topic %d not found", id)
+	}
+	return nil
+}
+
+// topicByName resolves a topic by name, creating it when missing
+func (imp *importer) topicByName(ctx context.Context, name string) error {
+	key := strings.ToLower(name)
+	if _, ok := imp.topicNames[key]; ok {
+		return nil
+	}
+
+	t, err := imp.s.topicRepo.GetByName(ctx, name)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err == nil {
+		imp.topicNames[key] = t.ID
+		return nil
+	}
+
+	if !imp.opts.DryRun {
+		t = domain.Topic{Name: name}
+		err = imp.s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if err := imp.s.topicRepo.Store(ctx, &t); err != nil {
+				return err
+			}
+			entry, err := domain.NewAuditLog(ctx, domain.AuditCreate, domain.AuditEntityTopic, t.ID, nil, t)
+			if err != nil {
+				return err
+			}
+			return imp.s.auditRepo.Store(ctx, entry)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create topic %q: %w", name, err)
+		}
+	}
+	imp.topicNames[key] = t.ID
+	imp.report.CreatedTopics = append(imp.report.CreatedTopics, name)
+	return nil
+}
+
+// flush skips the news whose title is already used and inserts the others,
+// when the batch fails its rows are retried one by one to find the culprits
+func (imp *importer) flush(ctx context.Context, batch []pendingNews) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	titles := make([]string, 0, len(batch))
+	for _, p := range batch {
+		titles = append(titles, p.req.Title)
+	}
+	existing, err := imp.s.newsRepo.ExistingTitles(ctx, titles)
+	if err != nil {
+		return err
+	}
+	used := make(map[string]bool, len(existing))
+	for _, title := range existing {
+		used[title] = true
+	}
+
+	list := make([]pendingNews, 0, len(batch))
+	for _, p := range batch {
+		if used[p.req.Title] {
+			imp.skip(p.line, p.req.Title, domain.ErrConflict.Error())
+			continue
+		}
+		for _, name := range p.topics {
+			if id := imp.topicNames[strings.ToLower(name)]; id != 0 {
+				p.req.TopicIDs = appendTopicID(p.req.TopicIDs, id)
+			}
+		}
+		list = append(list, p)
+	}
+
+	if imp.opts.DryRun {
+		for _, p := range list {
+			imp.report.Add(domain.ImportRowResult{Line: p.line, Title: p.req.Title, Status: domain.ImportCreated})
+		}
+		return nil
+	}
+
+	if err = imp.insert(ctx, list); err == nil {
+		imp.created(list)
+		return nil
+	}
+	if len(list) == 1 {
+		imp.fail(list[0].line, list[0].req.Title, err)
+		return nil
+	}
+	for _, p := range list {
+		single := []pendingNews{p}
+		if err = imp.insert(ctx, single); err != nil {
+			imp.fail(p.line, p.req.Title, err)
+			continue
+		}
+		imp.created(single)
+	}
+	return nil
+}
+
+func (imp *importer) created(list []pendingNews) {
+	for _, p := range list {
+		imp.report.Add(domain.ImportRowResult{Line: p.line, Title: p.req.Title, Status: domain.ImportCreated, NewsID: p.req.ID})
+	}
+}
+
+// insert stores the news, their topics and their audit entries in one
+// transaction
+func (imp *importer) insert(ctx context.Context, list []pendingNews) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	reqs := make([]*news.CreateNewsReq, 0, len(list))
+	for _, p := range list {
+		p.req.ID = 0
+		reqs = append(reqs, p.req)
+	}
+
+	return imp.s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := imp.s.newsRepo.StoreBatch(ctx, reqs); err != nil {
+			return err
+		}
+
+		var newsTopics []domain.NewsTopic
+		entries := make([]*domain.AuditLog, 0, len(reqs))
+		for _, req := range reqs {
+			if req.ID == 0 {
+				return fmt.Errorf("no ID returned for news %q", req.Title)
+			}
+			after := domain.News{
+				ID:        req.ID,
+				Title:     req.Title,
+				Content:   req.Content,
+				Author:    domain.AuthorNews{ID: req.AuthorID},
+				Status:    req.Status,
+				CreatedAt: req.CreatedAt,
+				UpdatedAt: req.UpdatedAt,
+			}
+			for _, topicID := range req.TopicIDs {
+				newsTopics = append(newsTopics, domain.NewsTopic{NewsID: req.ID, TopicID: topicID})
+				after.Topics = append(after.Topics, domain.TopicNews{ID: topicID})
+			}
+
+			entry, err := domain.NewAuditLog(ctx, domain.AuditCreate, domain.AuditEntityNews, req.ID, nil, after)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+
+		if err := imp.s.newsTopicRepo.StoreBatch(ctx, newsTopics); err != nil {
+			return err
+		}
+		return imp.s.auditRepo.StoreBatch(ctx, entries)
+	})
+}
+
+// appendTopicID appends id unless the news already has this topic
+func appendTopicID(ids []int64, id int64) []int64 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, domain.ErrNotFound)
+}
@@ -0,0 +1,202 @@
+package news_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	newsDto "github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/news"
+)
+
+type fakeNewsRepo struct {
+	news.NewsRepository
+	titles []string
+	nextID int64
+}
+
+func (f *fakeNewsRepo) ExistingTitles(_ context.Context, titles []string) ([]string, error) {
+	var res []string
+	for _, title := range titles {
+		for _, existing := range f.titles {
+			if title == existing {
+				res = append(res, title)
+			}
+		}
+	}
+	return res, nil
+}
+
+func (f *fakeNewsRepo) StoreBatch(_ context.Context, list []*newsDto.CreateNewsReq) error {
+	for _, n := range list {
+		if n.Title == "Broken" {
+			return errors.New("value too long")
+		}
+	}
+	for _, n := range list {
+		f.nextID++
+		n.ID = f.nextID
+		f.titles = append(f.titles, n.Title)
+	}
+	return nil
+}
+
+type fakeAuthorRepo struct {
+	news.AuthorRepository
+}
+
+func (f *fakeAuthorRepo) GetByID(_ context.Context, id int64) (domain.Author, error) {
+	if id != 1 {
+		return domain.Author{}, domain.ErrNotFound
+	}
+	return domain.Author{ID: 1, Name: "Deni"}, nil
+}
+
+func (f *fakeAuthorRepo) GetByName(_ context.Context, name string) (domain.Author, error) {
+	if name != "Deni" {
+		return domain.Author{}, domain.ErrNotFound
+	}
+	return domain.Author{ID: 1, Name: "Deni"}, nil
+}
+
+type fakeTopicRepo struct {
+	news.TopicRepository
+	stored []domain.Topic
+}
+
+func (f *fakeTopicRepo) GetByID(_ context.Context, id int64) (domain.Topic, error) {
+	if id != 1 {
+		return domain.Topic{}, domain.ErrNotFound
+	}
+	return domain.Topic{ID: 1, Name: "Health"}, nil
+}
+
+func (f *fakeTopicRepo) GetByName(_ context.Context, name string) (domain.Topic, error) {
+	if name != "Health" {
+		return domain.Topic{}, domain.ErrNotFound
+	}
+	return domain.Topic{ID: 1, Name: "Health"}, nil
+}
+
+func (f *fakeTopicRepo) Store(_ context.Context, t *domain.Topic) error {
+	t.ID = int64(100 + len(f.stored))
+	f.stored = append(f.stored, *t)
+	return nil
+}
+
+type fakeNewsTopicRepo struct {
+	news.NewsTopicRepository
+	stored []domain.NewsTopic
+}
+
+func (f *fakeNewsTopicRepo) StoreBatch(_ context.Context, list []domain.NewsTopic) error {
+	f.stored = append(f.stored, list...)
+	return nil
+}
+
+type fakeAuditRepo struct {
+	stored []*domain.AuditLog
+}
+
+func (f *fakeAuditRepo) Store(_ context.Context, a *domain.AuditLog) error {
+	f.stored = append(f.stored, a)
+	return nil
+}
+
+func (f *fakeAuditRepo) StoreBatch(_ context.Context, list []*domain.AuditLog) error {
+	f.stored = append(f.stored, list...)
+	return nil
+}
+
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+const importInput = `{"title":"First","content":"Body","author":"Deni","status":"published","topics":["Health","Space",1]}
+{"title":"Existing","content":"Body","author":1,"status":"draft"}
+{"title":"First","content":"Body","author":1,"status":"draft"}
+{"title":"Second","content":"Body","author":"Nobody","status":"draft"}
+{"title":"Third","content":"Body","author":1,"status":"archived"}
+{"title":"Broken","content":"Body","author":1,"status":"draft"}
+{"title":"Fourth","content":"Body","author":1,"status":"draft","topics":[7]}
+{"title":"Fifth","content":"Body","author":1,"status":"draft","topics":["space"]}
+`
+
+type importRepos struct {
+	news      *fakeNewsRepo
+	topic     *fakeTopicRepo
+	newsTopic *fakeNewsTopicRepo
+	audit     *fakeAuditRepo
+}
+
+func newImportService() (*news.Service, importRepos) {
+	repos := importRepos{
+		news:      &fakeNewsRepo{titles: []string{"Existing"}},
+		topic:     &fakeTopicRepo{},
+		newsTopic: &fakeNewsTopicRepo{},
+		audit:     &fakeAuditRepo{},
+	}
+	svc := news.NewService(repos.news, &fakeAuthorRepo{}, repos.topic, repos.newsTopic, repos.audit, fakeTransactor{})
+	return svc, repos
+}
+
+func TestImport(t *testing.T) {
+	svc, repos := newImportService()
+
+	report, err := svc.Import(context.Background(), newsDto.NewNDJSONImportReader(strings.NewReader(importInput)),
+		domain.ImportOptions{BatchSize: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, 8, report.Total)
+	assert.Equal(t, 2, report.Created)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, 4, report.Failed)
+	assert.Equal(t, []string{"Space"}, report.CreatedTopics)
+
+	statuses := map[string]domain.ImportStatus{}
+	for _, row := range report.Rows {
+		if _, ok := statuses[row.Title]; !ok {
+			statuses[row.Title] = row.Status
+		}
+	}
+	assert.Equal(t, map[string]domain.ImportStatus{
+		"First":    domain.ImportCreated,
+		"Existing": domain.ImportSkipped,
+		"Second":   domain.ImportFailed,
+		"Third":    domain.ImportFailed,
+		"Broken":   domain.ImportFailed,
+		"Fourth":   domain.ImportFailed,
+		"Fifth":    domain.ImportCreated,
+	}, statuses)
+
+	assert.ElementsMatch(t, []domain.NewsTopic{
+		{NewsID: 1, TopicID: 1},
+		{NewsID: 1, TopicID: 100},
+		{NewsID: 2, TopicID: 100},
+	}, repos.newsTopic.stored)
+	// one entry for the created topic and one per created news
+	assert.Len(t, repos.audit.stored, 3)
+}
+
+func TestImportDryRun(t *testing.T) {
+	svc, repos := newImportService()
+
+	report, err := svc.Import(context.Background(), newsDto.NewNDJSONImportReader(strings.NewReader(importInput)),
+		domain.ImportOptions{DryRun: true})
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 3, report.Created)
+	assert.Equal(t, []string{"Space"}, report.CreatedTopics)
+	assert.Empty(t, repos.topic.stored)
+	assert.Empty(t, repos.newsTopic.stored)
+	assert.Empty(t, repos.audit.stored)
+	assert.Equal(t, []string{"Existing"}, repos.news.titles)
+}
@@ -2,6 +2,7 @@ package news
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/bxcodec/go-clean-arch/internal/dto/news"
 	"github.com/sirupsen/logrus"
@@ -15,11 +16,19 @@ import (
 //go:generate mockery --name NewsRepository
 type NewsRepository interface {
 	Fetch(ctx context.Context, filter domain.NewsFilter) (res []domain.News, totalPage int64, err error)
+	Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) error
+	Count(ctx context.Context, filter domain.NewsFilter) (int64, error)
+	FetchLatestByAuthors(ctx context.Context, authorIDs []int64, limit int64) ([]domain.News, error)
+	Archive(ctx context.Context, filter domain.NewsFilter) ([]domain.NewsArchive, error)
+	Facets(ctx context.Context, filter domain.NewsFilter, facets []string) ([]domain.Facet, error)
+	AuthorStats(ctx context.Context, filter domain.AuthorStatsFilter) ([]domain.AuthorStats, error)
 	GetByID(ctx context.Context, id int64) (domain.News, error)
 	GetByTitle(ctx context.Context, title string) (domain.News, error)
 	Update(ctx context.Context, ar *news.UpdateNewsReq) error
 	Store(ctx context.Context, a *news.CreateNewsReq) error
-	Delete(ctx context.Context, id int64) error
+	StoreBatch(ctx context.Context, list []*news.CreateNewsReq) error
+	ExistingTitles(ctx context.Context, titles []string) ([]string, error)
+	Delete(ctx context.Context, id int64, version int64) error
 }
 
 // AuthorRepository represent the author's repository contract
@@ -27,6 +36,8 @@ type NewsRepository interface {
 //go:generate mockery --name AuthorRepository
 type AuthorRepository interface {
 	GetByID(ctx context.Context, id int64) (domain.Author, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]domain.Author, error)
+	GetByName(ctx context.Context, name string) (domain.Author, error)
 }
 
 // TopicRepository represent topics repository contract
@@ -34,6 +45,8 @@ type AuthorRepository interface {
 //go:generate mockery --name TopicRepository
 type TopicRepository interface {
 	GetByID(ctx context.Context, id int64) (domain.Topic, error)
+	GetByName(ctx context.Context, name string) (domain.Topic, error)
+	Store(ctx context.Context, t *domain.Topic) error
 }
 
 // NewsTopicRepository represent the news topic repository contract
@@ -43,23 +56,43 @@ type NewsTopicRepository interface {
 	GetByNewsID(ctx context.Context, newsId int64) ([]domain.NewsTopic, error)
 	GetByTopicID(ctx context.Context, topicId int64) ([]domain.NewsTopic, error)
 	Store(ctx context.Context, nt *domain.NewsTopic) (err error)
+	StoreBatch(ctx context.Context, list []domain.NewsTopic) (err error)
 	DeleteByNewsID(ctx context.Context, newsId int64) (err error)
 }
 
+// AuditRepository represent the audit log repository contract
+//
+//go:generate mockery --name AuditRepository
+type AuditRepository interface {
+	Store(ctx context.Context, a *domain.AuditLog) error
+	StoreBatch(ctx context.Context, list []*domain.AuditLog) error
+}
+
+// Transactor runs fn inside a transaction carried by its context
+//
+//go:generate mockery --name Transactor
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type Service struct {
 	newsRepo      NewsRepository
 	authorRepo    AuthorRepository
 	topicRepo     TopicRepository
 	newsTopicRepo NewsTopicRepository
+	auditRepo     AuditRepository
+	transactor    Transactor
 }
 
 // NewService will create a new news service object
-func NewService(n NewsRepository, a AuthorRepository, t TopicRepository, nt NewsTopicRepository) *Service {
+func NewService(n NewsRepository, a AuthorRepository, t TopicRepository, nt NewsTopicRepository, ar AuditRepository, tx Transactor) *Service {
 	return &Service{
 		newsRepo:      n,
 		authorRepo:    a,
 		topicRepo:     t,
 		newsTopicRepo: nt,
+		auditRepo:     ar,
+		transactor:    tx,
 	}
 }
 
@@ -122,7 +155,9 @@ func (s *Service) fillAuthorDetails(ctx context.Context, data []domain.News) ([]
 	return data, nil
 }
 
-func (s *Service) fillTopicDetails(ctx context.Context, data []domain.News) ([]domain.News, error) {
+// fillTopicDetails sets the topics of the news, only with their IDs unless
+// expand is set
+func (s *Service) fillTopicDetails(ctx context.Context, data []domain.News, expand bool) ([]domain.News, error) {
 	g, ctx := errgroup.WithContext(ctx)
 	mapNewsTopics := map[int64][]domain.Topic{}
 
@@ -131,7 +166,7 @@ func (s *Service) fillTopicDetails(ctx context.Context, data []domain.News) ([]d
 		newsID := newsData.ID
 		g.Go(func() error {
 			res, err := s.newsTopicRepo.GetByNewsID(ctx, newsID)
-			if err != nil {
+			if err != nil && !errors.Is(err, domain.ErrNotFound) { // news without topics
 				return err
 			}
 			chanNewsTopic <- res
@@ -155,17 +190,19 @@ func (s *Service) fillTopicDetails(ctx context.Context, data []domain.News) ([]d
 	}
 
 	chanTopic := make(chan domain.Topic)
-	for _, topics := range mapNewsTopics {
-		for _, topic := range topics {
-			topicID := topic.ID
-			g.Go(func() error {
-				res, err := s.topicRepo.GetByID(context.Background(), topicID)
-				if err != nil {
-					return err
-				}
-				chanTopic <- res
-				return nil
-			})
+	if expand {
+		for _, topics := range mapNewsTopics {
+			for _, topic := range topics {
+				topicID := topic.ID
+				g.Go(func() error {
+					res, err := s.topicRepo.GetByID(ctx, topicID)
+					if err != nil {
+						return err
+					}
+					chanTopic <- res
+					return nil
+				})
+			}
 		}
 	}
 
@@ -204,24 +241,85 @@ func (s *Service) fillTopicDetails(ctx context.Context, data []domain.News) ([]d
 	return data, nil
 }
 
+// Fetch returns the news matching filter with the fields it selects, the
+// author and topic names are only looked up for the relations it expands
 func (s *Service) Fetch(ctx context.Context, filter domain.NewsFilter) (res []domain.News, totalPage int64, err error) {
 	res, totalPage, err = s.newsRepo.Fetch(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	if res, err = s.fillAuthorDetails(ctx, res); err != nil {
-		totalPage = 0
-		return
+	if filter.Expands("author") {
+		if res, err = s.fillAuthorDetails(ctx, res); err != nil {
+			totalPage = 0
+			return
+		}
 	}
 
-	if res, err = s.fillTopicDetails(ctx, res); err != nil {
-		totalPage = 0
-		return
+	if filter.Selects("topics") {
+		if res, err = s.fillTopicDetails(ctx, res, filter.Expands("topics")); err != nil {
+			totalPage = 0
+			return
+		}
 	}
 	return
 }
 
+// Stream calls fn for every news matching filter, with their author and
+// topics, without loading them all in memory
+func (s *Service) Stream(ctx context.Context, filter domain.NewsFilter, fn func(domain.News) error) error {
+	return s.newsRepo.Stream(ctx, filter, fn)
+}
+
+// Count returns the number of news matching filter
+func (s *Service) Count(ctx context.Context, filter domain.NewsFilter) (int64, error) {
+	return s.newsRepo.Count(ctx, filter)
+}
+
+// LatestByAuthors returns up to limit of the latest news of every given
+// author, with their author name and topics
+func (s *Service) LatestByAuthors(ctx context.Context, authorIDs []int64, limit int64) ([]domain.News, error) {
+	return s.newsRepo.FetchLatestByAuthors(ctx, authorIDs, limit)
+}
+
+// AuthorsByIDs returns the authors with the given IDs, unknown IDs are ignored
+func (s *Service) AuthorsByIDs(ctx context.Context, ids []int64) ([]domain.Author, error) {
+	return s.authorRepo.GetByIDs(ctx, ids)
+}
+
+// AuthorStats returns the stats of the authors of the news matching filter,
+// the authors with the most published news first. The stats of the author of
+// filter are returned even when they have no news, ErrNotFound when the
+// author does not exist.
+func (s *Service) AuthorStats(ctx context.Context, filter domain.AuthorStatsFilter) ([]domain.AuthorStats, error) {
+	res, err := s.newsRepo.AuthorStats(ctx, filter)
+	if err != nil || filter.AuthorID == 0 || len(res) > 0 {
+		return res, err
+	}
+
+	author, err := s.authorRepo.GetByID(ctx, filter.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	return []domain.AuthorStats{{
+		AuthorID:  author.ID,
+		Name:      author.Name,
+		TopTopics: []domain.AuthorTopic{},
+		Periods:   []domain.AuthorPeriod{},
+	}}, nil
+}
+
+// Archive counts the news matching filter per month of creation
+func (s *Service) Archive(ctx context.Context, filter domain.NewsFilter) ([]domain.NewsArchive, error) {
+	return s.newsRepo.Archive(ctx, filter)
+}
+
+// Facets counts the news matching filter per value of the facets, each
+// facet ignoring its own condition of filter
+func (s *Service) Facets(ctx context.Context, filter domain.NewsFilter, facets []string) ([]domain.Facet, error) {
+	return s.newsRepo.Facets(ctx, filter, facets)
+}
+
 func (s *Service) GetByID(ctx context.Context, id int64) (res domain.News, err error) {
 	res, err = s.newsRepo.GetByID(ctx, id)
 	if err != nil {
@@ -241,24 +339,41 @@ func (s *Service) GetByID(ctx context.Context, id int64) (res domain.News, err e
 }
 
 func (s *Service) Update(ctx context.Context, unr *news.UpdateNewsReq) (err error) {
-	if unr.TopicIDs != nil {
-		// Remove previous news topics
-		_ = s.newsTopicRepo.DeleteByNewsID(ctx, *unr.ID)
-
-		// Create new news topics
-		for _, topicId := range *unr.TopicIDs {
-			err = s.newsTopicRepo.Store(ctx, &domain.NewsTopic{
-				NewsID:  *unr.ID,
-				TopicID: topicId,
-			})
-			if err != nil {
-				return fmt.Errorf("failed to store new news topic: %w", err)
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.snapshot(ctx, *unr.ID)
+		if err != nil {
+			return err
+		}
+
+		if unr.TopicIDs != nil {
+			// Remove previous news topics
+			if err = s.newsTopicRepo.DeleteByNewsID(ctx, *unr.ID); err != nil {
+				return fmt.Errorf("failed to remove news topics: %w", err)
+			}
+
+			// Create new news topics
+			for _, topicId := range *unr.TopicIDs {
+				err = s.newsTopicRepo.Store(ctx, &domain.NewsTopic{
+					NewsID:  *unr.ID,
+					TopicID: topicId,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to store new news topic: %w", err)
+				}
 			}
 		}
-	}
 
-	// Step 3: Update the news article itself
-	return s.newsRepo.Update(ctx, unr)
+		// Step 3: Update the news article itself
+		if err = s.newsRepo.Update(ctx, unr); err != nil {
+			return err
+		}
+
+		after, err := s.snapshot(ctx, *unr.ID)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, domain.AuditUpdate, *unr.ID, before, after)
+	})
 }
 
 func (s *Service) GetByTitle(ctx context.Context, title string) (res domain.News, err error) {
@@ -285,31 +400,67 @@ func (s *Service) Store(ctx context.Context, cnr *news.CreateNewsReq) (err error
 		return domain.ErrConflict
 	}
 
-	err = s.newsRepo.Store(ctx, cnr)
-	if err != nil {
-		return
-	}
-	for _, topicId := range cnr.TopicIDs {
-		err = s.newsTopicRepo.Store(ctx, &domain.NewsTopic{
-			NewsID:  cnr.ID,
-			TopicID: topicId,
-		})
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.newsRepo.Store(ctx, cnr); err != nil {
+			return err
+		}
+		for _, topicId := range cnr.TopicIDs {
+			err := s.newsTopicRepo.Store(ctx, &domain.NewsTopic{
+				NewsID:  cnr.ID,
+				TopicID: topicId,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		after, err := s.snapshot(ctx, cnr.ID)
 		if err != nil {
-			return
+			return err
+		}
+		return s.audit(ctx, domain.AuditCreate, cnr.ID, nil, after)
+	})
+}
+
+// Delete removes the news, only at the given version unless it is 0
+func (s *Service) Delete(ctx context.Context, id int64, version int64) (err error) {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.snapshot(ctx, id)
+		if err != nil {
+			return err
 		}
 
-	}
-	return
+		if before.ID == 0 {
+			return domain.ErrNotFound
+		}
+		if err = s.newsRepo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, domain.AuditDelete, id, before, nil)
+	})
 }
 
-func (s *Service) Delete(ctx context.Context, id int64) (err error) {
-	existedNews, err := s.newsRepo.GetByID(ctx, id)
+// snapshot loads the news with its topic IDs as recorded in the audit log
+func (s *Service) snapshot(ctx context.Context, id int64) (domain.News, error) {
+	res, err := s.newsRepo.GetByID(ctx, id)
 	if err != nil {
-		return
+		return domain.News{}, err
+	}
+
+	newsTopics, err := s.newsTopicRepo.GetByNewsID(ctx, id)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.News{}, err
+	}
+	for _, nt := range newsTopics {
+		res.Topics = append(res.Topics, domain.TopicNews{ID: nt.TopicID})
 	}
+	return res, nil
+}
 
-	if existedNews.ID == 0 {
-		return domain.ErrNotFound
+func (s *Service) audit(ctx context.Context, action domain.AuditAction, id int64, before, after interface{}) error {
+	entry, err := domain.NewAuditLog(ctx, action, domain.AuditEntityNews, id, before, after)
+	if err != nil {
+		return err
 	}
-	return s.newsRepo.Delete(ctx, id)
+	return s.auditRepo.Store(ctx, entry)
 }
@@ -0,0 +1,43 @@
+package news_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/news"
+)
+
+// statsNewsRepo has no news to compute stats on
+type statsNewsRepo struct {
+	memoryNewsRepo
+}
+
+func (statsNewsRepo) AuthorStats(_ context.Context, _ domain.AuthorStatsFilter) ([]domain.AuthorStats, error) {
+	return []domain.AuthorStats{}, nil
+}
+
+func TestAuthorStatsWithoutNews(t *testing.T) {
+	svc := news.NewService(&statsNewsRepo{}, &fakeAuthorRepo{}, &fakeTopicRepo{}, &memoryNewsTopicRepo{}, &fakeAuditRepo{}, fakeTransactor{})
+	ctx := context.Background()
+
+	res, err := svc.AuthorStats(ctx, domain.AuthorStatsFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, res)
+
+	// an author without news has empty stats
+	res, err = svc.AuthorStats(ctx, domain.AuthorStatsFilter{AuthorID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.AuthorStats{{
+		AuthorID:  1,
+		Name:      "Deni",
+		TopTopics: []domain.AuthorTopic{},
+		Periods:   []domain.AuthorPeriod{},
+	}}, res)
+
+	_, err = svc.AuthorStats(ctx, domain.AuthorStatsFilter{AuthorID: 2})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
@@ -0,0 +1,30 @@
+package client
+
+import "net/http"
+
+// Authenticator adds credentials to the requests of a Client
+type Authenticator interface {
+	Authenticate(r *http.Request)
+}
+
+// AuthenticatorFunc is an Authenticator calling itself
+type AuthenticatorFunc func(r *http.Request)
+
+// Authenticate calls f
+func (f AuthenticatorFunc) Authenticate(r *http.Request) {
+	f(r)
+}
+
+// BearerToken sends token in the Authorization header
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+	})
+}
+
+// APIKey sends key in the X-API-Key header
+func APIKey(key string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) {
+		r.Header.Set("X-API-Key", key)
+	})
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// GetAuthor returns an author
+func (c *Client) GetAuthor(ctx context.Context, id int64) (domain.Author, error) {
+	var a domain.Author
+	_, err := c.doJSON(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/author/%d", id)}, &a)
+	return a, err
+}
+
+// ListAuthors returns the authors with the given IDs, unknown IDs are skipped
+func (c *Client) ListAuthors(ctx context.Context, ids ...int64) ([]domain.Author, error) {
+	list := make([]string, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, strconv.FormatInt(id, 10))
+	}
+	var res []domain.Author
+	_, err := c.doJSON(ctx, request{
+		method: http.MethodGet,
+		path:   "/author",
+		query:  url.Values{"ids": {strings.Join(list, ",")}},
+	}, &res)
+	return res, err
+}
+
+// ListAuthorNews returns the latest news of an author, limit defaults to 10
+// when not positive
+func (c *Client) ListAuthorNews(ctx context.Context, id int64, limit int64) ([]domain.News, error) {
+	query := url.Values{}
+	setInt(query, "limit", max(limit, 0))
+	var res []domain.News
+	_, err := c.doJSON(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/author/%d/news", id), query: query}, &res)
+	return res, err
+}
+
+// AuthorStats returns the stats of an author, filter.AuthorID is ignored
+func (c *Client) AuthorStats(ctx context.Context, id int64, filter domain.AuthorStatsFilter) (domain.AuthorStats, error) {
+	var stats domain.AuthorStats
+	_, err := c.doJSON(ctx, request{
+		method: http.MethodGet,
+		path:   fmt.Sprintf("/author/%d/stats", id),
+		query:  authorStatsQuery(filter),
+	}, &stats)
+	return stats, err
+}
+
+// ListAuthorStats returns the stats of every author with news matching
+// filter, the most published first
+func (c *Client) ListAuthorStats(ctx context.Context, filter domain.AuthorStatsFilter) ([]domain.AuthorStats, error) {
+	var res []domain.AuthorStats
+	_, err := c.doJSON(ctx, request{method: http.MethodGet, path: "/stats/authors", query: authorStatsQuery(filter)}, &res)
+	return res, err
+}
+
+// authorStatsQuery encodes the query parameters read by parseAuthorStatsFilter
+func authorStatsQuery(filter domain.AuthorStatsFilter) url.Values {
+	query := url.Values{}
+	setString(query, "interval", filter.Interval)
+	if !filter.StartDate.IsZero() {
+		query.Set("start_date", filter.StartDate.Format(time.RFC3339))
+	}
+	if !filter.EndDate.IsZero() {
+		query.Set("end_date", filter.EndDate.Format(time.RFC3339))
+	}
+	return query
+}
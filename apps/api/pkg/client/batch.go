@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// Modes of BatchNews
+const (
+	// BatchAtomic applies every operation or none of them
+	BatchAtomic = "atomic"
+	// BatchBestEffort applies the operations which succeed
+	BatchBestEffort = "best_effort"
+)
+
+// BatchOperation is one operation of BatchNews, built with CreateOperation,
+// UpdateOperation, StatusOperation or DeleteOperation
+type BatchOperation struct {
+	Op      string            `json:"op"`
+	ID      int64             `json:"id,omitempty"`
+	Version int64             `json:"version,omitempty"`
+	Status  domain.NewsStatus `json:"status,omitempty"`
+	Data    interface{}       `json:"data,omitempty"`
+}
+
+// CreateOperation creates a news
+func CreateOperation(req CreateNewsRequest) BatchOperation {
+	return BatchOperation{Op: "create", Data: req}
+}
+
+// UpdateOperation changes the fields set in req, at req.Version unless it
+// is 0
+func UpdateOperation(id int64, req UpdateNewsRequest) BatchOperation {
+	return BatchOperation{Op: "update", ID: id, Version: req.Version, Data: req}
+}
+
+// StatusOperation changes the status of a news, at version unless it is 0
+func StatusOperation(id int64, status domain.NewsStatus, version int64) BatchOperation {
+	return BatchOperation{Op: "status", ID: id, Version: version, Status: status}
+}
+
+// DeleteOperation deletes a news, at version unless it is 0
+func DeleteOperation(id int64, version int64) BatchOperation {
+	return BatchOperation{Op: "delete", ID: id, Version: version}
+}
+
+// BatchResult is the outcome of one operation of a batch, ID is the news
+// created or changed
+type BatchResult struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	ID      int64  `json:"id"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// Err returns the *Error of a failed operation, nil when it succeeded
+func (r BatchResult) Err() error {
+	if r.Status < http.StatusBadRequest {
+		return nil
+	}
+	return &Error{StatusCode: r.Status, Message: r.Message}
+}
+
+// BatchResponse reports the outcome of every operation of a batch
+type BatchResponse struct {
+	Mode      string        `json:"mode"`
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchNews applies the operations in order, in one transaction in
+// BatchAtomic mode. Failed operations are reported in the response, not as
+// an error.
+func (c *Client) BatchNews(ctx context.Context, mode string, operations ...BatchOperation) (BatchResponse, error) {
+	var res BatchResponse
+	body := struct {
+		Mode       string           `json:"mode"`
+		Operations []BatchOperation `json:"operations"`
+	}{mode, operations}
+	_, err := c.doJSON(ctx, request{method: http.MethodPost, path: "/news/batch", body: body}, &res)
+	return res, err
+}
@@ -0,0 +1,290 @@
+// Package client is a Go client of the news and topic management REST API.
+//
+//	c, err := client.New("http://localhost:9090", client.WithAuth(client.APIKey(key)))
+//	if err != nil {
+//		return err
+//	}
+//	for n, err := range c.AllNews(ctx, domain.NewsFilter{Status: "published"}) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Println(n.Title)
+//	}
+//
+// Failed calls return an *Error, which matches the domain errors of the
+// server with errors.Is, e.g. errors.Is(err, client.ErrNotFound).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// Client calls the REST API, it is safe for concurrent use
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	auth       Authenticator
+	retry      RetryPolicy
+	headers    http.Header
+}
+
+// RetryPolicy tells how calls answered with 429 Too Many Requests, or with a
+// 5xx status or a network error for idempotent methods, are retried. POST
+// calls carry an Idempotency-Key, which makes them idempotent too.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt, 1 disables retries
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry, doubled for each of the
+	// following ones up to MaxBackoff. A Retry-After header takes precedence.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetry is given
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, MinBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sends the requests with hc instead of http.DefaultClient
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithAuth authenticates every request with a
+func WithAuth(a Authenticator) Option {
+	return func(c *Client) {
+		c.auth = a
+	}
+}
+
+// WithRetry replaces DefaultRetryPolicy
+func WithRetry(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = p
+	}
+}
+
+// WithHeader adds a header to every request, e.g. X-User-ID to name the
+// actor recorded in the audit log
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.headers.Add(key, value)
+	}
+}
+
+// New will create a Client of the API served at baseURL
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		retry:      DefaultRetryPolicy,
+		headers:    http.Header{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.retry.MaxAttempts < 1 {
+		c.retry.MaxAttempts = 1
+	}
+	return c, nil
+}
+
+// request describes a call, body is encoded as JSON unless rawBody is set,
+// with contentType defaulting to application/json. Calls with a rawBody are
+// not retried since it can only be read once.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        interface{}
+	rawBody     io.Reader
+	contentType string
+	accept      string
+	// ifMatch is the version a change applies to, 0 for any
+	ifMatch int64
+	// idempotencyKey is sent with every attempt of a POST, so that the
+	// server applies it once
+	idempotencyKey string
+}
+
+// do sends req, retrying it according to the retry policy, and returns the
+// response of a successful call; the caller closes its body
+func (c *Client) do(ctx context.Context, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+		if req.contentType == "" {
+			req.contentType = "application/json"
+		}
+		if req.method == http.MethodPost {
+			req.idempotencyKey = domain.NewRequestID()
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		httpReq, err := c.newRequest(ctx, req, body)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		var retryAfter time.Duration
+		retryable := false
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			retryable = idempotent(req.method) || req.idempotencyKey != ""
+		} else {
+			err = decodeError(resp)
+			retryable = resp.StatusCode == http.StatusTooManyRequests ||
+				(resp.StatusCode >= http.StatusInternalServerError && (idempotent(req.method) || req.idempotencyKey != ""))
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		}
+		if !retryable || attempt >= c.retry.MaxAttempts || req.rawBody != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(c.retry.backoff(attempt, retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// doJSON sends req and decodes the body of the response into out, unless
+// out is nil
+func (c *Client) doJSON(ctx context.Context, req request, out interface{}) (*http.Response, error) {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("failed to decode the response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+func (c *Client) newRequest(ctx context.Context, req request, body []byte) (*http.Request, error) {
+	u := *c.baseURL
+	u.Path += req.path
+	u.RawQuery = req.query.Encode()
+
+	var bodyReader io.Reader
+	switch {
+	case req.rawBody != nil:
+		bodyReader = req.rawBody
+	case body != nil:
+		bodyReader = bytes.NewReader(body)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, u.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.headers {
+		httpReq.Header[key] = append([]string(nil), values...)
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if req.accept == "" {
+		req.accept = "application/json"
+	}
+	httpReq.Header.Set("Accept", req.accept)
+	if req.idempotencyKey != "" {
+		httpReq.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
+	if req.ifMatch != 0 {
+		httpReq.Header.Set("If-Match", `"`+strconv.FormatInt(req.ifMatch, 10)+`"`)
+	}
+	if c.auth != nil {
+		c.auth.Authenticate(httpReq)
+	}
+	return httpReq, nil
+}
+
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	wait := p.MinBackoff << (attempt - 1)
+	if p.MaxBackoff > 0 && (wait > p.MaxBackoff || wait <= 0) {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0
+	}
+	// up to 20% of jitter spreads the retries of concurrent callers
+	return wait - time.Duration(rand.Int63n(int64(wait)/5+1))
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// idFromLocation reads the ID at the end of a Location header
+func idFromLocation(resp *http.Response) int64 {
+	location := resp.Header.Get("Location")
+	id, err := strconv.ParseInt(location[strings.LastIndex(location, "/")+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,430 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+	"github.com/bxcodec/go-clean-arch/internal/dto/news"
+	"github.com/bxcodec/go-clean-arch/internal/rest"
+	"github.com/bxcodec/go-clean-arch/internal/rest/middleware"
+	"github.com/bxcodec/go-clean-arch/pkg/client"
+)
+
+// memoryNews is an in-memory news service behind the real handlers
+type memoryNews struct {
+	rest.NewsService
+	mu     sync.Mutex
+	list   []domain.News
+	actors []string
+	// statsFilter is the filter of the last AuthorStats call
+	statsFilter domain.AuthorStatsFilter
+}
+
+func (m *memoryNews) Fetch(_ context.Context, filter domain.NewsFilter) ([]domain.News, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var matching []domain.News
+	for _, n := range m.list {
+		if (filter.ID == 0 || n.ID == filter.ID) && (filter.Status == "" || string(n.Status) == filter.Status) &&
+			(len(filter.IDs) == 0 || slices.Contains(filter.IDs, n.ID)) {
+			matching = append(matching, n)
+		}
+	}
+	start := min((filter.Page-1)*filter.Limit, int64(len(matching)))
+	end := min(start+filter.Limit, int64(len(matching)))
+	return matching[start:end], int64(len(matching)), nil
+}
+
+func (m *memoryNews) Store(ctx context.Context, req *news.CreateNewsReq) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, n := range m.list {
+		if n.Title == req.Title {
+			return domain.ErrConflict
+		}
+	}
+	req.ID = int64(len(m.list) + 1)
+	m.actors = append(m.actors, domain.RequestInfoFromContext(ctx).Actor)
+	m.list = append(m.list, domain.News{ID: req.ID, Title: req.Title, Content: req.Content, Status: req.Status,
+		Author: domain.AuthorNews{ID: req.AuthorID}})
+	return nil
+}
+
+func (m *memoryNews) Update(_ context.Context, req *news.UpdateNewsReq) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, n := range m.list {
+		if n.ID == *req.ID {
+			if req.Title != nil {
+				m.list[i].Title = *req.Title
+			}
+			if req.Status != nil {
+				m.list[i].Status = *req.Status
+			}
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *memoryNews) Delete(_ context.Context, id int64, _ int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, n := range m.list {
+		if n.ID == id {
+			m.list = append(m.list[:i], m.list[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// Batch applies the operations one by one, whatever the mode
+func (m *memoryNews) Batch(ctx context.Context, operations []news.BatchOperation, _ news.BatchMode) ([]news.BatchResult, error) {
+	results := make([]news.BatchResult, len(operations))
+	for i, op := range operations {
+		results[i].ID = op.ID
+		switch op.Op {
+		case news.BatchCreate:
+			results[i].Err = m.Store(ctx, op.Create)
+			results[i].ID = op.Create.ID
+		case news.BatchDelete:
+			results[i].Err = m.Delete(ctx, op.ID, op.Version)
+		default:
+			op.Update.ID = &op.ID
+			results[i].Err = m.Update(ctx, op.Update)
+		}
+	}
+	return results, nil
+}
+
+func (m *memoryNews) Facets(_ context.Context, filter domain.NewsFilter, facets []string) ([]domain.Facet, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := map[domain.NewsStatus]int64{}
+	for _, n := range m.list {
+		counts[n.Status]++
+	}
+	res := []domain.Facet{{Name: facets[0]}}
+	for _, status := range []domain.NewsStatus{domain.Published, domain.Draft} {
+		if counts[status] > 0 {
+			res[0].Values = append(res[0].Values, domain.FacetValue{Value: string(status), Count: counts[status]})
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryNews) AuthorsByIDs(_ context.Context, ids []int64) ([]domain.Author, error) {
+	var res []domain.Author
+	for _, id := range ids {
+		if id == 1 {
+			res = append(res, domain.Author{ID: 1, Name: "Deni"})
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryNews) LatestByAuthors(_ context.Context, ids []int64, limit int64) ([]domain.News, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []domain.News
+	for _, n := range m.list {
+		if n.Author.ID == ids[0] && int64(len(res)) < limit {
+			res = append(res, n)
+		}
+	}
+	return res, nil
+}
+
+func (m *memoryNews) AuthorStats(_ context.Context, filter domain.AuthorStatsFilter) ([]domain.AuthorStats, error) {
+	m.statsFilter = filter
+	stats := []domain.AuthorStats{{AuthorID: 1, Name: "Deni", Published: 2}, {AuthorID: 2, Name: "Doni"}}
+	if filter.AuthorID != 0 {
+		return stats[filter.AuthorID-1 : filter.AuthorID], nil
+	}
+	return stats, nil
+}
+
+type memoryTopics struct {
+	rest.TopicService
+}
+
+func (memoryTopics) Fetch(_ context.Context, filter domain.TopicFilter) ([]domain.Topic, int64, error) {
+	if filter.ID != 0 && filter.ID != 1 {
+		return nil, 0, nil
+	}
+	return []domain.Topic{{ID: 1, Name: "Health"}}, 1, nil
+}
+
+func (memoryTopics) Stats(_ context.Context, id int64, weeks int64) (domain.TopicActivity, error) {
+	if id != 1 {
+		return domain.TopicActivity{}, domain.ErrNotFound
+	}
+	return domain.TopicActivity{TopicID: 1, TopicStats: domain.TopicStats{Published: 2}, Weeks: make([]domain.TopicWeek, weeks)}, nil
+}
+
+func (memoryTopics) Store(_ context.Context, t *domain.Topic) error {
+	t.ID = 7
+	return nil
+}
+
+// newServer serves the real handlers, failures lets tests answer the first
+// requests with an error status
+func newServer(t *testing.T, svc *memoryNews, failures func(r *http.Request) int) *httptest.Server {
+	mux := http.NewServeMux()
+	rest.NewNewsHandler(mux, svc, rest.ConcurrencyConfig{})
+	rest.NewTopicHandler(mux, memoryTopics{}, rest.ConcurrencyConfig{})
+	rest.NewAuthorHandler(mux, svc)
+
+	handler := middleware.RequestInfo(middleware.Idempotency(middleware.IdempotencyConfig{})(mux))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures != nil {
+			if status := failures(r); status != 0 {
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, `{"message":"try again"}`, status)
+				return
+			}
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var fastRetry = client.WithRetry(client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond})
+
+func TestNewsCRUD(t *testing.T) {
+	svc := &memoryNews{}
+	server := newServer(t, svc, nil)
+	c, err := client.New(server.URL, client.WithHeader("X-User-ID", "42"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	id, err := c.CreateNews(ctx, client.CreateNewsRequest{
+		Title: "Covid 19 is gone", Content: "Body", AuthorID: 1, Status: domain.Draft, TopicIDs: []int64{1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.Equal(t, []string{"user:42"}, svc.actors)
+
+	_, err = c.CreateNews(ctx, client.CreateNewsRequest{
+		Title: "Covid 19 is gone", Content: "Body", AuthorID: 1, Status: domain.Draft, TopicIDs: []int64{},
+	})
+	assert.ErrorIs(t, err, client.ErrConflict)
+
+	_, err = c.CreateNews(ctx, client.CreateNewsRequest{Title: "No content"})
+	assert.ErrorIs(t, err, client.ErrBadParamInput)
+
+	title := "Updated"
+	status := domain.Published
+	require.NoError(t, c.UpdateNews(ctx, id, client.UpdateNewsRequest{Title: &title, Status: &status}))
+
+	n, err := c.GetNews(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", n.Title)
+	assert.Equal(t, domain.Published, n.Status)
+
+	require.NoError(t, c.PatchNews(ctx, id, []client.PatchOperation{{Op: "replace", Path: "/title", Value: "Patched"}}, 0))
+	n, err = c.GetNews(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Patched", n.Title)
+
+	page, err := c.ListNews(ctx, domain.NewsFilter{Status: "published"})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, client.Pagination{CurrentPage: 1, TotalPages: 1, TotalData: 1, Links: client.PageLinks{
+		Self:  server.URL + "/news?status=published",
+		First: server.URL + "/news?page=1&status=published",
+		Last:  server.URL + "/news?page=1&status=published",
+	}}, page.Meta)
+	assert.Equal(t, fmt.Sprintf("%s/news/%d", server.URL, id), page.Items[0].Links.Self)
+
+	require.NoError(t, c.DeleteNews(ctx, id, 0))
+	_, err = c.GetNews(ctx, id)
+	assert.ErrorIs(t, err, client.ErrNotFound)
+
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.NotEmpty(t, apiErr.RequestID)
+}
+
+func TestAllNewsPaginates(t *testing.T) {
+	svc := &memoryNews{}
+	for i := 1; i <= 25; i++ {
+		svc.list = append(svc.list, domain.News{ID: int64(i), Title: fmt.Sprintf("News %d", i), Status: domain.Published})
+	}
+	var pages int
+	c, err := client.New(newServer(t, svc, func(r *http.Request) int {
+		pages++
+		return 0
+	}).URL)
+	require.NoError(t, err)
+
+	var ids []int64
+	for n, err := range c.AllNews(context.Background(), domain.NewsFilter{Limit: 10}) {
+		require.NoError(t, err)
+		ids = append(ids, n.ID)
+	}
+	assert.Len(t, ids, 25)
+	assert.Equal(t, int64(25), ids[24])
+	assert.Equal(t, 3, pages)
+
+	// stopping early does not fetch the following pages
+	pages = 0
+	for range c.AllNews(context.Background(), domain.NewsFilter{Limit: 10}) {
+		break
+	}
+	assert.Equal(t, 1, pages)
+}
+
+func TestBatchNews(t *testing.T) {
+	svc := &memoryNews{list: []domain.News{
+		{ID: 1, Title: "First", Status: domain.Published},
+		{ID: 2, Title: "Second", Status: domain.Published},
+	}}
+	c, err := client.New(newServer(t, svc, nil).URL)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	res, err := c.BatchNews(ctx, client.BatchBestEffort,
+		client.CreateOperation(client.CreateNewsRequest{Title: "Third", Content: "Body", AuthorID: 1, Status: domain.Draft, TopicIDs: []int64{}}),
+		client.StatusOperation(1, domain.Draft, 0),
+		client.DeleteOperation(7, 0),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Succeeded)
+	assert.Equal(t, int64(3), res.Results[0].ID)
+	assert.NoError(t, res.Results[1].Err())
+	assert.ErrorIs(t, res.Results[2].Err(), client.ErrNotFound)
+
+	list, err := c.GetNewsByIDs(ctx, 1, 3, 9)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, domain.Draft, list[0].Status)
+	assert.Equal(t, "Third", list[1].Title)
+
+	facets, err := c.NewsFacets(ctx, domain.NewsFilter{Status: "draft"}, "status")
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Facet{{Name: "status", Values: []domain.FacetValue{
+		{Value: "published", Count: 1},
+		{Value: "draft", Count: 2},
+	}}}, facets)
+}
+
+func TestRetries(t *testing.T) {
+	svc := &memoryNews{list: []domain.News{{ID: 1, Title: "First"}}}
+	attempts := map[string]int{}
+	var keys []string
+	server := newServer(t, svc, func(r *http.Request) int {
+		attempts[r.Method]++
+		switch {
+		case r.Method == http.MethodGet && attempts[r.Method] <= 2:
+			return http.StatusServiceUnavailable
+		case r.Method == http.MethodPost:
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			if attempts[r.Method] == 1 {
+				return http.StatusTooManyRequests
+			}
+		case r.Method == http.MethodDelete:
+			return http.StatusInternalServerError
+		}
+		return 0
+	})
+	c, err := client.New(server.URL, fastRetry)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	n, err := c.GetNews(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "First", n.Title)
+	assert.Equal(t, 3, attempts[http.MethodGet])
+
+	// 429 means the request was not processed, even POST is retried
+	_, err = c.CreateNews(ctx, client.CreateNewsRequest{Title: "Second", Content: "Body", AuthorID: 1, Status: domain.Draft, TopicIDs: []int64{}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts[http.MethodPost])
+	require.Len(t, keys, 2)
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+
+	err = c.DeleteNews(ctx, 1, 0)
+	assert.ErrorIs(t, err, client.ErrInternalServerError)
+	assert.Equal(t, 3, attempts[http.MethodDelete])
+}
+
+func TestAuthAndAuthors(t *testing.T) {
+	svc := &memoryNews{list: []domain.News{
+		{ID: 1, Title: "First", Author: domain.AuthorNews{ID: 1}},
+		{ID: 2, Title: "Second", Author: domain.AuthorNews{ID: 1}},
+	}}
+	var authorization, apiKey string
+	server := newServer(t, svc, func(r *http.Request) int {
+		authorization = r.Header.Get("Authorization")
+		apiKey = r.Header.Get("X-API-Key")
+		return 0
+	})
+	ctx := context.Background()
+
+	c, err := client.New(server.URL, client.WithAuth(client.BearerToken("secret")))
+	require.NoError(t, err)
+	author, err := c.GetAuthor(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Deni", author.Name)
+	assert.Equal(t, "Bearer secret", authorization)
+
+	c, err = client.New(server.URL, client.WithAuth(client.APIKey("key")))
+	require.NoError(t, err)
+	authors, err := c.ListAuthors(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Author{{ID: 1, Name: "Deni", Links: &domain.Links{Self: server.URL + "/author/1"}}}, authors)
+	assert.Equal(t, "key", apiKey)
+
+	list, err := c.ListAuthorNews(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	_, err = c.GetAuthor(ctx, 2)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+
+	start := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	authorStats, err := c.AuthorStats(ctx, 2, domain.AuthorStatsFilter{StartDate: start, Interval: "week"})
+	require.NoError(t, err)
+	assert.Equal(t, "Doni", authorStats.Name)
+	assert.Equal(t, domain.AuthorStatsFilter{AuthorID: 2, StartDate: start, Interval: "week"}, svc.statsFilter)
+	allStats, err := c.ListAuthorStats(ctx, domain.AuthorStatsFilter{})
+	require.NoError(t, err)
+	assert.Len(t, allStats, 2)
+	assert.Equal(t, "month", svc.statsFilter.Interval)
+
+	topicID, err := c.CreateTopic(ctx, "Space")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), topicID)
+	topic, err := c.GetTopic(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Health", topic.Name)
+
+	stats, err := c.TopicStats(ctx, 1, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Published)
+	assert.Len(t, stats.Weeks, 4)
+	_, err = c.TopicStats(ctx, 2, 0)
+	assert.True(t, errors.Is(err, client.ErrNotFound))
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// Errors matched by the *Error of failed calls, they are the domain errors
+// of the server
+var (
+	ErrNotFound            = domain.ErrNotFound
+	ErrConflict            = domain.ErrConflict
+	ErrBadParamInput       = domain.ErrBadParamInput
+	ErrTooManyRequests     = domain.ErrTooManyRequests
+	ErrPreconditionFailed  = domain.ErrPreconditionFailed
+	ErrBatchAborted        = domain.ErrBatchAborted
+	ErrInternalServerError = domain.ErrInternalServerError
+)
+
+var statusErrors = map[int]error{
+	http.StatusNotFound:             domain.ErrNotFound,
+	http.StatusConflict:             domain.ErrConflict,
+	http.StatusBadRequest:           domain.ErrBadParamInput,
+	http.StatusTooManyRequests:      domain.ErrTooManyRequests,
+	http.StatusMethodNotAllowed:     domain.ErrMethodNotAllowed,
+	http.StatusUnprocessableEntity:  domain.ErrUnprocessableEntity,
+	http.StatusNotAcceptable:        domain.ErrNotAcceptable,
+	http.StatusUnsupportedMediaType: domain.ErrUnsupportedMediaType,
+	http.StatusPreconditionFailed:   domain.ErrPreconditionFailed,
+	http.StatusPreconditionRequired: domain.ErrPreconditionRequired,
+	http.StatusFailedDependency:     domain.ErrBatchAborted,
+	http.StatusInternalServerError:  domain.ErrInternalServerError,
+}
+
+// Error is returned for the calls answered with an error status
+type Error struct {
+	StatusCode int
+	// Message is the message of the error body, or the status text
+	Message   string
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Is matches the domain error of the status code, so that
+// errors.Is(err, ErrNotFound) holds for a 404 response
+func (e *Error) Is(target error) bool {
+	err, ok := statusErrors[e.StatusCode]
+	return ok && err == target
+}
+
+// decodeError reads an error response and closes its body
+func decodeError(resp *http.Response) error {
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	apiErr := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		RequestID:  resp.Header.Get("X-Request-ID"),
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10)); err == nil {
+		if json.Unmarshal(data, &body) == nil && body.Message != "" {
+			apiErr.Message = body.Message
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+// Pagination is the metadata of a page of results
+type Pagination struct {
+	CurrentPage int64 `json:"current_page"`
+	TotalPages  int64 `json:"total_pages"`
+	TotalData   int64 `json:"total_data"`
+	// Facets holds the counts asked for by NewsFacets
+	Facets []domain.Facet `json:"facets"`
+	// Links holds the URLs of the pages of the listing
+	Links PageLinks `json:"links"`
+}
+
+// PageLinks are the URLs of the pages of a listing, Prev and Next are empty
+// on the first and last pages
+type PageLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Prev  string `json:"prev"`
+	Next  string `json:"next"`
+	Last  string `json:"last"`
+}
+
+// Page is a page of results
+type Page[T any] struct {
+	Items []T        `json:"data"`
+	Meta  Pagination `json:"meta"`
+}
+
+// CreateNewsRequest is the body of CreateNews and ReplaceNews
+type CreateNewsRequest struct {
+	Title    string            `json:"title"`
+	Content  string            `json:"content"`
+	AuthorID int64             `json:"author_id"`
+	Status   domain.NewsStatus `json:"status"`
+	TopicIDs []int64           `json:"topic_ids"`
+}
+
+// UpdateNewsRequest is the merge patch of UpdateNews, nil fields are left
+// unchanged
+type UpdateNewsRequest struct {
+	Title    *string            `json:"title,omitempty"`
+	Content  *string            `json:"content,omitempty"`
+	AuthorID *int64             `json:"author_id,omitempty"`
+	Status   *domain.NewsStatus `json:"status,omitempty"`
+	TopicIDs *[]int64           `json:"topic_ids,omitempty"`
+	// Version makes the update fail with ErrPreconditionFailed unless the
+	// news is still at this version, 0 updates any version
+	Version int64 `json:"-"`
+}
+
+// iteratePageSize is the page size of the iterators when the filter sets none
+const iteratePageSize = 100
+
+// ListNews returns one page of the news matching filter
+func (c *Client) ListNews(ctx context.Context, filter domain.NewsFilter) (*Page[domain.News], error) {
+	var page Page[domain.News]
+	if _, err := c.doJSON(ctx, request{method: http.MethodGet, path: "/news", query: newsQuery(filter)}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllNews iterates over every news matching filter, fetching the pages from
+// filter.Page on. Iteration stops after the first error.
+func (c *Client) AllNews(ctx context.Context, filter domain.NewsFilter) iter.Seq2[domain.News, error] {
+	return paginate(func(page int64) (*Page[domain.News], error) {
+		f := filter
+		f.Page = page
+		if f.Limit <= 0 {
+			f.Limit = iteratePageSize
+		}
+		return c.ListNews(ctx, f)
+	}, filter.Page)
+}
+
+// GetNews returns a news with its author and topics
+func (c *Client) GetNews(ctx context.Context, id int64) (domain.News, error) {
+	var n domain.News
+	_, err := c.doJSON(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/news/%d", id)}, &n)
+	return n, err
+}
+
+// NewsFacets counts the news matching filter per value of the facets,
+// status, topic, author or month. Each facet ignores its own condition of
+// filter, e.g. the status facet counts every status when filter.Status is set.
+func (c *Client) NewsFacets(ctx context.Context, filter domain.NewsFilter, facets ...string) ([]domain.Facet, error) {
+	query := newsQuery(filter)
+	query.Set("facets", strings.Join(facets, ","))
+	query.Set("fields", "id")
+	query.Set("limit", "1")
+
+	var page Page[domain.News]
+	if _, err := c.doJSON(ctx, request{method: http.MethodGet, path: "/news", query: query}, &page); err != nil {
+		return nil, err
+	}
+	return page.Meta.Facets, nil
+}
+
+// GetNewsByIDs returns the news with the given IDs, unknown IDs are skipped
+func (c *Client) GetNewsByIDs(ctx context.Context, ids ...int64) ([]domain.News, error) {
+	if len(ids) == 0 {
+		return []domain.News{}, nil
+	}
+	page, err := c.ListNews(ctx, domain.NewsFilter{IDs: ids, Limit: int64(len(ids))})
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// CreateNews stores a news and returns its ID
+func (c *Client) CreateNews(ctx context.Context, req CreateNewsRequest) (int64, error) {
+	resp, err := c.doJSON(ctx, request{method: http.MethodPost, path: "/news", body: req}, nil)
+	if err != nil {
+		return 0, err
+	}
+	return idFromLocation(resp), nil
+}
+
+// UpdateNews changes the fields set in req
+func (c *Client) UpdateNews(ctx context.Context, id int64, req UpdateNewsRequest) error {
+	_, err := c.doJSON(ctx, request{
+		method:      http.MethodPatch,
+		path:        fmt.Sprintf("/news/%d", id),
+		body:        req,
+		contentType: "application/merge-patch+json",
+		ifMatch:     req.Version,
+	}, nil)
+	return err
+}
+
+// ReplaceNews replaces every field of a news if it is still at version, any
+// version when it is 0
+func (c *Client) ReplaceNews(ctx context.Context, id int64, req CreateNewsRequest, version int64) error {
+	_, err := c.doJSON(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/news/%d", id), body: req, ifMatch: version}, nil)
+	return err
+}
+
+// PatchOperation is an operation of a JSON Patch (RFC 6902), e.g.
+// {Op: "add", Path: "/topic_ids/-", Value: 3}
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// PatchNews applies the JSON Patch operations to a news if it is still at
+// version, any version when it is 0. The paths are the JSON fields of
+// CreateNewsRequest.
+func (c *Client) PatchNews(ctx context.Context, id int64, operations []PatchOperation, version int64) error {
+	_, err := c.doJSON(ctx, request{
+		method:      http.MethodPatch,
+		path:        fmt.Sprintf("/news/%d", id),
+		body:        operations,
+		contentType: "application/json-patch+json",
+		ifMatch:     version,
+	}, nil)
+	return err
+}
+
+// DeleteNews deletes a news if it is still at version, any version when it
+// is 0
+func (c *Client) DeleteNews(ctx context.Context, id int64, version int64) error {
+	_, err := c.doJSON(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/news/%d", id), ifMatch: version}, nil)
+	return err
+}
+
+// ImportNews bulk imports the news read from r, format being csv or ndjson
+func (c *Client) ImportNews(ctx context.Context, r io.Reader, format string, opts domain.ImportOptions) (domain.ImportReport, error) {
+	query := url.Values{"format": {format}}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+	if opts.BatchSize > 0 {
+		query.Set("batch_size", strconv.Itoa(opts.BatchSize))
+	}
+	contentType := "text/csv"
+	if format == "ndjson" {
+		contentType = "application/x-ndjson"
+	}
+
+	var report domain.ImportReport
+	_, err := c.doJSON(ctx, request{
+		method:      http.MethodPost,
+		path:        "/news/import",
+		query:       query,
+		rawBody:     r,
+		contentType: contentType,
+	}, &report)
+	return report, err
+}
+
+// ExportNews streams every news matching filter as csv, ndjson or xlsx, the
+// caller closes the returned reader
+func (c *Client) ExportNews(ctx context.Context, filter domain.NewsFilter, format string) (io.ReadCloser, error) {
+	query := newsQuery(filter)
+	query.Set("format", format)
+	resp, err := c.do(ctx, request{method: http.MethodGet, path: "/news/export", query: query, accept: "*/*"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// newsQuery encodes the query parameters read by parseNewsFilter
+func newsQuery(filter domain.NewsFilter) url.Values {
+	query := url.Values{}
+	setString(query, "title", filter.Title)
+	setString(query, "status", filter.Status)
+	setString(query, "sort_by", filter.SortBy)
+	setString(query, "sort_order", filter.SortOrder)
+	setInt(query, "id", filter.ID)
+	if len(filter.IDs) > 0 {
+		ids := make([]string, len(filter.IDs))
+		for i, id := range filter.IDs {
+			ids[i] = strconv.FormatInt(id, 10)
+		}
+		query.Set("ids", strings.Join(ids, ","))
+	}
+	setInt(query, "author_id", filter.AuthorID)
+	setInt(query, "topic_id", filter.TopicID)
+	setInt(query, "limit", filter.Limit)
+	setInt(query, "page", filter.Page)
+	if filter.Fields != nil {
+		query.Set("fields", strings.Join(filter.Fields, ","))
+	}
+	if filter.Include != nil {
+		query.Set("include", strings.Join(filter.Include, ","))
+	}
+	if !filter.StartDate.IsZero() {
+		query.Set("start_date", filter.StartDate.Format(time.RFC3339))
+	}
+	if !filter.EndDate.IsZero() {
+		query.Set("end_date", filter.EndDate.Format(time.RFC3339))
+	}
+	return query
+}
+
+func setString(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
+
+func setInt(query url.Values, key string, value int64) {
+	if value != 0 {
+		query.Set(key, strconv.FormatInt(value, 10))
+	}
+}
+
+// paginate iterates over the items of the pages returned by fetch, from
+// page first (1 when unset) to the last one
+func paginate[T any](fetch func(page int64) (*Page[T], error), first int64) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		page := max(first, 1)
+		for {
+			res, err := fetch(page)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range res.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if len(res.Items) == 0 || page >= res.Meta.TotalPages {
+				return
+			}
+			page++
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+
+	"github.com/bxcodec/go-clean-arch/domain"
+)
+
+type topicRequest struct {
+	Name string `json:"name"`
+}
+
+// ListTopics returns one page of the topics matching filter
+func (c *Client) ListTopics(ctx context.Context, filter domain.TopicFilter) (*Page[domain.Topic], error) {
+	var page Page[domain.Topic]
+	if _, err := c.doJSON(ctx, request{method: http.MethodGet, path: "/topic", query: topicQuery(filter)}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// AllTopics iterates over every topic matching filter, fetching the pages
+// from filter.Page on. Iteration stops after the first error.
+func (c *Client) AllTopics(ctx context.Context, filter domain.TopicFilter) iter.Seq2[domain.Topic, error] {
+	return paginate(func(page int64) (*Page[domain.Topic], error) {
+		f := filter
+		f.Page = page
+		if f.Limit <= 0 {
+			f.Limit = iteratePageSize
+		}
+		return c.ListTopics(ctx, f)
+	}, filter.Page)
+}
+
+// GetTopic returns a topic
+func (c *Client) GetTopic(ctx context.Context, id int64) (domain.Topic, error) {
+	var t domain.Topic
+	_, err := c.doJSON(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/topic/%d", id)}, &t)
+	return t, err
+}
+
+// TopicStats returns the stats of a topic with the counts of its news per week
+// over the given number of weeks, the server default when it is 0
+func (c *Client) TopicStats(ctx context.Context, id int64, weeks int64) (domain.TopicActivity, error) {
+	query := url.Values{}
+	setInt(query, "weeks", weeks)
+	var stats domain.TopicActivity
+	_, err := c.doJSON(ctx, request{method: http.MethodGet, path: fmt.Sprintf("/topic/%d/stats", id), query: query}, &stats)
+	return stats, err
+}
+
+// CreateTopic stores a topic and returns its ID
+func (c *Client) CreateTopic(ctx context.Context, name string) (int64, error) {
+	resp, err := c.doJSON(ctx, request{method: http.MethodPost, path: "/topic", body: topicRequest{Name: name}}, nil)
+	if err != nil {
+		return 0, err
+	}
+	return idFromLocation(resp), nil
+}
+
+// UpdateTopic renames a topic if it is still at version, any version when it
+// is 0
+func (c *Client) UpdateTopic(ctx context.Context, id int64, name string, version int64) error {
+	_, err := c.doJSON(ctx, request{method: http.MethodPut, path: fmt.Sprintf("/topic/%d", id), body: topicRequest{Name: name}, ifMatch: version}, nil)
+	return err
+}
+
+// DeleteTopic deletes a topic if it is still at version, any version when it
+// is 0
+func (c *Client) DeleteTopic(ctx context.Context, id int64, version int64) error {
+	_, err := c.doJSON(ctx, request{method: http.MethodDelete, path: fmt.Sprintf("/topic/%d", id), ifMatch: version}, nil)
+	return err
+}
+
+// ExportTopics streams every topic matching filter as csv, ndjson or xlsx,
+// the caller closes the returned reader
+func (c *Client) ExportTopics(ctx context.Context, filter domain.TopicFilter, format string) (io.ReadCloser, error) {
+	query := topicQuery(filter)
+	query.Set("format", format)
+	resp, err := c.do(ctx, request{method: http.MethodGet, path: "/topic/export", query: query, accept: "*/*"})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// topicQuery encodes the query parameters read by parseTopicFilter
+func topicQuery(filter domain.TopicFilter) url.Values {
+	query := url.Values{}
+	setString(query, "name", filter.Name)
+	setString(query, "sort_by", filter.SortBy)
+	setString(query, "sort_order", filter.SortOrder)
+	setInt(query, "id", filter.ID)
+	setInt(query, "limit", filter.Limit)
+	setInt(query, "page", filter.Page)
+	if filter.WithStats {
+		query.Set("with_stats", "true")
+	}
+	return query
+}
@@ -0,0 +1,1684 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v5.28.3
+// source: news/v1/news.proto
+
+package newsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type NewsStatus int32
+
+const (
+	NewsStatus_NEWS_STATUS_UNSPECIFIED NewsStatus = 0
+	NewsStatus_NEWS_STATUS_DRAFT       NewsStatus = 1
+	NewsStatus_NEWS_STATUS_PUBLISHED   NewsStatus = 2
+	NewsStatus_NEWS_STATUS_DELETED     NewsStatus = 3
+)
+
+// Enum value maps for NewsStatus.
+var (
+	NewsStatus_name = map[int32]string{
+		0: "NEWS_STATUS_UNSPECIFIED",
+		1: "NEWS_STATUS_DRAFT",
+		2: "NEWS_STATUS_PUBLISHED",
+		3: "NEWS_STATUS_DELETED",
+	}
+	NewsStatus_value = map[string]int32{
+		"NEWS_STATUS_UNSPECIFIED": 0,
+		"NEWS_STATUS_DRAFT":       1,
+		"NEWS_STATUS_PUBLISHED":   2,
+		"NEWS_STATUS_DELETED":     3,
+	}
+)
+
+func (x NewsStatus) Enum() *NewsStatus {
+	p := new(NewsStatus)
+	*p = x
+	return p
+}
+
+func (x NewsStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NewsStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_news_v1_news_proto_enumTypes[0].Descriptor()
+}
+
+func (NewsStatus) Type() protoreflect.EnumType {
+	return &file_news_v1_news_proto_enumTypes[0]
+}
+
+func (x NewsStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NewsStatus.Descriptor instead.
+func (NewsStatus) EnumDescriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{0}
+}
+
+type Author struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Author) Reset() {
+	*x = Author{}
+	mi := &file_news_v1_news_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Author) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Author) ProtoMessage() {}
+
+func (x *Author) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Author.ProtoReflect.Descriptor instead.
+func (*Author) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Author) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Author) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Author) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Author) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+// TopicRef is the topic of a news, without its timestamps
+type TopicRef struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicRef) Reset() {
+	*x = TopicRef{}
+	mi := &file_news_v1_news_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicRef) ProtoMessage() {}
+
+func (x *TopicRef) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicRef.ProtoReflect.Descriptor instead.
+func (*TopicRef) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TopicRef) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TopicRef) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type News struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	Author        *Author                `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"` // only id and name are set
+	Status        NewsStatus             `protobuf:"varint,5,opt,name=status,proto3,enum=news.v1.NewsStatus" json:"status,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Topics        []*TopicRef            `protobuf:"bytes,8,rep,name=topics,proto3" json:"topics,omitempty"`
+	Version       int64                  `protobuf:"varint,9,opt,name=version,proto3" json:"version,omitempty"` // incremented by every update
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *News) Reset() {
+	*x = News{}
+	mi := &file_news_v1_news_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *News) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*News) ProtoMessage() {}
+
+func (x *News) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use News.ProtoReflect.Descriptor instead.
+func (*News) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *News) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *News) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *News) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *News) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *News) GetStatus() NewsStatus {
+	if x != nil {
+		return x.Status
+	}
+	return NewsStatus_NEWS_STATUS_UNSPECIFIED
+}
+
+func (x *News) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *News) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *News) GetTopics() []*TopicRef {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *News) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type Topic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Version       int64                  `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"` // incremented by every update
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Topic) Reset() {
+	*x = Topic{}
+	mi := &file_news_v1_news_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topic) ProtoMessage() {}
+
+func (x *Topic) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topic.ProtoReflect.Descriptor instead.
+func (*Topic) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Topic) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Topic) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Topic) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Topic) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Topic) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// NewsFilter mirrors the query parameters of GET /news, every news matching
+// the other fields is returned when limit is not set
+type NewsFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Status        NewsStatus             `protobuf:"varint,3,opt,name=status,proto3,enum=news.v1.NewsStatus" json:"status,omitempty"`
+	AuthorId      int64                  `protobuf:"varint,4,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	TopicId       int64                  `protobuf:"varint,5,opt,name=topic_id,json=topicId,proto3" json:"topic_id,omitempty"`
+	StartDate     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	Limit         int64                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`
+	Page          int64                  `protobuf:"varint,9,opt,name=page,proto3" json:"page,omitempty"`
+	SortBy        string                 `protobuf:"bytes,10,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortOrder     string                 `protobuf:"bytes,11,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewsFilter) Reset() {
+	*x = NewsFilter{}
+	mi := &file_news_v1_news_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewsFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewsFilter) ProtoMessage() {}
+
+func (x *NewsFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewsFilter.ProtoReflect.Descriptor instead.
+func (*NewsFilter) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NewsFilter) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *NewsFilter) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *NewsFilter) GetStatus() NewsStatus {
+	if x != nil {
+		return x.Status
+	}
+	return NewsStatus_NEWS_STATUS_UNSPECIFIED
+}
+
+func (x *NewsFilter) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *NewsFilter) GetTopicId() int64 {
+	if x != nil {
+		return x.TopicId
+	}
+	return 0
+}
+
+func (x *NewsFilter) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *NewsFilter) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *NewsFilter) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *NewsFilter) GetPage() int64 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *NewsFilter) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *NewsFilter) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+// TopicFilter mirrors the query parameters of GET /topic
+type TopicFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Limit         int64                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Page          int64                  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
+	SortBy        string                 `protobuf:"bytes,5,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"`
+	SortOrder     string                 `protobuf:"bytes,6,opt,name=sort_order,json=sortOrder,proto3" json:"sort_order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicFilter) Reset() {
+	*x = TopicFilter{}
+	mi := &file_news_v1_news_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicFilter) ProtoMessage() {}
+
+func (x *TopicFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicFilter.ProtoReflect.Descriptor instead.
+func (*TopicFilter) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TopicFilter) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TopicFilter) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TopicFilter) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *TopicFilter) GetPage() int64 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *TopicFilter) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *TopicFilter) GetSortOrder() string {
+	if x != nil {
+		return x.SortOrder
+	}
+	return ""
+}
+
+type ListNewsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *NewsFilter            `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNewsRequest) Reset() {
+	*x = ListNewsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNewsRequest) ProtoMessage() {}
+
+func (x *ListNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNewsRequest.ProtoReflect.Descriptor instead.
+func (*ListNewsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListNewsRequest) GetFilter() *NewsFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type CountNewsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *NewsFilter            `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountNewsRequest) Reset() {
+	*x = CountNewsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountNewsRequest) ProtoMessage() {}
+
+func (x *CountNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountNewsRequest.ProtoReflect.Descriptor instead.
+func (*CountNewsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CountNewsRequest) GetFilter() *NewsFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type CountNewsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CountNewsResponse) Reset() {
+	*x = CountNewsResponse{}
+	mi := &file_news_v1_news_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CountNewsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountNewsResponse) ProtoMessage() {}
+
+func (x *CountNewsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountNewsResponse.ProtoReflect.Descriptor instead.
+func (*CountNewsResponse) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CountNewsResponse) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetNewsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNewsRequest) Reset() {
+	*x = GetNewsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNewsRequest) ProtoMessage() {}
+
+func (x *GetNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNewsRequest.ProtoReflect.Descriptor instead.
+func (*GetNewsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetNewsRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateNewsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	AuthorId      int64                  `protobuf:"varint,3,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Status        NewsStatus             `protobuf:"varint,4,opt,name=status,proto3,enum=news.v1.NewsStatus" json:"status,omitempty"`
+	TopicIds      []int64                `protobuf:"varint,5,rep,packed,name=topic_ids,json=topicIds,proto3" json:"topic_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNewsRequest) Reset() {
+	*x = CreateNewsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNewsRequest) ProtoMessage() {}
+
+func (x *CreateNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNewsRequest.ProtoReflect.Descriptor instead.
+func (*CreateNewsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateNewsRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateNewsRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreateNewsRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *CreateNewsRequest) GetStatus() NewsStatus {
+	if x != nil {
+		return x.Status
+	}
+	return NewsStatus_NEWS_STATUS_UNSPECIFIED
+}
+
+func (x *CreateNewsRequest) GetTopicIds() []int64 {
+	if x != nil {
+		return x.TopicIds
+	}
+	return nil
+}
+
+// TopicIDs wraps the topics of an update, to tell an empty list from no change
+type TopicIDs struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopicIDs) Reset() {
+	*x = TopicIDs{}
+	mi := &file_news_v1_news_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopicIDs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopicIDs) ProtoMessage() {}
+
+func (x *TopicIDs) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopicIDs.ProtoReflect.Descriptor instead.
+func (*TopicIDs) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TopicIDs) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+// UpdateNewsRequest only changes the fields which are set
+type UpdateNewsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title    *string                `protobuf:"bytes,2,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Content  *string                `protobuf:"bytes,3,opt,name=content,proto3,oneof" json:"content,omitempty"`
+	AuthorId *int64                 `protobuf:"varint,4,opt,name=author_id,json=authorId,proto3,oneof" json:"author_id,omitempty"`
+	Status   NewsStatus             `protobuf:"varint,5,opt,name=status,proto3,enum=news.v1.NewsStatus" json:"status,omitempty"`
+	TopicIds *TopicIDs              `protobuf:"bytes,6,opt,name=topic_ids,json=topicIds,proto3" json:"topic_ids,omitempty"`
+	// expected_version fails the update with FAILED_PRECONDITION when the news
+	// is at another version, 0 applies it to any version
+	ExpectedVersion int64 `protobuf:"varint,7,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateNewsRequest) Reset() {
+	*x = UpdateNewsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNewsRequest) ProtoMessage() {}
+
+func (x *UpdateNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNewsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNewsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateNewsRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateNewsRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateNewsRequest) GetContent() string {
+	if x != nil && x.Content != nil {
+		return *x.Content
+	}
+	return ""
+}
+
+func (x *UpdateNewsRequest) GetAuthorId() int64 {
+	if x != nil && x.AuthorId != nil {
+		return *x.AuthorId
+	}
+	return 0
+}
+
+func (x *UpdateNewsRequest) GetStatus() NewsStatus {
+	if x != nil {
+		return x.Status
+	}
+	return NewsStatus_NEWS_STATUS_UNSPECIFIED
+}
+
+func (x *UpdateNewsRequest) GetTopicIds() *TopicIDs {
+	if x != nil {
+		return x.TopicIds
+	}
+	return nil
+}
+
+func (x *UpdateNewsRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type DeleteNewsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// expected_version fails the delete with FAILED_PRECONDITION when the news
+	// is at another version, 0 applies it to any version
+	ExpectedVersion int64 `protobuf:"varint,2,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteNewsRequest) Reset() {
+	*x = DeleteNewsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNewsRequest) ProtoMessage() {}
+
+func (x *DeleteNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNewsRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNewsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteNewsRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteNewsRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type ListTopicsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Filter        *TopicFilter           `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTopicsRequest) Reset() {
+	*x = ListTopicsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTopicsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTopicsRequest) ProtoMessage() {}
+
+func (x *ListTopicsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTopicsRequest.ProtoReflect.Descriptor instead.
+func (*ListTopicsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListTopicsRequest) GetFilter() *TopicFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type GetTopicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopicRequest) Reset() {
+	*x = GetTopicRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopicRequest) ProtoMessage() {}
+
+func (x *GetTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopicRequest.ProtoReflect.Descriptor instead.
+func (*GetTopicRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetTopicRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateTopicRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTopicRequest) Reset() {
+	*x = CreateTopicRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTopicRequest) ProtoMessage() {}
+
+func (x *CreateTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTopicRequest.ProtoReflect.Descriptor instead.
+func (*CreateTopicRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateTopicRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type UpdateTopicRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// expected_version fails the update with FAILED_PRECONDITION when the topic
+	// is at another version, 0 applies it to any version
+	ExpectedVersion int64 `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateTopicRequest) Reset() {
+	*x = UpdateTopicRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTopicRequest) ProtoMessage() {}
+
+func (x *UpdateTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTopicRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTopicRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UpdateTopicRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateTopicRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateTopicRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type DeleteTopicRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// expected_version fails the delete with FAILED_PRECONDITION when the topic
+	// is at another version, 0 applies it to any version
+	ExpectedVersion int64 `protobuf:"varint,2,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteTopicRequest) Reset() {
+	*x = DeleteTopicRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTopicRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTopicRequest) ProtoMessage() {}
+
+func (x *DeleteTopicRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTopicRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTopicRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteTopicRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteTopicRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type GetAuthorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAuthorRequest) Reset() {
+	*x = GetAuthorRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAuthorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuthorRequest) ProtoMessage() {}
+
+func (x *GetAuthorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuthorRequest.ProtoReflect.Descriptor instead.
+func (*GetAuthorRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetAuthorRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListAuthorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ids           []int64                `protobuf:"varint,1,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuthorsRequest) Reset() {
+	*x = ListAuthorsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuthorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuthorsRequest) ProtoMessage() {}
+
+func (x *ListAuthorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuthorsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuthorsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListAuthorsRequest) GetIds() []int64 {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+type ListAuthorNewsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId      int64                  `protobuf:"varint,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAuthorNewsRequest) Reset() {
+	*x = ListAuthorNewsRequest{}
+	mi := &file_news_v1_news_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAuthorNewsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAuthorNewsRequest) ProtoMessage() {}
+
+func (x *ListAuthorNewsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_news_v1_news_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAuthorNewsRequest.ProtoReflect.Descriptor instead.
+func (*ListAuthorNewsRequest) Descriptor() ([]byte, []int) {
+	return file_news_v1_news_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListAuthorNewsRequest) GetAuthorId() int64 {
+	if x != nil {
+		return x.AuthorId
+	}
+	return 0
+}
+
+func (x *ListAuthorNewsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+var File_news_v1_news_proto protoreflect.FileDescriptor
+
+const file_news_v1_news_proto_rawDesc = "" +
+	"\n" +
+	"\x12news/v1/news.proto\x12\anews.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"j\n" +
+	"\x06Author\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\tR\tupdatedAt\".\n" +
+	"\bTopicRef\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xd7\x02\n" +
+	"\x04News\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12'\n" +
+	"\x06author\x18\x04 \x01(\v2\x0f.news.v1.AuthorR\x06author\x12+\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x13.news.v1.NewsStatusR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12)\n" +
+	"\x06topics\x18\b \x03(\v2\x11.news.v1.TopicRefR\x06topics\x12\x18\n" +
+	"\aversion\x18\t \x01(\x03R\aversion\"\xbb\x01\n" +
+	"\x05Topic\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\x03R\aversion\"\xeb\x02\n" +
+	"\n" +
+	"NewsFilter\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12+\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x13.news.v1.NewsStatusR\x06status\x12\x1b\n" +
+	"\tauthor_id\x18\x04 \x01(\x03R\bauthorId\x12\x19\n" +
+	"\btopic_id\x18\x05 \x01(\x03R\atopicId\x129\n" +
+	"\n" +
+	"start_date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\x12\x14\n" +
+	"\x05limit\x18\b \x01(\x03R\x05limit\x12\x12\n" +
+	"\x04page\x18\t \x01(\x03R\x04page\x12\x17\n" +
+	"\asort_by\x18\n" +
+	" \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\v \x01(\tR\tsortOrder\"\x93\x01\n" +
+	"\vTopicFilter\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x03R\x04page\x12\x17\n" +
+	"\asort_by\x18\x05 \x01(\tR\x06sortBy\x12\x1d\n" +
+	"\n" +
+	"sort_order\x18\x06 \x01(\tR\tsortOrder\">\n" +
+	"\x0fListNewsRequest\x12+\n" +
+	"\x06filter\x18\x01 \x01(\v2\x13.news.v1.NewsFilterR\x06filter\"?\n" +
+	"\x10CountNewsRequest\x12+\n" +
+	"\x06filter\x18\x01 \x01(\v2\x13.news.v1.NewsFilterR\x06filter\")\n" +
+	"\x11CountNewsResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x03R\x05total\" \n" +
+	"\x0eGetNewsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xaa\x01\n" +
+	"\x11CreateNewsRequest\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x1b\n" +
+	"\tauthor_id\x18\x03 \x01(\x03R\bauthorId\x12+\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x13.news.v1.NewsStatusR\x06status\x12\x1b\n" +
+	"\ttopic_ids\x18\x05 \x03(\x03R\btopicIds\"\x1c\n" +
+	"\bTopicIDs\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"\xab\x02\n" +
+	"\x11UpdateNewsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\x05title\x18\x02 \x01(\tH\x00R\x05title\x88\x01\x01\x12\x1d\n" +
+	"\acontent\x18\x03 \x01(\tH\x01R\acontent\x88\x01\x01\x12 \n" +
+	"\tauthor_id\x18\x04 \x01(\x03H\x02R\bauthorId\x88\x01\x01\x12+\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x13.news.v1.NewsStatusR\x06status\x12.\n" +
+	"\ttopic_ids\x18\x06 \x01(\v2\x11.news.v1.TopicIDsR\btopicIds\x12)\n" +
+	"\x10expected_version\x18\a \x01(\x03R\x0fexpectedVersionB\b\n" +
+	"\x06_titleB\n" +
+	"\n" +
+	"\b_contentB\f\n" +
+	"\n" +
+	"_author_id\"N\n" +
+	"\x11DeleteNewsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12)\n" +
+	"\x10expected_version\x18\x02 \x01(\x03R\x0fexpectedVersion\"A\n" +
+	"\x11ListTopicsRequest\x12,\n" +
+	"\x06filter\x18\x01 \x01(\v2\x14.news.v1.TopicFilterR\x06filter\"!\n" +
+	"\x0fGetTopicRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"(\n" +
+	"\x12CreateTopicRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"c\n" +
+	"\x12UpdateTopicRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12)\n" +
+	"\x10expected_version\x18\x03 \x01(\x03R\x0fexpectedVersion\"O\n" +
+	"\x12DeleteTopicRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12)\n" +
+	"\x10expected_version\x18\x02 \x01(\x03R\x0fexpectedVersion\"\"\n" +
+	"\x10GetAuthorRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"&\n" +
+	"\x12ListAuthorsRequest\x12\x10\n" +
+	"\x03ids\x18\x01 \x03(\x03R\x03ids\"J\n" +
+	"\x15ListAuthorNewsRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\x03R\bauthorId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit*t\n" +
+	"\n" +
+	"NewsStatus\x12\x1b\n" +
+	"\x17NEWS_STATUS_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11NEWS_STATUS_DRAFT\x10\x01\x12\x19\n" +
+	"\x15NEWS_STATUS_PUBLISHED\x10\x02\x12\x17\n" +
+	"\x13NEWS_STATUS_DELETED\x10\x032\xef\x02\n" +
+	"\vNewsService\x125\n" +
+	"\bListNews\x12\x18.news.v1.ListNewsRequest\x1a\r.news.v1.News0\x01\x12B\n" +
+	"\tCountNews\x12\x19.news.v1.CountNewsRequest\x1a\x1a.news.v1.CountNewsResponse\x121\n" +
+	"\aGetNews\x12\x17.news.v1.GetNewsRequest\x1a\r.news.v1.News\x127\n" +
+	"\n" +
+	"CreateNews\x12\x1a.news.v1.CreateNewsRequest\x1a\r.news.v1.News\x127\n" +
+	"\n" +
+	"UpdateNews\x12\x1a.news.v1.UpdateNewsRequest\x1a\r.news.v1.News\x12@\n" +
+	"\n" +
+	"DeleteNews\x12\x1a.news.v1.DeleteNewsRequest\x1a\x16.google.protobuf.Empty2\xbc\x02\n" +
+	"\fTopicService\x12:\n" +
+	"\n" +
+	"ListTopics\x12\x1a.news.v1.ListTopicsRequest\x1a\x0e.news.v1.Topic0\x01\x124\n" +
+	"\bGetTopic\x12\x18.news.v1.GetTopicRequest\x1a\x0e.news.v1.Topic\x12:\n" +
+	"\vCreateTopic\x12\x1b.news.v1.CreateTopicRequest\x1a\x0e.news.v1.Topic\x12:\n" +
+	"\vUpdateTopic\x12\x1b.news.v1.UpdateTopicRequest\x1a\x0e.news.v1.Topic\x12B\n" +
+	"\vDeleteTopic\x12\x1b.news.v1.DeleteTopicRequest\x1a\x16.google.protobuf.Empty2\xca\x01\n" +
+	"\rAuthorService\x127\n" +
+	"\tGetAuthor\x12\x19.news.v1.GetAuthorRequest\x1a\x0f.news.v1.Author\x12=\n" +
+	"\vListAuthors\x12\x1b.news.v1.ListAuthorsRequest\x1a\x0f.news.v1.Author0\x01\x12A\n" +
+	"\x0eListAuthorNews\x12\x1e.news.v1.ListAuthorNewsRequest\x1a\r.news.v1.News0\x01B8Z6github.com/bxcodec/go-clean-arch/pkg/pb/news/v1;newsv1b\x06proto3"
+
+var (
+	file_news_v1_news_proto_rawDescOnce sync.Once
+	file_news_v1_news_proto_rawDescData []byte
+)
+
+func file_news_v1_news_proto_rawDescGZIP() []byte {
+	file_news_v1_news_proto_rawDescOnce.Do(func() {
+		file_news_v1_news_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_news_v1_news_proto_rawDesc), len(file_news_v1_news_proto_rawDesc)))
+	})
+	return file_news_v1_news_proto_rawDescData
+}
+
+var file_news_v1_news_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_news_v1_news_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_news_v1_news_proto_goTypes = []any{
+	(NewsStatus)(0),               // 0: news.v1.NewsStatus
+	(*Author)(nil),                // 1: news.v1.Author
+	(*TopicRef)(nil),              // 2: news.v1.TopicRef
+	(*News)(nil),                  // 3: news.v1.News
+	(*Topic)(nil),                 // 4: news.v1.Topic
+	(*NewsFilter)(nil),            // 5: news.v1.NewsFilter
+	(*TopicFilter)(nil),           // 6: news.v1.TopicFilter
+	(*ListNewsRequest)(nil),       // 7: news.v1.ListNewsRequest
+	(*CountNewsRequest)(nil),      // 8: news.v1.CountNewsRequest
+	(*CountNewsResponse)(nil),     // 9: news.v1.CountNewsResponse
+	(*GetNewsRequest)(nil),        // 10: news.v1.GetNewsRequest
+	(*CreateNewsRequest)(nil),     // 11: news.v1.CreateNewsRequest
+	(*TopicIDs)(nil),              // 12: news.v1.TopicIDs
+	(*UpdateNewsRequest)(nil),     // 13: news.v1.UpdateNewsRequest
+	(*DeleteNewsRequest)(nil),     // 14: news.v1.DeleteNewsRequest
+	(*ListTopicsRequest)(nil),     // 15: news.v1.ListTopicsRequest
+	(*GetTopicRequest)(nil),       // 16: news.v1.GetTopicRequest
+	(*CreateTopicRequest)(nil),    // 17: news.v1.CreateTopicRequest
+	(*UpdateTopicRequest)(nil),    // 18: news.v1.UpdateTopicRequest
+	(*DeleteTopicRequest)(nil),    // 19: news.v1.DeleteTopicRequest
+	(*GetAuthorRequest)(nil),      // 20: news.v1.GetAuthorRequest
+	(*ListAuthorsRequest)(nil),    // 21: news.v1.ListAuthorsRequest
+	(*ListAuthorNewsRequest)(nil), // 22: news.v1.ListAuthorNewsRequest
+	(*timestamppb.Timestamp)(nil), // 23: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 24: google.protobuf.Empty
+}
+var file_news_v1_news_proto_depIdxs = []int32{
+	1,  // 0: news.v1.News.author:type_name -> news.v1.Author
+	0,  // 1: news.v1.News.status:type_name -> news.v1.NewsStatus
+	23, // 2: news.v1.News.created_at:type_name -> google.protobuf.Timestamp
+	23, // 3: news.v1.News.updated_at:type_name -> google.protobuf.Timestamp
+	2,  // 4: news.v1.News.topics:type_name -> news.v1.TopicRef
+	23, // 5: news.v1.Topic.created_at:type_name -> google.protobuf.Timestamp
+	23, // 6: news.v1.Topic.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 7: news.v1.NewsFilter.status:type_name -> news.v1.NewsStatus
+	23, // 8: news.v1.NewsFilter.start_date:type_name -> google.protobuf.Timestamp
+	23, // 9: news.v1.NewsFilter.end_date:type_name -> google.protobuf.Timestamp
+	5,  // 10: news.v1.ListNewsRequest.filter:type_name -> news.v1.NewsFilter
+	5,  // 11: news.v1.CountNewsRequest.filter:type_name -> news.v1.NewsFilter
+	0,  // 12: news.v1.CreateNewsRequest.status:type_name -> news.v1.NewsStatus
+	0,  // 13: news.v1.UpdateNewsRequest.status:type_name -> news.v1.NewsStatus
+	12, // 14: news.v1.UpdateNewsRequest.topic_ids:type_name -> news.v1.TopicIDs
+	6,  // 15: news.v1.ListTopicsRequest.filter:type_name -> news.v1.TopicFilter
+	7,  // 16: news.v1.NewsService.ListNews:input_type -> news.v1.ListNewsRequest
+	8,  // 17: news.v1.NewsService.CountNews:input_type -> news.v1.CountNewsRequest
+	10, // 18: news.v1.NewsService.GetNews:input_type -> news.v1.GetNewsRequest
+	11, // 19: news.v1.NewsService.CreateNews:input_type -> news.v1.CreateNewsRequest
+	13, // 20: news.v1.NewsService.UpdateNews:input_type -> news.v1.UpdateNewsRequest
+	14, // 21: news.v1.NewsService.DeleteNews:input_type -> news.v1.DeleteNewsRequest
+	15, // 22: news.v1.TopicService.ListTopics:input_type -> news.v1.ListTopicsRequest
+	16, // 23: news.v1.TopicService.GetTopic:input_type -> news.v1.GetTopicRequest
+	17, // 24: news.v1.TopicService.CreateTopic:input_type -> news.v1.CreateTopicRequest
+	18, // 25: news.v1.TopicService.UpdateTopic:input_type -> news.v1.UpdateTopicRequest
+	19, // 26: news.v1.TopicService.DeleteTopic:input_type -> news.v1.DeleteTopicRequest
+	20, // 27: news.v1.AuthorService.GetAuthor:input_type -> news.v1.GetAuthorRequest
+	21, // 28: news.v1.AuthorService.ListAuthors:input_type -> news.v1.ListAuthorsRequest
+	22, // 29: news.v1.AuthorService.ListAuthorNews:input_type -> news.v1.ListAuthorNewsRequest
+	3,  // 30: news.v1.NewsService.ListNews:output_type -> news.v1.News
+	9,  // 31: news.v1.NewsService.CountNews:output_type -> news.v1.CountNewsResponse
+	3,  // 32: news.v1.NewsService.GetNews:output_type -> news.v1.News
+	3,  // 33: news.v1.NewsService.CreateNews:output_type -> news.v1.News
+	3,  // 34: news.v1.NewsService.UpdateNews:output_type -> news.v1.News
+	24, // 35: news.v1.NewsService.DeleteNews:output_type -> google.protobuf.Empty
+	4,  // 36: news.v1.TopicService.ListTopics:output_type -> news.v1.Topic
+	4,  // 37: news.v1.TopicService.GetTopic:output_type -> news.v1.Topic
+	4,  // 38: news.v1.TopicService.CreateTopic:output_type -> news.v1.Topic
+	4,  // 39: news.v1.TopicService.UpdateTopic:output_type -> news.v1.Topic
+	24, // 40: news.v1.TopicService.DeleteTopic:output_type -> google.protobuf.Empty
+	1,  // 41: news.v1.AuthorService.GetAuthor:output_type -> news.v1.Author
+	1,  // 42: news.v1.AuthorService.ListAuthors:output_type -> news.v1.Author
+	3,  // 43: news.v1.AuthorService.ListAuthorNews:output_type -> news.v1.News
+	30, // [30:44] is the sub-list for method output_type
+	16, // [16:30] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_news_v1_news_proto_init() }
+func file_news_v1_news_proto_init() {
+	if File_news_v1_news_proto != nil {
+		return
+	}
+	file_news_v1_news_proto_msgTypes[12].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_news_v1_news_proto_rawDesc), len(file_news_v1_news_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_news_v1_news_proto_goTypes,
+		DependencyIndexes: file_news_v1_news_proto_depIdxs,
+		EnumInfos:         file_news_v1_news_proto_enumTypes,
+		MessageInfos:      file_news_v1_news_proto_msgTypes,
+	}.Build()
+	File_news_v1_news_proto = out.File
+	file_news_v1_news_proto_goTypes = nil
+	file_news_v1_news_proto_depIdxs = nil
+}
@@ -10,21 +10,43 @@ import (
 //go:generate mockery --name TopicRepository
 type TopicRepository interface {
 	Fetch(ctx context.Context, filter domain.TopicFilter) (res []domain.Topic, totalPage int64, err error)
+	Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) error
 	GetByName(ctx context.Context, name string) (domain.Topic, error)
 	GetByID(ctx context.Context, id int64) (domain.Topic, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]domain.Topic, error)
+	CountNews(ctx context.Context, ids []int64) (map[int64]int64, error)
+	Stats(ctx context.Context, id int64, weeks int64) (domain.TopicActivity, error)
 	Update(ctx context.Context, ar *domain.Topic) error
 	Store(ctx context.Context, a *domain.Topic) error
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64, version int64) error
+}
+
+// AuditRepository represent the audit log repository contract
+//
+//go:generate mockery --name AuditRepository
+type AuditRepository interface {
+	Store(ctx context.Context, a *domain.AuditLog) error
+}
+
+// Transactor runs fn inside a transaction carried by its context
+//
+//go:generate mockery --name Transactor
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 type Service struct {
-	topicRepo TopicRepository
+	topicRepo  TopicRepository
+	auditRepo  AuditRepository
+	transactor Transactor
 }
 
 // NewService will create a new topic service object
-func NewService(t TopicRepository) *Service {
+func NewService(t TopicRepository, ar AuditRepository, tx Transactor) *Service {
 	return &Service{
-		topicRepo: t,
+		topicRepo:  t,
+		auditRepo:  ar,
+		transactor: tx,
 	}
 }
 
@@ -43,6 +65,11 @@ func (s *Service) Fetch(ctx context.Context, filter domain.TopicFilter) (res []d
 	return
 }
 
+// Stream calls fn for every topic matching filter without loading them all in memory
+func (s *Service) Stream(ctx context.Context, filter domain.TopicFilter, fn func(domain.Topic) error) error {
+	return s.topicRepo.Stream(ctx, filter, fn)
+}
+
 func (s *Service) GetByID(ctx context.Context, id int64) (res domain.Topic, err error) {
 	res, err = s.topicRepo.GetByID(ctx, id)
 	if err != nil {
@@ -52,8 +79,39 @@ func (s *Service) GetByID(ctx context.Context, id int64) (res domain.Topic, err
 	return
 }
 
+// GetByIDs returns the topics with the given IDs, unknown IDs are ignored
+func (s *Service) GetByIDs(ctx context.Context, ids []int64) ([]domain.Topic, error) {
+	return s.topicRepo.GetByIDs(ctx, ids)
+}
+
+// CountNews returns the number of news of every given topic
+func (s *Service) CountNews(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	return s.topicRepo.CountNews(ctx, ids)
+}
+
+// Stats returns the stats of a topic with their time series over the given
+// number of weeks
+func (s *Service) Stats(ctx context.Context, id int64, weeks int64) (domain.TopicActivity, error) {
+	return s.topicRepo.Stats(ctx, id, weeks)
+}
+
 func (s *Service) Update(ctx context.Context, unr *domain.Topic) (err error) {
-	return s.topicRepo.Update(ctx, unr)
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.topicRepo.GetByID(ctx, unr.ID)
+		if err != nil {
+			return err
+		}
+
+		if err = s.topicRepo.Update(ctx, unr); err != nil {
+			return err
+		}
+
+		after, err := s.topicRepo.GetByID(ctx, unr.ID)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, domain.AuditUpdate, unr.ID, before, after)
+	})
 }
 
 func (s *Service) GetByTitle(ctx context.Context, name string) (res domain.Topic, err error) {
@@ -71,22 +129,41 @@ func (s *Service) Store(ctx context.Context, cnr *domain.Topic) (err error) {
 		return domain.ErrConflict
 	}
 
-	err = s.topicRepo.Store(ctx, cnr)
-	if err != nil {
-		return
-	}
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.topicRepo.Store(ctx, cnr); err != nil {
+			return err
+		}
+
+		after, err := s.topicRepo.GetByID(ctx, cnr.ID)
+		if err != nil {
+			return err
+		}
+		return s.audit(ctx, domain.AuditCreate, cnr.ID, nil, after)
+	})
+}
 
-	return
+// Delete removes the topic, only at the given version unless it is 0
+func (s *Service) Delete(ctx context.Context, id int64, version int64) (err error) {
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		before, err := s.topicRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if before.ID == 0 {
+			return domain.ErrNotFound
+		}
+		if err = s.topicRepo.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return s.audit(ctx, domain.AuditDelete, id, before, nil)
+	})
 }
 
-func (s *Service) Delete(ctx context.Context, id int64) (err error) {
-	existedTopic, err := s.topicRepo.GetByID(ctx, id)
+func (s *Service) audit(ctx context.Context, action domain.AuditAction, id int64, before, after interface{}) error {
+	entry, err := domain.NewAuditLog(ctx, action, domain.AuditEntityTopic, id, before, after)
 	if err != nil {
-		return
-	}
-
-	if existedTopic.ID == 0 {
-		return domain.ErrNotFound
+		return err
 	}
-	return s.topicRepo.Delete(ctx, id)
+	return s.auditRepo.Store(ctx, entry)
 }